@@ -0,0 +1,252 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+	"vanity-eth/internal/generator"
+	"vanity-eth/internal/ledger"
+)
+
+// batchSpec is one line of a --batch file.
+type batchSpec struct {
+	line                     int
+	text                     string
+	prefix, suffix, contains string
+}
+
+// parseBatch reads one pattern spec per line. A spec is a bare prefix
+// ("dead") or space-separated prefix=, suffix= and contains= fields
+// ("prefix=dead suffix=beef"). Blank lines and # comments are skipped.
+func parseBatch(r io.Reader) ([]batchSpec, error) {
+	var specs []batchSpec
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		spec := batchSpec{line: line, text: text}
+		for _, field := range strings.Fields(text) {
+			key, val, ok := strings.Cut(field, "=")
+			if !ok {
+				key, val = "prefix", field
+			}
+			var dst *string
+			switch key {
+			case "prefix":
+				dst = &spec.prefix
+			case "suffix":
+				dst = &spec.suffix
+			case "contains":
+				dst = &spec.contains
+			default:
+				return nil, fmt.Errorf("line %d: unknown field %q (want prefix=, suffix= or contains=)", line, key)
+			}
+			if err := generator.ValidateHexPattern(val); err != nil {
+				return nil, fmt.Errorf("line %d: %s: %v", line, key, err)
+			}
+			*dst = val
+		}
+		specs = append(specs, spec)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no patterns found")
+	}
+	return specs, nil
+}
+
+func readBatch(path string) ([]batchSpec, error) {
+	if path == "-" {
+		return parseBatch(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseBatch(f)
+}
+
+// runBatch searches for --count addresses per spec, one spec at a time, each
+// with the full worker pool. Every pattern runs until it has its matches;
+// --timeout is a single deadline for the whole batch, and Ctrl-C stops the
+// current search and skips the rest. The other criteria in base (regex,
+// words, …) apply to every spec. If --max-attempts stops any spec short of
+// its matches, the batch still runs the rest and ends with errPartialResult.
+func runBatch(cmd *cobra.Command, base generator.Config) error {
+	// The status servers report on a single search.
+	for _, name := range []string{"serve", "serve-keys", "metrics"} {
+		if cmd.Flags().Changed(name) {
+			return fmt.Errorf("--%s cannot be combined with --batch", name)
+		}
+	}
+	specs, err := readBatch(flagBatch)
+	if err != nil {
+		return fmt.Errorf("--batch: %v", err)
+	}
+
+	decorate := flagFormat != "csv" && !flagQuiet
+	showProgress = decorate && !flagNoProgress && term.IsTerminal(os.Stdout.Fd())
+
+	ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	if flagTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, flagTimeout)
+		defer cancelTimeout()
+	}
+
+	var collected []generator.Result
+	defer func() { generator.ZeroKeys(collected) }()
+	var csvOut *csvStream
+	if base.Count == 0 {
+		return fmt.Errorf("--batch needs a --count of at least 1")
+	}
+	if flagFormat == "csv" && !flagSort && flagRankBy == "" {
+		csvOut = newCSVStream(os.Stdout, csvColumns("pattern")...)
+	}
+
+	dump := make(chan os.Signal, 1)
+	notifyStatsDump(dump)
+	defer ignoreStatsDump()
+
+	// The odds of each pattern are for the time the batch has left.
+	var benchRate float64
+	if flagTimeout > 0 && (flagFormat == "text" || flagFormat == "table") && !flagQuiet {
+		benchRate = generator.MeasureRate(base, 500*time.Millisecond)
+	}
+
+	start := time.Now()
+	var total int64
+	var runErr error
+	short := 0 // specs --max-attempts stopped short of --count
+	for i, spec := range specs {
+		if ctx.Err() != nil {
+			break
+		}
+		cfg := base
+		cfg.Prefix, cfg.Suffix, cfg.Contains = spec.prefix, spec.suffix, spec.contains
+		if (flagFormat == "text" || flagFormat == "table") && !flagQuiet {
+			bold.Printf("[%d/%d] ", i+1, len(specs))
+			printPattern(cfg)
+			if left := flagTimeout - time.Since(start); benchRate > 0 && left > 0 {
+				printTimeoutOdds(cfg, left.Round(time.Second), benchRate)
+			}
+		}
+
+		stats := &generator.Stats{}
+		resultCh := make(chan generator.Result, cfg.Count)
+		searchStart := time.Now()
+		found := 0
+		go generator.Run(ctx, cfg, resultCh, stats)
+	results:
+		for {
+			select {
+			case <-dump:
+				printStatsDump(os.Stderr, stats, cfg.Count, time.Since(searchStart), cfg)
+			case r, ok := <-resultCh:
+				if !ok {
+					break results
+				}
+				r.Address = styleAddress(r.Address, flagAddrStyle)
+				withReverseNode(&r)
+				withASCII(&r)
+				withProof(&r)
+				r.Pattern = spec.text
+				collected = append(collected, r)
+				found++
+				switch flagFormat {
+				case "text":
+					if flagQuiet {
+						if flagRankBy == "" {
+							printQuietResult(os.Stdout, r)
+						}
+						break
+					}
+					printResult(os.Stdout, len(collected), r, stats.Total.Load(), time.Since(searchStart), cfg)
+				case "csv":
+					if csvOut != nil {
+						_ = csvOut.write(r)
+					}
+				}
+			}
+		}
+		total += stats.Total.Load()
+		if runErr = stats.Err(); runErr != nil {
+			break
+		}
+		if cfg.MaxAttempts > 0 && found < cfg.Count && stats.Total.Load() >= cfg.MaxAttempts {
+			short++
+		}
+	}
+
+	elapsed := time.Since(start)
+	if flagSort {
+		sortResults(collected)
+	}
+	if flagRankBy != "" {
+		rankResults(collected, rankMetrics[flagRankBy])
+	}
+	switch flagFormat {
+	case "json":
+		_ = ledger.WriteJSON(os.Stdout, collected)
+	case "csv":
+		if csvOut == nil {
+			_ = writeCSV(os.Stdout, collected)
+		}
+	case "text", "table":
+		if flagFormat == "table" {
+			if !flagQuiet {
+				fmt.Println()
+			}
+			printTable(os.Stdout, collected, flagFullKeys)
+		}
+		if flagQuiet {
+			if flagRankBy != "" && flagFormat == "text" {
+				for _, r := range collected {
+					printQuietResult(os.Stdout, r)
+				}
+			}
+			break
+		}
+		fmt.Printf("\n%s  %d pattern(s)  •  found %d/%d  •  %s tried  •  %s\n",
+			bold.Sprint("done"), len(specs),
+			len(collected), len(specs)*base.Count,
+			formatBig(total),
+			elapsed.Round(time.Millisecond),
+		)
+		if flagRankBy != "" {
+			printRanking(os.Stdout, collected, flagRankBy, rankMetrics[flagRankBy])
+		}
+	}
+
+	if flagOutput != "" {
+		if err := saveToFile(flagOutput, flagOutputFormat, collected, flagAppend); err != nil {
+			fmt.Fprintf(os.Stderr, "error saving file: %v\n", err)
+		} else if decorate {
+			green.Printf("saved to %s\n", flagOutput)
+		}
+	}
+	if runErr != nil {
+		return runErr
+	}
+	if short > 0 {
+		yellow.Fprintf(os.Stderr, "stopped at --max-attempts %d: %d of %d pattern(s) found fewer than %d\n", flagMaxAttempts, short, len(specs), base.Count)
+		cmd.SilenceErrors, cmd.SilenceUsage = true, true
+		return errPartialResult
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+func TestParseBatch_Specs(t *testing.T) {
+	in := `# one address for each
+dead
+
+prefix=cafe suffix=beef
+  contains=c0ffee  
+`
+	specs, err := parseBatch(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []batchSpec{
+		{line: 2, text: "dead", prefix: "dead"},
+		{line: 4, text: "prefix=cafe suffix=beef", prefix: "cafe", suffix: "beef"},
+		{line: 5, text: "contains=c0ffee", contains: "c0ffee"},
+	}
+	if len(specs) != len(want) {
+		t.Fatalf("got %d specs, want %d: %+v", len(specs), len(want), specs)
+	}
+	for i := range want {
+		if specs[i] != want[i] {
+			t.Errorf("spec %d = %+v, want %+v", i, specs[i], want[i])
+		}
+	}
+}
+
+func TestParseBatch_Errors(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"dead\nmiddle=beef\n", "line 2: unknown field \"middle\""},
+		{"suffix=xyz\n", "line 1: suffix:"},
+		{"# only a comment\n\n", "no patterns found"},
+	} {
+		_, err := parseBatch(strings.NewReader(tc.in))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("parseBatch(%q) error = %v, want it to contain %q", tc.in, err, tc.want)
+		}
+	}
+}
+
+func TestHighlightAddress_UsesGivenPatterns(t *testing.T) {
+	defer func(old bool) { color.NoColor = old }(color.NoColor)
+	color.NoColor = false
+	var buf bytes.Buffer
+	highlightAddress(&buf, "0xcafe00000000000000000000000000000000beef", "cafe", "beef")
+	hi := func(s string) string {
+		var out string
+		for _, c := range s {
+			out += "\x1b[32;1m" + string(c) + "\x1b[0m"
+		}
+		return out
+	}
+	want := "0x" + hi("cafe") + strings.Repeat("0", 32) + hi("beef")
+	if got := buf.String(); got != want {
+		t.Fatalf("highlightAddress = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// console serializes everything the search loop writes to the terminal.
+// The live progress line is redrawn in place with \r, so other output has
+// to clear it first. On a terminal narrower than the line it would also
+// wrap, and \r\033[K then only clears the last row, leaving the rest on top
+// of the next result; progress is therefore cut to the terminal width.
+type console struct {
+	mu       sync.Mutex
+	w        io.Writer
+	width    func() int // terminal columns; 0 if unknown
+	progress bool       // a progress line is on screen
+}
+
+func newConsole(f *os.File) *console {
+	return &console{
+		w: f,
+		width: func() int {
+			w, _, err := term.GetSize(f.Fd())
+			if err != nil {
+				return 0
+			}
+			return w
+		},
+	}
+}
+
+// showProgress replaces the progress line with line.
+func (c *console) showProgress(line string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if w := c.width(); w > 1 {
+		// Leave the last column free so the cursor never wraps.
+		line = truncateVisible(line, w-1)
+	}
+	io.WriteString(c.w, "\r\033[K"+line)
+	c.progress = true
+}
+
+// print clears the progress line, if any, and runs f with exclusive use of
+// the terminal.
+func (c *console) print(f func(w io.Writer)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.progress {
+		io.WriteString(c.w, "\r\033[K")
+		c.progress = false
+	}
+	f(c.w)
+}
+
+// finish leaves the last progress line on screen and moves below it.
+func (c *console) finish() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.progress {
+		io.WriteString(c.w, "\n")
+		c.progress = false
+	}
+}
+
+// truncateVisible cuts s to n visible runes, skipping over ANSI color
+// sequences when counting and keeping any that follow the cut so colors
+// are still reset.
+func truncateVisible(s string, n int) string {
+	var b strings.Builder
+	visible := 0
+	for i := 0; i < len(s); {
+		if s[i] == '\033' {
+			end := strings.IndexByte(s[i:], 'm')
+			if end < 0 {
+				break
+			}
+			b.WriteString(s[i : i+end+1])
+			i += end + 1
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		if visible < n {
+			b.WriteString(s[i : i+size])
+			visible++
+		}
+		i += size
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestConsole_ProgressNeverClobbersResults(t *testing.T) {
+	var buf bytes.Buffer
+	c := &console{w: &buf, width: func() int { return 20 }}
+
+	// Hammer the console from a fast ticker and a stream of results at once.
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 2000; i++ {
+			c.showProgress(fmt.Sprintf("%s %d tried  •  0/1 found  •  123 addr/s", cyan.Sprint("⠋"), i))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			c.print(func(w io.Writer) { fmt.Fprintf(w, "result %d\nkey %d\n", i, i) })
+		}
+	}()
+	wg.Wait()
+	c.finish()
+
+	// Replay the output as a terminal would: \r\033[K erases the current
+	// row. Every result line must end up alone on its own row.
+	var rows []string
+	for _, line := range strings.SplitAfter(buf.String(), "\n") {
+		segs := strings.Split(line, "\r\033[K")
+		row := strings.TrimSuffix(segs[len(segs)-1], "\n")
+		if n := utf8.RuneCountInString(ansi.ReplaceAllString(row, "")); n > 19 {
+			t.Fatalf("row wider than the terminal: %q", row)
+		}
+		rows = append(rows, row)
+	}
+	results := 0
+	for _, row := range rows {
+		if strings.Contains(row, "result") || strings.Contains(row, "key") {
+			if !strings.HasPrefix(row, "result ") && !strings.HasPrefix(row, "key ") {
+				t.Fatalf("result line clobbered: %q", row)
+			}
+			results++
+		}
+	}
+	if results != 400 {
+		t.Fatalf("saw %d result lines, want 400", results)
+	}
+}
+
+func TestTruncateVisible_SkipsColorCodes(t *testing.T) {
+	s := "\033[36m⠋\033[0m 1.2M tried"
+	if got, want := truncateVisible(s, 4), "\033[36m⠋\033[0m 1."; got != want {
+		t.Fatalf("truncateVisible = %q, want %q", got, want)
+	}
+	if got := truncateVisible("short", 10); got != "short" {
+		t.Fatalf("truncateVisible = %q, want unchanged", got)
+	}
+}
+
+var ansi = regexp.MustCompile("\033\\[[0-9;]*m")
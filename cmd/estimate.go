@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"vanity-eth/internal/generator"
+)
+
+var (
+	flagEstimateRate    float64
+	flagEstimatePattern patternFlags
+	flagEstimateCount   int
+	flagEstimateTimeout time.Duration
+	flagEstimateFormat  string
+)
+
+var estimateCmd = &cobra.Command{
+	Use:   "estimate",
+	Short: "Print the difficulty and ETA of a pattern at a given rate",
+	Long: `estimate prints the difficulty, expected attempts and ETA of a pattern at
+the --rate you give, without benchmarking or generating any keys. Use it to
+plan a run on another machine whose rate you already know.
+
+Examples:
+  vanity-eth estimate --prefix dead --suffix beef --rate 2000000
+  vanity-eth estimate --contains c0ffee --case-sensitive --count 5 --rate 450000
+  vanity-eth estimate --prefix c0ffee --rate 2000000 --timeout 1h --format json`,
+	Args: cobra.NoArgs,
+	RunE: runEstimate,
+}
+
+func init() {
+	estimateCmd.Flags().Float64Var(&flagEstimateRate, "rate", 0, "addresses per second of the machine doing the search (required)")
+	flagEstimatePattern.register(estimateCmd.Flags())
+	estimateCmd.Flags().IntVarP(&flagEstimateCount, "count", "n", 1, "how many matching addresses the run should find")
+	estimateCmd.Flags().DurationVar(&flagEstimateTimeout, "timeout", 0, "also print the chance of finding all --count matches within this long")
+	estimateCmd.Flags().StringVar(&flagEstimateFormat, "format", "text", "output format: text, or json for scripts")
+	_ = estimateCmd.MarkFlagRequired("rate")
+	rootCmd.AddCommand(estimateCmd)
+}
+
+func runEstimate(cmd *cobra.Command, args []string) error {
+	if flagEstimateRate <= 0 {
+		return fmt.Errorf("--rate must be positive")
+	}
+	if flagEstimateCount < 1 {
+		return fmt.Errorf("--count must be at least 1")
+	}
+	if flagEstimateTimeout < 0 {
+		return fmt.Errorf("--timeout must not be negative")
+	}
+	if flagEstimateFormat != "text" && flagEstimateFormat != "json" {
+		return fmt.Errorf("--format must be text or json")
+	}
+	cfg, err := flagEstimatePattern.config(cmd.Flags().Changed)
+	if err != nil {
+		return err
+	}
+	if cfg.Chain == generator.ChainEthereum {
+		if err := generator.ValidatePatternFit(cfg.Prefix, cfg.Suffix, cfg.Contains, cfg.CaseSensitive); err != nil {
+			return fmt.Errorf("--prefix/--suffix/--contains: %v", err)
+		}
+	}
+	cfg.Count = flagEstimateCount
+
+	e, ok := generator.Estimate(cfg, flagEstimateRate, flagEstimateTimeout)
+	if !ok {
+		return fmt.Errorf("give a pattern to estimate (regex patterns cannot be estimated)")
+	}
+	if flagEstimateFormat == "json" {
+		return json.NewEncoder(os.Stdout).Encode(toEstimateJSON(e, cfg, flagEstimateTimeout))
+	}
+
+	fmt.Printf("%s    %s\n", bold.Sprint("pattern:"), strings.Join(patternParts(cfg, flagEstimatePattern.spells), "  "))
+	fmt.Printf("%s ~1 in %s\n", bold.Sprint("difficulty:"), e.Difficulty.String())
+	fmt.Printf("%s %s for %d match(es)\n", bold.Sprint("expected:  "), e.ExpectedAttempts.String(), cfg.Count)
+	eta := "—"
+	if !cfg.Invert {
+		eta = fmtDuration(e.ETA)
+	}
+	fmt.Printf("%s %s at %.0f addr/s\n", bold.Sprint("ETA:       "), eta, flagEstimateRate)
+	if flagEstimateTimeout > 0 {
+		fmt.Printf("%s ~%s chance within %s\n", bold.Sprint("odds:      "), fmtPercent(e.ProbabilityInTimeout), flagEstimateTimeout)
+	}
+	return nil
+}
+
+// estimateJSON is the machine-readable form of estimate's output. Big
+// numbers are JSON integers, exact however long.
+type estimateJSON struct {
+	Difficulty           *big.Int `json:"difficulty"`
+	ExpectedAttempts     *big.Int `json:"expectedAttempts"`
+	ETASeconds           *float64 `json:"etaSeconds,omitempty"`           // absent with --invert
+	ProbabilityInTimeout *float64 `json:"probabilityInTimeout,omitempty"` // absent without --timeout
+}
+
+func toEstimateJSON(e generator.EstimateResult, cfg generator.Config, timeout time.Duration) estimateJSON {
+	j := estimateJSON{Difficulty: e.Difficulty, ExpectedAttempts: e.ExpectedAttempts}
+	if !cfg.Invert {
+		secs := e.ETA.Seconds()
+		j.ETASeconds = &secs
+	}
+	if timeout > 0 {
+		p := e.ProbabilityInTimeout
+		j.ProbabilityInTimeout = &p
+	}
+	return j
+}
@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"vanity-eth/internal/generator"
+	"vanity-eth/internal/ledger"
+)
+
+// fountainBuffer is how many keys the fountain's result channel holds, so
+// that workers rarely wait for the writer.
+const fountainBuffer = 4096
+
+// fountainFlags are the flags --fountain accepts besides itself: those that
+// shape key generation and the output files. Pattern flags make no sense
+// when every key is kept.
+var fountainFlags = []string{
+	"rotate", "output", "output-format", "workers", "count", "fast",
+	"timeout", "max-attempts", "address-style", "with-pubkey",
+	"pubkey-format", "pin-cpu", "quiet", "no-progress", "progress-interval",
+	"key-type",
+}
+
+// runFountain writes every key the workers generate to --output, starting a
+// new numbered file every --rotate keys, until --count keys are written or
+// the run is stopped. A dedicated goroutine drains the results into the
+// files, so the progress line never holds up the workers.
+func runFountain(cmd *cobra.Command) error {
+	var bad string
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if bad == "" && f.Name != "fountain" && !slices.Contains(fountainFlags, f.Name) {
+			bad = f.Name
+		}
+	})
+	switch {
+	case bad != "":
+		return fmt.Errorf("--fountain cannot be combined with --%s: it keeps every key, with no pattern", bad)
+	case flagOutput == "":
+		return fmt.Errorf("--fountain needs --output to write the keys to")
+	case !validOutputFormat(flagOutputFormat):
+		return fmt.Errorf("--output-format must be text, json or csv")
+	case flagRotate < 0:
+		return fmt.Errorf("--rotate must not be negative")
+	case flagCount < 0:
+		return fmt.Errorf("--count must not be negative")
+	case flagMaxAttempts < 0:
+		return fmt.Errorf("--max-attempts must not be negative")
+	case flagProgressTick <= 0:
+		return fmt.Errorf("--progress-interval must be positive")
+	case !validAddressStyle(flagAddrStyle):
+		return fmt.Errorf("--address-style must be checksum, lower, upper or bare")
+	case flagPubKeyFormat != generator.PublicKeyUncompressed && flagPubKeyFormat != generator.PublicKeyCompressed:
+		return fmt.Errorf("--pubkey-format must be uncompressed or compressed")
+	}
+	if err := generator.ValidateKeyType(flagKeyType); err != nil {
+		return fmt.Errorf("--key-type: %v", err)
+	}
+
+	// --count limits the run only when given; a fountain otherwise flows
+	// until stopped.
+	count := 0
+	if cmd.Flags().Changed("count") {
+		count = flagCount
+	}
+	cfg := generator.Config{
+		Workers:     flagWorkers,
+		Count:       count,
+		Fast:        flagFast,
+		MaxAttempts: flagMaxAttempts,
+		PinCPU:      flagPinCPU,
+		KeyType:     flagKeyType,
+	}
+	if flagWithPubKey {
+		cfg.PublicKey = flagPubKeyFormat
+	}
+
+	ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	if flagTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, flagTimeout)
+		defer cancelTimeout()
+	}
+
+	decorate := !flagQuiet
+	if decorate {
+		bold.Printf("vanity-eth fountain  •  workers: %d  •  keys: %s  •  to %s", cfg.Workers, countLabel(count), flagOutput)
+		if flagRotate > 0 {
+			fmt.Printf(" (a new file every %d keys)", flagRotate)
+		}
+		fmt.Println()
+		if flagFast {
+			yellow.Println("fast mode: keys are consecutive from one random base per worker")
+		}
+	}
+
+	stats := &generator.Stats{}
+	resultCh := make(chan generator.Result, fountainBuffer)
+	start := time.Now()
+	go generator.Run(ctx, cfg, resultCh, stats)
+
+	rw := &rotatingWriter{path: flagOutput, format: flagOutputFormat, rotate: flagRotate, columns: csvColumns()}
+	done := make(chan error, 1)
+	go func() {
+		var err error
+		for r := range resultCh {
+			if err == nil {
+				r.Address = styleAddress(r.Address, flagAddrStyle)
+				if err = rw.write(r); err != nil {
+					cancel()
+				}
+			}
+			r.PrivateKey.Zero()
+		}
+		if cerr := rw.close(); err == nil {
+			err = cerr
+		}
+		done <- err
+	}()
+
+	out := newConsole(os.Stdout)
+	ticker := time.NewTicker(flagProgressTick)
+	defer ticker.Stop()
+	var err error
+wait:
+	for {
+		select {
+		case err = <-done:
+			break wait
+		case <-ticker.C:
+			if decorate && !flagNoProgress && term.IsTerminal(os.Stdout.Fd()) {
+				elapsed := time.Since(start)
+				n := rw.written.Load()
+				out.showProgress(fmt.Sprintf("%s keys written  •  %d file(s)  •  %.0f keys/s",
+					formatBig(n), rw.files.Load(), float64(n)/elapsed.Seconds()))
+			}
+		}
+	}
+	out.finish()
+
+	elapsed := time.Since(start)
+	n := rw.written.Load()
+	if decorate {
+		fmt.Printf("\n%s  %d keys written to %d file(s)  •  %.0f keys/s  •  %s\n",
+			bold.Sprint("done"), n, rw.files.Load(), float64(n)/elapsed.Seconds(), elapsed.Round(time.Millisecond))
+	}
+	if err != nil {
+		return fmt.Errorf("--output: %w", err)
+	}
+	return stats.Err()
+}
+
+// rotatingWriter writes results to path in format. With rotate set it
+// writes numbered files next to path instead, each holding up to rotate
+// results: keys.csv becomes keys-000001.csv, keys-000002.csv, and so on.
+// Files are created readable by the owner only. write and close must be
+// called from one goroutine; written and files may be read from any.
+type rotatingWriter struct {
+	path, format string
+	rotate       int
+	columns      []string // extra CSV columns, as for newCSVStream
+
+	f      *os.File
+	w      *bufio.Writer
+	csv    *csvStream
+	inFile int
+
+	written atomic.Int64
+	files   atomic.Int64
+}
+
+// write adds r to the current file, starting the next one first if it is
+// full.
+func (rw *rotatingWriter) write(r generator.Result) error {
+	if rw.f == nil || rw.rotate > 0 && rw.inFile == rw.rotate {
+		if err := rw.next(); err != nil {
+			return err
+		}
+	}
+	var err error
+	switch rw.format {
+	case "csv":
+		err = rw.csv.write(r)
+	case "json":
+		var b []byte
+		if b, err = json.Marshal(ledger.ToJSON(r)); err == nil {
+			if rw.inFile > 0 {
+				rw.w.WriteString(",\n")
+			}
+			_, err = rw.w.Write(b)
+		}
+	default:
+		err = ledger.WriteText(rw.w, []generator.Result{r}, rw.inFile+1)
+	}
+	if err != nil {
+		return err
+	}
+	rw.inFile++
+	rw.written.Add(1)
+	return nil
+}
+
+// next closes the current file, if any, and opens the next one.
+func (rw *rotatingWriter) next() error {
+	if err := rw.close(); err != nil {
+		return err
+	}
+	name := rw.path
+	if rw.rotate > 0 {
+		name = rotatedName(rw.path, int(rw.files.Load())+1)
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	rw.f, rw.w, rw.inFile = f, bufio.NewWriter(f), 0
+	rw.files.Add(1)
+	switch rw.format {
+	case "csv":
+		rw.csv = newCSVStream(rw.w, rw.columns...)
+	case "json":
+		rw.w.WriteString("[\n")
+	}
+	return nil
+}
+
+// close finishes and closes the current file, if any.
+func (rw *rotatingWriter) close() error {
+	if rw.f == nil {
+		return nil
+	}
+	if rw.format == "json" {
+		rw.w.WriteString("\n]\n")
+	}
+	err := rw.w.Flush()
+	if cerr := rw.f.Close(); err == nil {
+		err = cerr
+	}
+	rw.f = nil
+	return err
+}
+
+// rotatedName numbers path for the n-th rotated file, before its extension.
+func rotatedName(path string, n int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%06d%s", strings.TrimSuffix(path, ext), n, ext)
+}
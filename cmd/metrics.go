@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"vanity-eth/internal/generator"
+)
+
+// startMetricsServer serves Prometheus metrics fed from stats on addr until
+// ctx is cancelled. The returned func stops it early.
+func startMetricsServer(ctx context.Context, addr string, stats *generator.Stats, start time.Time) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	srv := &http.Server{Handler: metricsHandler(stats, start), ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			red.Printf("--metrics: %v\n", err)
+		}
+	}()
+	stop := func() {
+		sctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = srv.Shutdown(sctx)
+	}
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return stop, nil
+}
+
+// metricsHandler serves the metrics of stats at /metrics.
+func metricsHandler(stats *generator.Stats, start time.Time) http.Handler {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "vanity_eth_attempts_total",
+			Help: "Candidate addresses generated and checked.",
+		}, func() float64 { return float64(stats.Total.Load()) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "vanity_eth_found_total",
+			Help: "Matching addresses found.",
+		}, func() float64 { return float64(stats.Found.Load()) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "vanity_eth_rate",
+			Help: "Average attempts per second since the search started.",
+		}, func() float64 { return float64(stats.Total.Load()) / time.Since(start).Seconds() }),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+	return mux
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"vanity-eth/internal/generator"
+)
+
+func TestMetricsHandler_ReportsStats(t *testing.T) {
+	stats := &generator.Stats{}
+	srv := httptest.NewServer(metricsHandler(stats, time.Now().Add(-10*time.Second)))
+	defer srv.Close()
+
+	scrape := func() string {
+		t.Helper()
+		resp, err := srv.Client().Get(srv.URL + "/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(body)
+	}
+
+	// The counters are read from stats on each scrape, not copied once.
+	stats.Total.Store(1000)
+	stats.Found.Store(2)
+	body := scrape()
+	for _, want := range []string{
+		"# TYPE vanity_eth_attempts_total counter\nvanity_eth_attempts_total 1000\n",
+		"# TYPE vanity_eth_found_total counter\nvanity_eth_found_total 2\n",
+		"# TYPE vanity_eth_rate gauge\nvanity_eth_rate ",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+
+	stats.Total.Store(5000)
+	if body := scrape(); !strings.Contains(body, "vanity_eth_attempts_total 5000\n") {
+		t.Errorf("second scrape did not see the new total:\n%s", body)
+	}
+}
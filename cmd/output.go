@@ -0,0 +1,613 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"vanity-eth/internal/ens"
+	"vanity-eth/internal/generator"
+	"vanity-eth/internal/ledger"
+	"vanity-eth/internal/proof"
+	"vanity-eth/internal/scoring"
+)
+
+// runSummary is the machine-readable record printed at the end of a run.
+type runSummary struct {
+	Attempts    int64   `json:"attempts"`
+	Found       int     `json:"found"`
+	Requested   int     `json:"requested"`
+	Rate        float64 `json:"rate"`
+	ElapsedMS   int64   `json:"elapsed_ms"`
+	Interrupted bool    `json:"interrupted"` // stopped before Requested were found
+}
+
+// writeSummary writes s as a single JSON line.
+func writeSummary(w io.Writer, s runSummary) error {
+	return json.NewEncoder(w).Encode(s)
+}
+
+// loadWordList returns the built-in list for "builtin", or reads path.
+func loadWordList(path string) ([]string, error) {
+	if path == "builtin" {
+		return generator.DefaultWords, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return generator.ParseWordList(f)
+}
+
+// loadPrefixList reads the prefixes for --prefix-file from path.
+func loadPrefixList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return generator.ParsePrefixList(f)
+}
+
+// loadBlocklist reads the words for --blocklist-file from path.
+func loadBlocklist(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return generator.ParseWordList(f)
+}
+
+func loadAvoidList(path string) (*generator.Bloom, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return generator.ParseAvoidList(f)
+}
+
+// withReverseNode sets r.ReverseNode if --with-namehash asks for it.
+func withReverseNode(r *generator.Result) {
+	if flagWithNamehash {
+		node := ens.ReverseNode(r.Address)
+		r.ReverseNode = hex.EncodeToString(node[:])
+	}
+}
+
+// withProof sets r.Proof to the key's signature of the address if --prove
+// asks for it. Signing fails only if the key does not produce the address,
+// which is a bug worth shouting about, so that goes to stderr.
+func withProof(r *generator.Result) {
+	if !flagProve || len(r.PrivateKey) == 0 {
+		return
+	}
+	sig, err := proof.Sign(r.PrivateKey, r.Address)
+	if err != nil {
+		red.Fprintf(os.Stderr, "--prove: %v\n", err)
+		return
+	}
+	r.Proof = sig
+}
+
+// withASCII sets r.Match to the address bytes as ASCII if --ascii is set,
+// so the word shows among them.
+func withASCII(r *generator.Result) {
+	if flagASCII != "" {
+		r.Match = generator.ASCIIText(r.Address)
+	}
+}
+
+// dumpConfig writes cfg as indented JSON, for --dump-config. Secrets and
+// fields without a JSON form are left out; see generator.Config.
+func dumpConfig(w io.Writer, cfg generator.Config) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(cfg)
+}
+
+func validAddressStyle(style string) bool {
+	switch style {
+	case "", "checksum", "lower", "upper", "bare":
+		return true
+	}
+	return false
+}
+
+// styleAddress renders a found 0x address in the given --address-style.
+// Matching has already happened, so this only affects presentation.
+func styleAddress(addr, style string) string {
+	switch style {
+	case "checksum":
+		return generator.ChecksumAddress(addr)
+	case "lower":
+		return strings.ToLower(addr)
+	case "upper":
+		return "0x" + strings.ToUpper(strings.TrimPrefix(addr, "0x"))
+	case "bare":
+		return strings.TrimPrefix(addr, "0x")
+	}
+	return addr
+}
+
+func validOutputFormat(format string) bool {
+	switch format {
+	case "text", "json", "csv":
+		return true
+	}
+	return false
+}
+
+// saveToFile writes results to path in the given --output-format. With
+// appendMode, results are added to an existing file instead of replacing
+// it: text continues the #N numbering, CSV follows the columns of the
+// file's header if it has one, and JSON is re-read and replaced as one
+// array.
+func saveToFile(path, format string, results []generator.Result, appendMode bool) error {
+	if appendMode {
+		switch format {
+		case "text":
+			return ledger.AppendText(path, results)
+		case "csv":
+			return appendCSV(path, results)
+		case "json":
+			return appendJSON(path, results)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeResults(f, format, results); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// outputStream saves results to a file as they arrive, flushing each one,
+// so a fifo's reader or tail -f sees it at once. The file ends up as
+// saveToFile would have written the same results at the end of the run.
+type outputStream struct {
+	f      *os.File
+	w      *bufio.Writer
+	format string
+	csv    *csvStream
+	n      int
+	err    error // the first write error; later writes are skipped
+}
+
+// openOutputStream truncates or creates path, or opens the fifo at path,
+// waiting for a reader. columns are the optional CSV columns, as for
+// newCSVStream.
+func openOutputStream(path, format string, columns []string) (*outputStream, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	s := &outputStream{f: f, w: bufio.NewWriter(f), format: format}
+	if format == "csv" {
+		s.csv = newCSVStream(s.w, columns...)
+		s.err = s.w.Flush()
+	}
+	return s, nil
+}
+
+func (s *outputStream) write(r generator.Result) {
+	if s.err != nil {
+		return
+	}
+	s.n++
+	switch s.format {
+	case "json":
+		// The layout of ledger.WriteJSON, one element at a time.
+		sep := ",\n  "
+		if s.n == 1 {
+			sep = "[\n  "
+		}
+		var b []byte
+		if b, s.err = json.MarshalIndent(ledger.ToJSON(r), "  ", "  "); s.err == nil {
+			s.w.WriteString(sep)
+			s.w.Write(b)
+		}
+	case "csv":
+		s.err = s.csv.write(r)
+	default:
+		s.err = ledger.WriteText(s.w, []generator.Result{r}, s.n)
+	}
+	if s.err == nil {
+		s.err = s.w.Flush()
+	}
+}
+
+// close finishes the file and reports the first error writing it.
+func (s *outputStream) close() error {
+	if s.err == nil && s.format == "json" {
+		if s.n == 0 {
+			s.w.WriteString("[]\n")
+		} else {
+			s.w.WriteString("\n]\n")
+		}
+		s.err = s.w.Flush()
+	}
+	if err := s.f.Close(); s.err == nil {
+		s.err = err
+	}
+	return s.err
+}
+
+// isFifo reports whether path names an existing named pipe.
+func isFifo(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&fs.ModeNamedPipe != 0
+}
+
+func writeResults(w io.Writer, format string, results []generator.Result) error {
+	switch format {
+	case "json":
+		return ledger.WriteJSON(w, results)
+	case "csv":
+		return writeCSV(w, results)
+	default:
+		return ledger.WriteText(w, results, 1)
+	}
+}
+
+// appendCSV adds results to the CSV file at path, creating it with a
+// header if it is missing or empty. Rows follow the existing header's
+// columns, so a file started with other optional columns stays aligned; if
+// the header lacks a column the results need, nothing is written.
+func appendCSV(path string, results []generator.Result) error {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if info.Size() == 0 {
+		err = writeCSV(f, results)
+	} else {
+		var extra []string
+		if extra, err = csvHeaderExtra(f, results); err != nil {
+			err = fmt.Errorf("%s: %w", path, err)
+		} else {
+			s := &csvStream{w: csv.NewWriter(f), extra: extra}
+			for _, r := range results {
+				if err = s.write(r); err != nil {
+					break
+				}
+			}
+		}
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// csvHeaderExtra reads the header row of a CSV results file from r and
+// returns its optional columns, checking that it has every column results
+// need and no column newCSVStream does not know.
+func csvHeaderExtra(r io.Reader, results []generator.Result) ([]string, error) {
+	header, err := csv.NewReader(r).Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading the CSV header: %v", err)
+	}
+	if len(header) < 2 || header[0] != "address" || header[1] != "private_key" {
+		return nil, fmt.Errorf("header %q does not start with address,private_key", strings.Join(header, ","))
+	}
+	extra := header[2:]
+	for _, col := range extra {
+		if !slices.Contains(csvOptional, col) {
+			return nil, fmt.Errorf("unknown column %q in the header", col)
+		}
+	}
+	for _, col := range csvExtra(results) {
+		if !slices.Contains(extra, col) {
+			return nil, fmt.Errorf("the header has no %s column, which these results need; append to a new file instead", col)
+		}
+	}
+	return extra, nil
+}
+
+// appendJSON adds results to the JSON array in the file at path, creating
+// it if needed. The whole array is written to a temporary file next to
+// path and renamed over it, so a failed write leaves the old file intact.
+func appendJSON(path string, results []generator.Result) error {
+	var existing []ledger.JSONResult
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	case len(bytes.TrimSpace(data)) > 0:
+		if err := json.Unmarshal(data, &existing); err != nil {
+			return fmt.Errorf("%s is not a JSON result array: %v", path, err)
+		}
+	}
+	for _, r := range results {
+		existing = append(existing, ledger.ToJSON(r))
+	}
+	// CreateTemp makes the file 0600.
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err = enc.Encode(existing); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, results []generator.Result) error {
+	s := newCSVStream(w, csvExtra(results)...)
+	for _, r := range results {
+		if err := s.write(r); err != nil {
+			return err
+		}
+	}
+	return s.w.Error()
+}
+
+// sortResults orders results by address, ignoring case and any 0x, so
+// runs that find the same addresses print them identically whichever worker
+// found each first.
+func sortResults(results []generator.Result) {
+	key := func(r generator.Result) string {
+		return strings.ToLower(strings.TrimPrefix(r.Address, "0x"))
+	}
+	slices.SortStableFunc(results, func(a, b generator.Result) int {
+		return strings.Compare(key(a), key(b))
+	})
+}
+
+// rankMetrics are the metrics --rank-by orders results by, highest first.
+// Each gets the address body in lowercase.
+var rankMetrics = map[string]func(body string) int{
+	"leading-zeros": scoring.LeadingZeros,
+	"zero-bytes":    scoring.ZeroBytes,
+	"runs":          scoring.Runs,
+	"palindrome":    scoring.Palindrome,
+	"score":         scoring.Score,
+}
+
+// addressBody returns addr's hex body in lowercase, whatever its
+// --address-style.
+func addressBody(addr string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(addr, "0x"), "0X"))
+}
+
+// rankResults orders results by metric, highest first. Ties keep their
+// order, so after sortResults they are ordered by address.
+func rankResults(results []generator.Result, metric func(string) int) {
+	slices.SortStableFunc(results, func(a, b generator.Result) int {
+		return cmp.Compare(metric(addressBody(b.Address)), metric(addressBody(a.Address)))
+	})
+}
+
+// printRanking lists ranked results with their value for the metric named
+// name, once a text search is done.
+func printRanking(w io.Writer, results []generator.Result, name string, metric func(string) int) {
+	if len(results) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nranked by %s:\n", name)
+	for i, r := range results {
+		fmt.Fprintf(w, "%4d. %s  %d\n", i+1, r.Address, metric(addressBody(r.Address)))
+	}
+}
+
+// tableKeyDigits is how many hex digits of each private key --format table
+// shows without --full-keys.
+const tableKeyDigits = 16
+
+// printTable writes results as an aligned table of index, address and
+// private key, adding a pattern column when any result has one. Keys are
+// cut to tableKeyDigits digits unless full, and masked with --mask-keys;
+// --xpub results show their child index instead.
+func printTable(w io.Writer, results []generator.Result, full bool) {
+	withPattern := slices.ContainsFunc(results, func(r generator.Result) bool { return r.Pattern != "" })
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := "#\tADDRESS\tPRIVATE KEY"
+	if withPattern {
+		header += "\tPATTERN"
+	}
+	fmt.Fprintln(tw, header)
+	for i, r := range results {
+		key := "child " + strconv.FormatUint(uint64(r.ChildIndex), 10)
+		if r.XPub == "" {
+			key = shownKey(r.PrivateKey)
+			if !full && !flagMaskKeys && len(key) > tableKeyDigits {
+				key = key[:tableKeyDigits] + "..."
+			}
+			key = "0x" + key
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s", i+1, r.Address, key)
+		if withPattern {
+			fmt.Fprintf(tw, "\t%s", r.Pattern)
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+}
+
+// groupByAlternative orders results by the --count-per-alt alternative
+// they count towards, in the order of alts; each group keeps its order.
+func groupByAlternative(results []generator.Result, alts []string) {
+	slices.SortStableFunc(results, func(a, b generator.Result) int {
+		return cmp.Compare(slices.Index(alts, a.Pattern), slices.Index(alts, b.Pattern))
+	})
+}
+
+// printAlternatives lists each --count-per-alt alternative with how many of
+// its per results were found and their addresses, once a text search is
+// done.
+func printAlternatives(w io.Writer, results []generator.Result, alts []string, per int) {
+	width := 0
+	for _, alt := range alts {
+		width = max(width, len(alt))
+	}
+	fmt.Fprintln(w, "\nby alternative:")
+	for _, alt := range alts {
+		var addrs []string
+		for _, r := range results {
+			if r.Pattern == alt {
+				addrs = append(addrs, r.Address)
+			}
+		}
+		fmt.Fprintf(w, "  %-*s  %d/%d\n", width, alt, len(addrs), per)
+		for _, addr := range addrs {
+			fmt.Fprintf(w, "    %s\n", addr)
+		}
+	}
+}
+
+// csvOptional lists every optional CSV column newCSVStream can write.
+var csvOptional = []string{
+	"pattern", "mnemonic", "derivation_path", "brainwallet_salt",
+	"xpub_index", "public_key", "ens_reverse_node", "proof",
+}
+
+// csvExtra returns the optional CSV columns results need.
+func csvExtra(results []generator.Result) []string {
+	var extra []string
+	for _, r := range results {
+		if r.Pattern != "" && !slices.Contains(extra, "pattern") {
+			extra = append(extra, "pattern")
+		}
+		if r.Mnemonic != "" && !slices.Contains(extra, "mnemonic") {
+			extra = append(extra, "mnemonic", "derivation_path")
+		}
+		if r.Passphrase != "" && !slices.Contains(extra, "brainwallet_salt") {
+			extra = append(extra, "brainwallet_salt")
+		}
+		if r.XPub != "" && !slices.Contains(extra, "xpub_index") {
+			extra = append(extra, "xpub_index")
+		}
+		if r.PublicKey != "" && !slices.Contains(extra, "public_key") {
+			extra = append(extra, "public_key")
+		}
+		if r.ReverseNode != "" && !slices.Contains(extra, "ens_reverse_node") {
+			extra = append(extra, "ens_reverse_node")
+		}
+		if r.Proof != "" && !slices.Contains(extra, "proof") {
+			extra = append(extra, "proof")
+		}
+	}
+	return extra
+}
+
+// csvStream writes results as CSV rows, flushing each one so a reader on
+// the other end of a pipe sees it as soon as it is found.
+type csvStream struct {
+	w     *csv.Writer
+	extra []string
+}
+
+// newCSVStream writes the header row immediately. extra names optional
+// columns after address and private_key: "pattern", "mnemonic",
+// "derivation_path", "brainwallet_salt", "xpub_index", "public_key",
+// "ens_reverse_node" and "proof".
+func newCSVStream(w io.Writer, extra ...string) *csvStream {
+	s := &csvStream{w: csv.NewWriter(w), extra: extra}
+	_ = s.w.Write(append([]string{"address", "private_key"}, extra...))
+	s.w.Flush()
+	return s
+}
+
+func (s *csvStream) write(r generator.Result) error {
+	j := ledger.ToJSON(r)
+	row := []string{j.Address, j.PrivateKey}
+	for _, col := range s.extra {
+		switch col {
+		case "pattern":
+			row = append(row, j.Pattern)
+		case "mnemonic":
+			row = append(row, j.Mnemonic)
+		case "derivation_path":
+			row = append(row, j.DerivationPath)
+		case "brainwallet_salt":
+			if j.BrainwalletSalt != nil {
+				row = append(row, strconv.FormatUint(*j.BrainwalletSalt, 10))
+			} else {
+				row = append(row, "")
+			}
+		case "public_key":
+			row = append(row, j.PublicKey)
+		case "ens_reverse_node":
+			row = append(row, j.ReverseNode)
+		case "proof":
+			row = append(row, j.Proof)
+		case "xpub_index":
+			if j.XPubIndex != nil {
+				row = append(row, strconv.FormatUint(uint64(*j.XPubIndex), 10))
+			} else {
+				row = append(row, "")
+			}
+		}
+	}
+	_ = s.w.Write(row)
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// csvColumns returns the optional CSV columns for a streamed search: the
+// given ones plus the pattern with --any, the phrase and path in mnemonic
+// mode, the salt in brainwallet mode, the child index in xpub mode, and
+// the public key with --with-pubkey, the namehash with --with-namehash,
+// and the signature with --prove.
+func csvColumns(extra ...string) []string {
+	if flagAny != "" {
+		extra = append(extra, "pattern")
+	}
+	if flagMnemonic {
+		extra = append(extra, "mnemonic", "derivation_path")
+	}
+	if flagBrainwallet != "" {
+		extra = append(extra, "brainwallet_salt")
+	}
+	if flagXPub != "" {
+		extra = append(extra, "xpub_index")
+	}
+	if flagWithPubKey {
+		extra = append(extra, "public_key")
+	}
+	if flagWithNamehash {
+		extra = append(extra, "ens_reverse_node")
+	}
+	if flagProve {
+		extra = append(extra, "proof")
+	}
+	return extra
+}
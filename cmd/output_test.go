@@ -0,0 +1,298 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"vanity-eth/internal/generator"
+	"vanity-eth/internal/proof"
+)
+
+func TestSortResults_IgnoresCaseAndPrefix(t *testing.T) {
+	rs := []generator.Result{{Address: "0xBB"}, {Address: "aa"}, {Address: "0xab"}}
+	sortResults(rs)
+	for i, want := range []string{"aa", "0xab", "0xBB"} {
+		if rs[i].Address != want {
+			t.Fatalf("order = %v", rs)
+		}
+	}
+}
+
+func TestRankResults_BestFirstStable(t *testing.T) {
+	rs := []generator.Result{
+		{Address: "0xab00000000000000000000000000000000000000"},
+		{Address: "0x000F000000000000000000000000000000000001"},
+		{Address: "0x0c00000000000000000000000000000000000000"},
+		{Address: "0x0d00000000000000000000000000000000000000"},
+	}
+	rankResults(rs, rankMetrics["leading-zeros"])
+	for i, want := range []string{"0x000F", "0x0c", "0x0d", "0xab"} {
+		if !strings.HasPrefix(rs[i].Address, want) {
+			t.Fatalf("rank %d = %s, want %s...", i+1, rs[i].Address, want)
+		}
+	}
+}
+
+func TestPrintAlternatives_GroupsAndShowsMissing(t *testing.T) {
+	alts := []string{"dead", "beef", "cafe"}
+	rs := []generator.Result{
+		{Address: "0xcafe1", Pattern: "cafe"},
+		{Address: "0xdead1", Pattern: "dead"},
+		{Address: "0xcafe2", Pattern: "cafe"},
+	}
+	groupByAlternative(rs, alts)
+	for i, want := range []string{"0xdead1", "0xcafe1", "0xcafe2"} {
+		if rs[i].Address != want {
+			t.Fatalf("order = %v", rs)
+		}
+	}
+	var b bytes.Buffer
+	printAlternatives(&b, rs, alts, 2)
+	for _, want := range []string{"dead  1/2", "beef  0/2", "cafe  2/2\n    0xcafe1\n    0xcafe2"} {
+		if !strings.Contains(b.String(), want) {
+			t.Fatalf("output lacks %q:\n%s", want, b.String())
+		}
+	}
+}
+
+func TestDifficultyComparison_NearestPrefix(t *testing.T) {
+	cases := []struct {
+		d    *big.Int
+		want string
+	}{
+		{big.NewInt(65536), "as hard as finding a specific 4-character prefix (~65.5K attempts)"},
+		{big.NewInt(80000), "about as hard as finding a specific 4-character prefix (~65.5K attempts)"},
+		{big.NewInt(900000), "about as hard as finding a specific 5-character prefix (~1.05M attempts)"},
+		{big.NewInt(2), "about as hard as finding a specific 1-character prefix (~16 attempts)"},
+	}
+	for _, tc := range cases {
+		if got := difficultyComparison(tc.d); got != tc.want {
+			t.Errorf("difficultyComparison(%s) = %q, want %q", tc.d, got, tc.want)
+		}
+	}
+}
+
+func TestPrintQuietResult_OneLine(t *testing.T) {
+	var buf bytes.Buffer
+	key := make(generator.PrivateKey, 32)
+	key[31] = 1
+	printQuietResult(&buf, generator.Result{Address: "0xdead", Checksum: "0xDEAD", PrivateKey: key, Match: "x"})
+	want := "0xdead 0x0000000000000000000000000000000000000000000000000000000000000001\n"
+	if buf.String() != want {
+		t.Fatalf("got %q want %q", buf.String(), want)
+	}
+}
+
+func TestWithProof_SignsInEveryFormat(t *testing.T) {
+	flagProve = true
+	defer func() { flagProve = false }()
+	key := make(generator.PrivateKey, 32)
+	key[31] = 1
+	r := generator.Result{Address: "0x7e5f4552091a69125d5dfcb7b8c2659029395bdf", PrivateKey: key}
+	withProof(&r)
+	if err := proof.Verify(r.Address, r.Proof); err != nil {
+		t.Fatalf("proof %q: %v", r.Proof, err)
+	}
+	var buf bytes.Buffer
+	printQuietResult(&buf, r)
+	if !strings.HasSuffix(buf.String(), " 0x"+r.Proof+"\n") {
+		t.Errorf("quiet line %q lacks the proof", buf.String())
+	}
+	if cols := csvColumns(); !slices.Contains(cols, "proof") {
+		t.Errorf("csvColumns = %v, want proof", cols)
+	}
+	xpub := generator.Result{Address: r.Address, XPub: "xpub…"}
+	withProof(&xpub)
+	if xpub.Proof != "" {
+		t.Errorf("signed a result with no key: %q", xpub.Proof)
+	}
+}
+
+func TestConfirmLongSearch_AsksOnlyPastThreshold(t *testing.T) {
+	var w bytes.Buffer
+	if !confirmLongSearch(strings.NewReader(""), &w, 30*time.Minute) || w.Len() != 0 {
+		t.Fatalf("short search prompted: %q", w.String())
+	}
+	for in, want := range map[string]bool{"y\n": true, "Yes\n": true, "n\n": false, "\n": false, "": false} {
+		w.Reset()
+		if got := confirmLongSearch(strings.NewReader(in), &w, 3*time.Hour); got != want {
+			t.Errorf("answer %q = %v, want %v", in, got, want)
+		}
+		if !strings.Contains(w.String(), "Continue? [y/N]") {
+			t.Errorf("prompt = %q", w.String())
+		}
+	}
+}
+
+func TestRotatingWriter_StartsNumberedFiles(t *testing.T) {
+	dir := t.TempDir()
+	rw := &rotatingWriter{path: filepath.Join(dir, "keys.csv"), format: "csv", rotate: 2}
+	for i := 0; i < 5; i++ {
+		r := generator.Result{Address: fmt.Sprintf("0x%040x", i), PrivateKey: make(generator.PrivateKey, 32)}
+		if err := rw.write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rw.close(); err != nil {
+		t.Fatal(err)
+	}
+	if rw.written.Load() != 5 || rw.files.Load() != 3 {
+		t.Fatalf("wrote %d keys to %d files, want 5 to 3", rw.written.Load(), rw.files.Load())
+	}
+	for n, rows := range []int{2, 2, 1} {
+		name := filepath.Join(dir, fmt.Sprintf("keys-%06d.csv", n+1))
+		b, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if lines := strings.Count(string(b), "\n"); lines != rows+1 {
+			t.Errorf("%s has %d lines, want a header and %d rows", name, lines, rows)
+		}
+	}
+}
+
+func TestOutputStream_MatchesSaveToFile(t *testing.T) {
+	dir := t.TempDir()
+	for _, format := range []string{"text", "json", "csv"} {
+		for _, n := range []int{0, 1, 3} {
+			var results []generator.Result
+			for i := 0; i < n; i++ {
+				results = append(results, generator.Result{Address: fmt.Sprintf("0x%040x", i), PrivateKey: make(generator.PrivateKey, 32)})
+			}
+			want := filepath.Join(dir, "want."+format)
+			if err := saveToFile(want, format, results, false); err != nil {
+				t.Fatal(err)
+			}
+			got := filepath.Join(dir, "got."+format)
+			s, err := openOutputStream(got, format, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range results {
+				s.write(r)
+			}
+			if err := s.close(); err != nil {
+				t.Fatal(err)
+			}
+			wb, _ := os.ReadFile(want)
+			gb, _ := os.ReadFile(got)
+			if !bytes.Equal(gb, wb) {
+				t.Errorf("%s with %d results: streamed\n%s\nwant\n%s", format, n, gb, wb)
+			}
+		}
+	}
+}
+
+func TestAppendCSV_FollowsExistingHeader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.csv")
+	key := make(generator.PrivateKey, 32)
+	first := []generator.Result{{Address: "0x01", PrivateKey: key, Pattern: "dead", PublicKey: "04ab"}}
+	if err := saveToFile(path, "csv", first, true); err != nil {
+		t.Fatal(err)
+	}
+	// Fewer optional columns than the file has: the row gets blanks.
+	if err := saveToFile(path, "csv", []generator.Result{{Address: "0x02", PrivateKey: key, PublicKey: "04cd"}}, true); err != nil {
+		t.Fatal(err)
+	}
+	b, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	if len(lines) != 3 || lines[0] != "address,private_key,pattern,public_key" {
+		t.Fatalf("unexpected file:\n%s", b)
+	}
+	if want := "0x02,0x" + key.String() + ",,0x04cd"; lines[2] != want {
+		t.Fatalf("appended row = %q, want %q", lines[2], want)
+	}
+}
+
+func TestAppendCSV_RejectsMissingColumn(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.csv")
+	key := make(generator.PrivateKey, 32)
+	if err := saveToFile(path, "csv", []generator.Result{{Address: "0x01", PrivateKey: key}}, true); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(path)
+	err := saveToFile(path, "csv", []generator.Result{{Address: "0x02", PrivateKey: key, Proof: "ab"}}, true)
+	if err == nil || !strings.Contains(err.Error(), "proof") {
+		t.Fatalf("err = %v, want a missing proof column", err)
+	}
+	if after, _ := os.ReadFile(path); !bytes.Equal(after, before) {
+		t.Fatalf("file changed on a mismatch:\n%s", after)
+	}
+}
+
+func TestAppendJSON_ReplacesFileWhole(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keys.json")
+	key := make(generator.PrivateKey, 32)
+	for _, addr := range []string{"0x01", "0x02"} {
+		if err := saveToFile(path, "json", []generator.Result{{Address: addr, PrivateKey: key}}, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, _ := os.ReadFile(path)
+	if strings.Count(string(b), `"address"`) != 2 {
+		t.Fatalf("want both results:\n%s", b)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("want only %s left in the directory, got %d entries", path, len(entries))
+	}
+
+	// A file that is not a result array is left alone.
+	os.WriteFile(path, []byte("not json"), 0o600)
+	if err := saveToFile(path, "json", []generator.Result{{Address: "0x03", PrivateKey: key}}, true); err == nil {
+		t.Fatal("want an error for a file that is not JSON")
+	}
+	if b, _ := os.ReadFile(path); string(b) != "not json" {
+		t.Fatalf("file changed: %q", b)
+	}
+}
+
+func TestPrintDifficultyBreakdown_ShowsCaseFactor(t *testing.T) {
+	var buf bytes.Buffer
+	printDifficultyBreakdown(&buf, generator.Config{Prefix: "dEAd", CaseSensitive: true})
+	if want := "16^4 × 2^4 = 65.5K × 16 = 1.05M"; !strings.Contains(buf.String(), want) {
+		t.Errorf("case-sensitive breakdown %q lacks %q", buf.String(), want)
+	}
+	buf.Reset()
+	printDifficultyBreakdown(&buf, generator.Config{Prefix: "dead", Contains: "00"})
+	for _, want := range []string{"16^6 = 16.78M", "16 times harder: 2^4"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("breakdown %q lacks %q", buf.String(), want)
+		}
+	}
+}
+
+func TestPrintTable_AlignsAndTruncatesKeys(t *testing.T) {
+	key := make(generator.PrivateKey, 32)
+	key[31] = 1
+	results := []generator.Result{
+		{Address: "0xdead", PrivateKey: key},
+		{Address: "0xdeadbeef", PrivateKey: key, Pattern: "dead"},
+	}
+	var buf bytes.Buffer
+	printTable(&buf, results, false)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "#  ADDRESS     PRIVATE KEY") || !strings.HasSuffix(lines[0], "PATTERN") {
+		t.Fatalf("unexpected table:\n%s", buf.String())
+	}
+	want := "0x" + strings.Repeat("0", tableKeyDigits) + "..."
+	if !strings.Contains(lines[1], "0xdead      "+want) {
+		t.Fatalf("row not aligned or key not cut: %q", lines[1])
+	}
+
+	buf.Reset()
+	printTable(&buf, results[:1], true)
+	if !strings.Contains(buf.String(), "0x"+key.String()) || strings.Contains(buf.String(), "PATTERN") {
+		t.Fatalf("want the full key and no pattern column:\n%s", buf.String())
+	}
+}
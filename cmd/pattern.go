@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"vanity-eth/internal/generator"
+)
+
+// patternFlags are the flags that describe which addresses a search wants.
+// The root command and estimate both register them through register and
+// read them through config, so a pattern is spelled and checked the same
+// way whether it is searched for or only estimated.
+type patternFlags struct {
+	chain                    string
+	prefix, suffix, contains string
+	exclude                  []string
+	near                     string
+	maxDistance              int
+	mod, remainder           string
+	prefixFile, wordlist     string
+	spells, leet             string
+	invert, caseSensitive    bool
+}
+
+// hexPatternFlags are the pattern flags that describe hex addresses and so
+// are rejected for base58 chains.
+var hexPatternFlags = []string{"exclude", "near", "mod", "remainder", "prefix-file", "wordlist", "spells"}
+
+// register defines the pattern flags on fs.
+func (p *patternFlags) register(fs *pflag.FlagSet) {
+	fs.StringVar(&p.chain, "chain", generator.ChainEthereum, "address encoding to search: eth, tron (base58 T… addresses) or btc (P2PKH 1… addresses); patterns are base58 for the latter two")
+	fs.StringVarP(&p.prefix, "prefix", "p", "", "address must start with this hex string (after 0x)")
+	fs.StringVarP(&p.suffix, "suffix", "s", "", "address must end with this hex string")
+	fs.StringVarP(&p.contains, "contains", "c", "", "address must contain this hex string")
+	fs.StringArrayVar(&p.exclude, "exclude", nil, "reject addresses containing this hex string, even if the rest matches (repeatable)")
+	fs.StringVar(&p.near, "near", "", "address must be within --max-distance nibbles of this address")
+	fs.IntVar(&p.maxDistance, "max-distance", 4, "max differing nibbles allowed by --near")
+	fs.StringVar(&p.mod, "mod", "", "address, read as a 160-bit number, must leave --remainder when divided by this")
+	fs.StringVar(&p.remainder, "remainder", "0", "remainder required by --mod")
+	fs.StringVar(&p.prefixFile, "prefix-file", "", "address must start with a prefix from this file (one hex prefix per line); the one found is reported")
+	fs.StringVar(&p.wordlist, "wordlist", "", `address must contain a word from this file (one hex word per line), or "builtin"`)
+	fs.StringVar(&p.spells, "spells", "", "address must contain a hex spelling of this word, e.g. dose → d05e|d053")
+	fs.StringVar(&p.leet, "leet", "", "extra letter=hexdigits substitutions for --spells, e.g. o=0,e=e3")
+	fs.BoolVar(&p.invert, "invert", false, "find addresses that do NOT match the pattern")
+	fs.BoolVar(&p.caseSensitive, "case-sensitive", false, "case-sensitive matching (checksummed address)")
+}
+
+// empty reports whether none of the flags asks for anything.
+func (p *patternFlags) empty() bool {
+	return p.prefix == "" && p.suffix == "" && p.contains == "" && len(p.exclude) == 0 && p.near == "" &&
+		p.mod == "" && p.prefixFile == "" && p.wordlist == "" && p.spells == ""
+}
+
+// config checks the flags and returns a Config with the pattern fields set,
+// reading --prefix-file and --wordlist. changed reports whether a flag was
+// given on the command line.
+func (p *patternFlags) config(changed func(name string) bool) (generator.Config, error) {
+	cfg := generator.Config{
+		Chain:         p.chain,
+		Prefix:        p.prefix,
+		Suffix:        p.suffix,
+		Contains:      p.contains,
+		Exclude:       p.exclude,
+		Near:          p.near,
+		MaxDistance:   p.maxDistance,
+		Invert:        p.invert,
+		CaseSensitive: p.caseSensitive,
+	}
+	if err := generator.ValidateChain(p.chain); err != nil {
+		return cfg, fmt.Errorf("--chain: %v", err)
+	}
+	base58 := p.chain != generator.ChainEthereum
+	if base58 {
+		for _, name := range hexPatternFlags {
+			if changed(name) {
+				return cfg, fmt.Errorf("--%s cannot be combined with --chain %s", name, p.chain)
+			}
+		}
+	}
+
+	// Hex patterns, or base58 for Tron and Bitcoin.
+	for _, f := range []struct{ flag, val string }{{"prefix", p.prefix}, {"suffix", p.suffix}, {"contains", p.contains}} {
+		if f.val == "" {
+			continue
+		}
+		err := generator.ValidateHexPattern(f.val)
+		if base58 {
+			err = generator.ValidateBase58Pattern(p.chain, f.val, f.flag == "prefix", p.caseSensitive)
+		}
+		if err != nil {
+			return cfg, fmt.Errorf("--%s: %v", f.flag, err)
+		}
+	}
+	for _, ex := range p.exclude {
+		if err := generator.ValidateHexPattern(ex); err != nil {
+			return cfg, fmt.Errorf("--exclude: %v", err)
+		}
+	}
+
+	if p.near != "" {
+		if err := generator.ValidateNearTarget(p.near); err != nil {
+			return cfg, fmt.Errorf("--near: %v", err)
+		}
+		if p.maxDistance < 0 || p.maxDistance > 40 {
+			return cfg, fmt.Errorf("--max-distance must be between 0 and 40")
+		}
+	}
+
+	if p.mod != "" {
+		var err error
+		if cfg.Mod, cfg.Remainder, err = generator.ParseMod(p.mod, p.remainder); err != nil {
+			return cfg, fmt.Errorf("--mod: %v", err)
+		}
+	} else if changed("remainder") {
+		return cfg, fmt.Errorf("--remainder requires --mod")
+	}
+
+	if p.prefixFile != "" {
+		if p.prefix != "" {
+			return cfg, fmt.Errorf("--prefix-file cannot be combined with --prefix")
+		}
+		var err error
+		if cfg.Prefixes, err = loadPrefixList(p.prefixFile); err != nil {
+			return cfg, fmt.Errorf("--prefix-file: %v", err)
+		}
+	}
+	if p.wordlist != "" {
+		var err error
+		if cfg.Words, err = loadWordList(p.wordlist); err != nil {
+			return cfg, fmt.Errorf("--wordlist: %v", err)
+		}
+	}
+
+	if p.spells != "" {
+		table, err := generator.ParseLeetTable(p.leet)
+		if err != nil {
+			return cfg, fmt.Errorf("--leet: %v", err)
+		}
+		if cfg.Spellings, err = generator.Spellings(p.spells, table); err != nil {
+			return cfg, fmt.Errorf("--spells: %v", err)
+		}
+	}
+	return cfg, nil
+}
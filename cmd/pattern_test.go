@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"vanity-eth/internal/generator"
+)
+
+// parsePattern registers the pattern flags on a fresh flag set, parses args
+// and returns the resulting Config.
+func parsePattern(t *testing.T, args ...string) (generator.Config, error) {
+	t.Helper()
+	var p patternFlags
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	p.register(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return p.config(fs.Changed)
+}
+
+func TestPatternFlags_Config(t *testing.T) {
+	words := filepath.Join(t.TempDir(), "words.txt")
+	if err := os.WriteFile(words, []byte("dead\nbeef\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := parsePattern(t, "-p", "dead", "--suffix", "beef", "--exclude", "00", "--exclude", "11",
+		"--mod", "7", "--remainder", "3", "--wordlist", words, "--spells", "dose", "--case-sensitive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Chain != generator.ChainEthereum || cfg.Prefix != "dead" || cfg.Suffix != "beef" || !cfg.CaseSensitive {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if !slices.Equal(cfg.Exclude, []string{"00", "11"}) {
+		t.Errorf("Exclude = %v", cfg.Exclude)
+	}
+	if cfg.Mod == nil || cfg.Mod.Int64() != 7 || cfg.Remainder.Int64() != 3 {
+		t.Errorf("Mod, Remainder = %v, %v", cfg.Mod, cfg.Remainder)
+	}
+	if !slices.Equal(cfg.Words, []string{"dead", "beef"}) {
+		t.Errorf("Words = %v", cfg.Words)
+	}
+	if len(cfg.Spellings) == 0 {
+		t.Error("Spellings is empty")
+	}
+}
+
+func TestPatternFlags_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--chain", "doge"}, "--chain:"},
+		{[]string{"--prefix", "xyz"}, "--prefix:"},
+		{[]string{"--exclude", "g"}, "--exclude:"},
+		{[]string{"--near", "0x1234"}, "--near:"},
+		{[]string{"--near", "0x" + strings.Repeat("0", 40), "--max-distance", "41"}, "--max-distance"},
+		{[]string{"--remainder", "1"}, "--remainder requires --mod"},
+		{[]string{"--prefix-file", "x", "--prefix", "ab"}, "--prefix-file cannot be combined"},
+		{[]string{"--wordlist", filepath.Join(t.TempDir(), "missing")}, "--wordlist:"},
+		{[]string{"--spells", "dose", "--leet", "oops"}, "--leet:"},
+		{[]string{"--chain", "tron", "--exclude", "00"}, "--exclude cannot be combined with --chain tron"},
+		{[]string{"--chain", "btc", "--remainder", "0"}, "--remainder cannot be combined with --chain btc"},
+	} {
+		_, err := parsePattern(t, tc.args...)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v: error = %v, want it to contain %q", tc.args, err, tc.want)
+		}
+	}
+}
+
+func TestPatternFlags_Empty(t *testing.T) {
+	var p patternFlags
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	p.register(fs)
+	if !p.empty() {
+		t.Error("empty() = false with no flags set")
+	}
+	if err := fs.Parse([]string{"--exclude", "00"}); err != nil {
+		t.Fatal(err)
+	}
+	if p.empty() {
+		t.Error("empty() = true with --exclude set")
+	}
+}
+
+// The root command and estimate must accept the same pattern flags.
+func TestPatternFlags_SharedByRootAndEstimate(t *testing.T) {
+	var p patternFlags
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	p.register(fs)
+	fs.VisitAll(func(f *pflag.Flag) {
+		for _, cmdFlags := range []*pflag.FlagSet{rootCmd.Flags(), estimateCmd.Flags()} {
+			if cmdFlags.Lookup(f.Name) == nil {
+				t.Errorf("--%s is not registered on every command", f.Name)
+			}
+		}
+	})
+}
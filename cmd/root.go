@@ -1,38 +1,105 @@
 package cmd
 
 import (
-	"encoding/json"
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"math/big"
 	"os"
 	"os/signal"
 	"regexp"
-	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/charmbracelet/x/term"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"vanity-eth/internal/generator"
+	"vanity-eth/internal/humanize"
+	"vanity-eth/internal/ledger"
+	"vanity-eth/internal/tui"
 )
 
 // version is set at build time via -ldflags "-X vanity-eth/cmd.version=vX.Y.Z"
 var version = "dev"
 
 var (
-	flagPrefix   string
-	flagSuffix   string
-	flagContains string
-	flagRegex    string
-	flagWorkers  int
-	flagCount    int
-	flagCase     bool
-	flagTUI      bool
-	flagOutput   string
-	flagFormat   string
+	flagRegex     string
+	flagRegexBody string
+	flagWorkers   int
+	flagCount     int
+	flagTUI       bool
+	flagOutput    string
+	flagFormat    string
+	flagFast      bool
+	flagTimeout   time.Duration
+	flagPattern   patternFlags
+
+	flagOutputFormat string
+	flagMinScore     int
+	flagServe        string
+	flagServeKeys    bool
+	flagNoProgress   bool
+	flagQuiet        bool
+	flagMaskKeys     bool
+	flagFullKeys     bool
+	flagAddrStyle    string
+	flagAudit        bool
+	flagRejectWeak   bool
+	flagMetrics      string
+	flagSample       int
+	flagNoColor      bool
+	flagBatch        string
+	flagAny          string
+	flagMnemonic     bool
+	flagMnemonicLen  int
+	flagDerivation   string
+	flagProgressTick time.Duration
+	flagAppend       bool
+	flagAutoWorkers  bool
+	flagSummaryJSON  bool
+	flagBrainwallet  string
+	flagStdinKey     bool
+	flagXPub         string
+	flagResumeFrom   []string
+	flagPrintResume  bool
+	flagWithPubKey   bool
+	flagWithNamehash bool
+	flagPinCPU       bool
+	flagAccelerator  string
+	flagSafe         bool
+	flagYes          bool
+	flagBlocklist    string
+	flagAvoidFile    string
+	flagPubKeyFormat string
+	flagSuffixDec    string
+	flagASCII        string
+	flagMaxRetain    int
+	flagVerbose      bool
+	flagKeyType      string
+	flagProve        bool
+	flagSort         bool
+	flagRankBy       string
+	flagCountPerAlt  int
+	flagFountain     bool
+	flagRotate       int
+	flagMaxAttempts  int64
+	flagAbandonAt    float64
+	flagPreview      bool
+	flagDumpConfig   bool
 )
 
+// showProgress is true when live progress lines and terminal control codes
+// may be written to stdout. It is decided once at the start of runCLI.
+var showProgress bool
+
 var (
 	green   = color.New(color.FgGreen, color.Bold)
 	yellow  = color.New(color.FgYellow, color.Bold)
@@ -64,85 +131,760 @@ Examples:
   vanity-eth --contains cafe --count 3
   vanity-eth --regex "^0x(dead|cafe)"
   vanity-eth              (launch interactive TUI)`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if flagNoColor || os.Getenv("NO_COLOR") != "" {
+			disableColor()
+		}
+	},
 	RunE: runRoot,
 }
 
+// exitPartial is the exit status of a search stopped by --max-attempts
+// before --count addresses were found, or of a --batch with such a pattern;
+// whatever was found is still output.
+const exitPartial = 2
+
+// errPartialResult is returned by runCLI and runBatch for an exitPartial
+// stop.
+var errPartialResult = errors.New("partial result")
+
+// errDeclined is returned by runCLI when the user turns down a long search
+// at the prompt.
+var errDeclined = errors.New("search declined")
+
+// longSearch is the ETA beyond which runCLI asks before starting.
+const longSearch = time.Hour
+
+// confirmLongSearch asks on w whether to start a search expected to take
+// eta, reading the answer from r. Searches up to longSearch need no answer.
+func confirmLongSearch(r io.Reader, w io.Writer, eta time.Duration) bool {
+	if eta <= longSearch {
+		return true
+	}
+	fmt.Fprintf(w, "This will take ~%s. Continue? [y/N] ", fmtDuration(eta))
+	line, _ := bufio.NewReader(r).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
+
 // Execute is the entry point called from main.
 func Execute() {
+	// SIGUSR1 would otherwise kill the process outside the searches that
+	// print a stats snapshot for it.
+	ignoreStatsDump()
 	if err := rootCmd.Execute(); err != nil {
+		if errors.Is(err, errPartialResult) {
+			os.Exit(exitPartial)
+		}
 		os.Exit(1)
 	}
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&flagPrefix, "prefix", "p", "", "address must start with this hex string (after 0x)")
-	rootCmd.Flags().StringVarP(&flagSuffix, "suffix", "s", "", "address must end with this hex string")
-	rootCmd.Flags().StringVarP(&flagContains, "contains", "c", "", "address must contain this hex string")
+	rootCmd.PersistentFlags().BoolVar(&flagNoColor, "no-color", false, "disable colored output (also honored: NO_COLOR env var)")
+	flagPattern.register(rootCmd.Flags())
+	rootCmd.Flags().StringVar(&flagKeyType, "key-type", generator.KeyTypeSecp256k1, "key scheme to generate keys for; this build supports "+strings.Join(generator.KeyTypes(), ", "))
+	rootCmd.Flags().StringVar(&flagSuffixDec, "suffix-decimal", "", "address must end with this decimal number in hex, padded to whole bytes (1337 → 0539)")
+	rootCmd.Flags().StringVar(&flagASCII, "ascii", "", "address bytes must spell this word in ASCII, e.g. cafe → 63616665 on a byte boundary")
 	rootCmd.Flags().StringVarP(&flagRegex, "regex", "r", "", "address must match this regex (applied to full 0x… address)")
-	rootCmd.Flags().IntVarP(&flagWorkers, "workers", "w", runtime.NumCPU(), "number of parallel workers")
-	rootCmd.Flags().IntVarP(&flagCount, "count", "n", 1, "how many matching addresses to find")
-	rootCmd.Flags().BoolVar(&flagCase, "case-sensitive", false, "case-sensitive matching (checksummed address)")
+	rootCmd.Flags().BoolVar(&flagSafe, "safe", false, "reject addresses containing a word from a small built-in list of offensive hex spellings (best effort)")
+	rootCmd.Flags().StringVar(&flagAvoidFile, "avoid-file", "", "never return an address listed in this file (one per line), e.g. ones already in use")
+	rootCmd.Flags().StringVar(&flagBlocklist, "blocklist-file", "", "reject addresses containing a hex word from this file; replaces the built-in --safe list, or adds to it with --safe")
+	rootCmd.Flags().StringVar(&flagRegexBody, "regex-body", "", "address must match this regex applied to the 40 hex chars without 0x")
+	rootCmd.Flags().IntVarP(&flagWorkers, "workers", "w", generator.DefaultWorkers(), "number of parallel workers")
+	rootCmd.Flags().BoolVar(&flagAutoWorkers, "auto-workers", false, "benchmark a few worker counts before searching and use the fastest")
+	rootCmd.Flags().IntVarP(&flagCount, "count", "n", 1, "how many matching addresses to find (0 = keep going until stopped)")
+	rootCmd.Flags().BoolVar(&flagFountain, "fountain", false, "write every generated key to --output, with no pattern, until --count keys or stopped (for seeding faucets)")
+	rootCmd.Flags().IntVar(&flagRotate, "rotate", 0, "with --fountain, start a new numbered --output file every this many keys")
+	rootCmd.Flags().IntVar(&flagCountPerAlt, "count-per-alt", 0, "find this many addresses for each --prefix alternative, e.g. one each of dead, beef and cafe for (dead|beef|cafe), instead of --count in all")
+	rootCmd.Flags().BoolVar(&flagVerbose, "verbose", false, "break the difficulty down into hex digits (16^n) and, for --case-sensitive, letter case (2^letters)")
 	rootCmd.Flags().BoolVar(&flagTUI, "tui", false, "launch interactive TUI (default when no pattern is given)")
-	rootCmd.Flags().StringVarP(&flagOutput, "output", "o", "", "save results to this file")
-	rootCmd.Flags().StringVar(&flagFormat, "format", "text", "output format: text or json")
+	rootCmd.Flags().StringVarP(&flagOutput, "output", "o", "", "save results to this file as they are found (a fifo works too)")
+	rootCmd.Flags().StringVar(&flagFormat, "format", "text", "output format: text, table (aligned, printed at the end), json or csv")
+	rootCmd.Flags().BoolVar(&flagFullKeys, "full-keys", false, "show whole private keys in --format table instead of their first digits")
+	rootCmd.Flags().BoolVar(&flagSort, "sort", false, "sort results by address before printing json/csv and saving (csv then waits for the search to end)")
+	rootCmd.Flags().StringVar(&flagRankBy, "rank-by", "", "order results by leading-zeros, zero-bytes, runs, palindrome or score, best first, once the search ends (json, csv and saved output; text adds a ranked list)")
+	rootCmd.Flags().BoolVar(&flagAppend, "append", false, "add to the --output file instead of overwriting it")
+	rootCmd.Flags().IntVar(&flagMaxRetain, "max-retain", 0, "keep only the last N results in memory, for searches with no end; --output still gets every one (0 = keep all)")
+	rootCmd.Flags().BoolVar(&flagSummaryJSON, "summary-json", false, "also print a JSON run summary to stderr in text format (always on for json and csv)")
+	rootCmd.Flags().StringVar(&flagOutputFormat, "output-format", "text", "format of the --output file: text, json or csv")
+	rootCmd.Flags().IntVar(&flagMinScore, "min-score", 0, "address must score at least this on leading zeros, repeated nibbles and palindromes (no ETA)")
+	rootCmd.Flags().Float64Var(&flagAbandonAt, "abandon-at", 0, "give up once a match was this likely by now, e.g. 0.99, and none has turned up: the pattern is probably not what you meant")
+	rootCmd.Flags().Int64Var(&flagMaxAttempts, "max-attempts", 0, "stop after about this many candidates (0 = no limit; may overshoot by up to --workers)")
+	rootCmd.Flags().DurationVar(&flagTimeout, "timeout", 0, "stop searching after this long, e.g. 30m or 1h (0 = no limit)")
+	rootCmd.Flags().StringVar(&flagServe, "serve", "", "serve live /stats and /results as JSON on this address, e.g. :8080")
+	rootCmd.Flags().BoolVar(&flagServeKeys, "serve-keys", false, "include private keys and mnemonic phrases in --serve /results (unsafe on shared networks)")
+	rootCmd.Flags().StringVar(&flagMetrics, "metrics", "", "serve Prometheus metrics at /metrics on this address, e.g. :9090")
+	rootCmd.Flags().StringVar(&flagAddrStyle, "address-style", "", "how to display and save addresses: checksum, lower, upper or bare (default: as matched)")
+	rootCmd.Flags().StringVar(&flagBatch, "batch", "", `run one search per pattern line in this file ("-" for stdin)`)
+	rootCmd.Flags().StringVar(&flagAny, "any", "", `search for all pattern lines in this file at once and stop when any one has --count matches ("-" for stdin)`)
+	rootCmd.Flags().BoolVar(&flagDumpConfig, "dump-config", false, "print the search configuration assembled from the flags as JSON to stderr before starting")
+	rootCmd.Flags().BoolVar(&flagPreview, "preview", false, "print where --prefix, --suffix and --contains sit in an address and exit")
+	rootCmd.Flags().IntVar(&flagSample, "sample", 0, "print N fake, keyless addresses that satisfy the pattern instead of searching")
+	rootCmd.Flags().BoolVar(&flagRejectWeak, "reject-weak", false, "discard matches whose private key is below 2^128, within 2^128 of the curve order, or a known weak key")
+	rootCmd.Flags().BoolVar(&flagAudit, "audit", false, "track and print the leading-nibble distribution as an RNG sanity check")
+	rootCmd.Flags().DurationVar(&flagProgressTick, "progress-interval", 3*time.Second, "how often the live progress line is refreshed")
+	rootCmd.Flags().BoolVar(&flagNoProgress, "no-progress", false, "hide the logo and live progress line (automatic when stdout is not a terminal)")
+	rootCmd.Flags().BoolVar(&flagWithPubKey, "with-pubkey", false, "include each result's public key in every output format")
+	rootCmd.Flags().BoolVar(&flagWithNamehash, "with-namehash", false, "include the ENS namehash of each result's reverse record (<address>.addr.reverse) in every output format")
+	rootCmd.Flags().BoolVar(&flagProve, "prove", false, `sign "vanity-eth:<address>" with each result's key (EIP-191) and include the signature, so anyone can check who found it with "vanity-eth verify --proof"`)
+	rootCmd.Flags().StringVar(&flagPubKeyFormat, "pubkey-format", generator.PublicKeyUncompressed, "encoding of --with-pubkey: uncompressed (04…, 65 bytes) or compressed (02…/03…, 33 bytes)")
+	rootCmd.Flags().BoolVar(&flagMaskKeys, "mask-keys", false, "show private keys (and phrases) masked in the terminal, e.g. 0x1a2b****...****9f0e; --output still gets them in full")
+	rootCmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "start searches expected to take over an hour without asking first")
+	rootCmd.Flags().BoolVarP(&flagQuiet, "quiet", "q", false, `print only the results: "address private_key" lines in text format, no banner, progress or summary`)
+	rootCmd.Flags().BoolVar(&flagMnemonic, "mnemonic", false, "derive each candidate from a BIP-39 phrase (importable into HD wallets; much slower)")
+	rootCmd.Flags().IntVar(&flagMnemonicLen, "mnemonic-words", generator.DefaultMnemonicWords, "BIP-39 phrase length for --mnemonic: 12, 15, 18, 21 or 24")
+	rootCmd.Flags().StringVar(&flagDerivation, "derivation-path", generator.DefaultDerivationPath, "BIP-32 path derived in --mnemonic mode, e.g. m/44'/60'/0'/0 for legacy Ledger")
+	rootCmd.Flags().StringVar(&flagBrainwallet, "brainwallet", "", "derive keys from this passphrase and a counting salt (UNSAFE: guessable passphrases get drained)")
+	rootCmd.Flags().BoolVar(&flagStdinKey, "stdin-key", false, "match hex private keys read from stdin, one per line, instead of generating keys; stops at end of input")
+	rootCmd.Flags().StringVar(&flagXPub, "xpub", "", "search the non-hardened children of this extended public key and report the winning index; no private key is ever generated")
+	rootCmd.Flags().BoolVar(&flagFast, "fast", false, "walk keys incrementally from a random base per worker (faster, less entropy per key)")
+	rootCmd.Flags().StringVar(&flagAccelerator, "accelerator", "cpu", "search backend; this build has "+strings.Join(generator.Accelerators(), ", "))
+	rootCmd.Flags().BoolVar(&flagPinCPU, "pin-cpu", false, "experimental: bind worker i to CPU i (Linux; elsewhere workers are only locked to OS threads)")
+	rootCmd.Flags().StringArrayVar(&flagResumeFrom, "resume-from-key", nil, "with --fast, continue a walk from this key as printed when a search ends, one per worker (repeatable)")
+	rootCmd.Flags().BoolVar(&flagPrintResume, "print-resume-keys", false, "with --fast, print the --resume-from-key flags that continue each walk to stderr when the search ends (as secret as the results)")
+}
+
+// autoWorkers picks the fastest worker count for cfg and reports the
+// measurements on stderr, keeping stdout clean for json and csv.
+func autoWorkers(cfg generator.Config) int {
+	fmt.Fprint(os.Stderr, "tuning workers: ")
+	best, rates := generator.TuneWorkers(cfg, 500*time.Millisecond)
+	parts := make([]string, len(rates))
+	for i, r := range rates {
+		parts[i] = fmt.Sprintf("%d → %s/s", r.Workers, formatBig(int64(r.Rate)))
+	}
+	fmt.Fprintf(os.Stderr, "%s; using %d\n", strings.Join(parts, ", "), best)
+	return best
+}
+
+// printBrainwalletWarning spells out on stderr why brainwallets get
+// emptied, whatever the output format.
+func printBrainwalletWarning() {
+	red.Fprintln(os.Stderr, "WARNING: brainwallet mode derives keys from a passphrase you can remember.")
+	red.Fprintln(os.Stderr, "  Attackers run the same derivation over leaked passwords, quotes, lyrics and")
+	red.Fprintln(os.Stderr, "  phrases of every kind, and sweep any funds the moment they land. The salt")
+	red.Fprintln(os.Stderr, "  adds little: it is a small counter anyone can try. The passphrase is also")
+	red.Fprintln(os.Stderr, "  left in your shell history. Use only a long, random passphrase, and never")
+	red.Fprintln(os.Stderr, "  for funds you cannot afford to lose.")
+	fmt.Fprintln(os.Stderr)
+}
+
+// printResumeKeys prints the --resume-from-key flags that continue walks,
+// the per-worker positions of a finished --fast search, on stderr when
+// --print-resume-keys asks for them, masked like results with --mask-keys
+// or --quiet. The keys are wiped either way.
+func printResumeKeys(walks []generator.PrivateKey) {
+	defer func() {
+		for _, key := range walks {
+			key.Zero()
+		}
+	}()
+	if !flagPrintResume {
+		return
+	}
+	var args []string
+	for _, key := range walks {
+		if key == nil {
+			continue
+		}
+		shown := key.String()
+		if flagMaskKeys || flagQuiet {
+			shown = key.Masked()
+		}
+		args = append(args, "--resume-from-key 0x"+shown)
+	}
+	if len(args) == 0 {
+		return
+	}
+	yellow.Fprintln(os.Stderr, "to carry on without repeating keys, run again with --fast and:")
+	fmt.Fprintf(os.Stderr, "  %s\n", strings.Join(args, " "))
+	yellow.Fprintln(os.Stderr, "keep these as safe as the results: every key of each walk, earlier ones and the matches included, can be recovered from them")
+}
+
+// disableColor turns off ANSI colors for both the CLI and the TUI.
+func disableColor() {
+	color.NoColor = true
+	tui.DisableColor()
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
-	noPattern := flagPrefix == "" && flagSuffix == "" && flagContains == "" && flagRegex == ""
+	if flagSuffixDec != "" {
+		if flagPattern.suffix != "" {
+			return fmt.Errorf("--suffix-decimal cannot be combined with --suffix")
+		}
+		hex, err := generator.DecimalSuffix(flagSuffixDec)
+		if err != nil {
+			return fmt.Errorf("--suffix-decimal: %v", err)
+		}
+		flagPattern.suffix = hex
+	}
+	if flagASCII != "" {
+		switch {
+		case flagPattern.contains != "" || flagRegexBody != "":
+			return fmt.Errorf("--ascii cannot be combined with --contains or --regex-body")
+		case flagPattern.caseSensitive:
+			return fmt.Errorf("--ascii cannot be combined with --case-sensitive: bytes have no case")
+		}
+		// --ascii reports the address bytes in Result.Match, which these
+		// use for what they matched.
+		for _, name := range []string{"prefix-file", "wordlist", "spells", "min-score", "any"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--ascii cannot be combined with --%s", name)
+			}
+		}
+		contains, body, err := generator.ASCIIPattern(flagASCII)
+		if err != nil {
+			return fmt.Errorf("--ascii: %v", err)
+		}
+		flagPattern.contains, flagRegexBody = contains, body
+	}
+	noPattern := flagPattern.empty() && flagRegex == "" && flagRegexBody == "" && flagMinScore == 0 && flagBatch == "" && flagAny == ""
+	if err := generator.ValidateChain(flagPattern.chain); err != nil {
+		return fmt.Errorf("--chain: %v", err)
+	}
+	if err := generator.ValidateKeyType(flagKeyType); err != nil {
+		return fmt.Errorf("--key-type: %v", err)
+	}
+	if flagPattern.chain != generator.ChainEthereum && (flagTUI || noPattern) {
+		return fmt.Errorf("--chain %s needs --prefix, --suffix, --contains or --regex (the TUI searches Ethereum addresses only)", flagPattern.chain)
+	}
+	if flagStdinKey && (flagTUI || noPattern) {
+		return fmt.Errorf("--stdin-key needs a pattern (the TUI generates its own keys)")
+	}
+	if flagProve && flagXPub != "" {
+		return fmt.Errorf("--prove needs private keys, which --xpub never has")
+	}
+	if flagProve && (flagTUI || noPattern) {
+		return fmt.Errorf("--prove needs a pattern (the TUI does not sign results)")
+	}
+	if flagXPub != "" && (flagTUI || noPattern) {
+		return fmt.Errorf("--xpub needs a pattern (the TUI generates its own keys)")
+	}
+	if flagMaxRetain > 0 && (flagTUI || noPattern) {
+		return fmt.Errorf("--max-retain needs a CLI search: the TUI saves its results only at the end, so it cannot drop any")
+	}
+	if flagFountain {
+		return runFountain(cmd)
+	}
+	if cmd.Flags().Changed("rotate") {
+		return fmt.Errorf("--rotate requires --fountain")
+	}
 	if flagTUI || noPattern {
 		return runTUI()
 	}
 	return runCLI(cmd)
 }
 
+// hexOnlyFlags are the flags that describe hex addresses or Ethereum
+// wallets and so are rejected for base58 chains; patternFlags.config checks
+// the pattern flags among them.
+var hexOnlyFlags = []string{
+	"regex-body", "suffix-decimal", "min-score", "batch", "any", "preview",
+	"address-style", "mnemonic", "safe", "blocklist-file", "rank-by",
+	"count-per-alt", "avoid-file", "with-namehash", "ascii", "verbose", "prove",
+}
+
 func runCLI(cmd *cobra.Command) error {
-	// Validate hex inputs.
-	for flag, val := range map[string]string{"prefix": flagPrefix, "suffix": flagSuffix, "contains": flagContains} {
-		if val != "" {
-			if err := generator.ValidateHexPattern(val); err != nil {
-				return fmt.Errorf("--%s: %v", flag, err)
+	base58 := flagPattern.chain != generator.ChainEthereum
+	if base58 {
+		for _, name := range hexOnlyFlags {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--%s cannot be combined with --chain %s", name, flagPattern.chain)
 			}
 		}
 	}
 
+	pattern, err := flagPattern.config(cmd.Flags().Changed)
+	if err != nil {
+		return err
+	}
+
 	if flagRegex != "" {
 		if _, err := regexp.Compile(flagRegex); err != nil {
 			return fmt.Errorf("invalid regex: %w", err)
 		}
 	}
+	if flagRegexBody != "" {
+		if _, err := regexp.Compile(flagRegexBody); err != nil {
+			return fmt.Errorf("invalid --regex-body: %w", err)
+		}
+	}
+
+	var patterns []generator.Pattern
+	if flagAny != "" {
+		if flagPattern.prefix != "" || flagPattern.suffix != "" || flagPattern.contains != "" || flagPattern.prefixFile != "" || flagBatch != "" {
+			return fmt.Errorf("--any cannot be combined with --prefix, --suffix, --contains, --prefix-file or --batch")
+		}
+		if flagAny == "-" && flagStdinKey {
+			return fmt.Errorf("--any - cannot be combined with --stdin-key: both read stdin")
+		}
+		specs, err := readBatch(flagAny)
+		if err != nil {
+			return fmt.Errorf("--any: %v", err)
+		}
+		for _, spec := range specs {
+			patterns = append(patterns, generator.Pattern{ID: spec.text, Prefix: spec.prefix, Suffix: spec.suffix, Contains: spec.contains})
+		}
+	}
 
-	if flagFormat != "text" && flagFormat != "json" {
-		return fmt.Errorf("--format must be text or json")
+	var blocklist []string
+	if flagSafe {
+		blocklist = generator.DefaultBlocklist
+	}
+	if flagBlocklist != "" {
+		extra, err := loadBlocklist(flagBlocklist)
+		if err != nil {
+			return fmt.Errorf("--blocklist-file: %v", err)
+		}
+		blocklist = append(slices.Clip(blocklist), extra...)
+	}
+
+	var avoid *generator.Bloom
+	if flagAvoidFile != "" {
+		if avoid, err = loadAvoidList(flagAvoidFile); err != nil {
+			return fmt.Errorf("--avoid-file: %v", err)
+		}
+	}
+
+	if flagMnemonic {
+		if _, err := generator.MnemonicEntropyBits(flagMnemonicLen); err != nil {
+			return fmt.Errorf("--mnemonic-words: %v", err)
+		}
+		if flagFast {
+			return fmt.Errorf("--fast cannot be combined with --mnemonic")
+		}
+		if _, err := generator.ParseDerivationPath(flagDerivation); err != nil {
+			return fmt.Errorf("--derivation-path: %v", err)
+		}
+	} else if cmd.Flags().Changed("derivation-path") {
+		return fmt.Errorf("--derivation-path requires --mnemonic")
+	}
+
+	var resumeFrom []generator.PrivateKey
+	if len(flagResumeFrom) > 0 {
+		if !flagFast {
+			return fmt.Errorf("--resume-from-key requires --fast")
+		}
+		for _, name := range []string{"auto-workers", "batch"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--resume-from-key cannot be combined with --%s", name)
+			}
+		}
+		if len(flagResumeFrom) > flagWorkers {
+			return fmt.Errorf("--resume-from-key: %d keys for %d workers; raise --workers to continue every walk", len(flagResumeFrom), flagWorkers)
+		}
+		for _, s := range flagResumeFrom {
+			key, err := generator.ParsePrivateKey(s)
+			if err != nil {
+				return fmt.Errorf("--resume-from-key: %v", err)
+			}
+			resumeFrom = append(resumeFrom, key)
+		}
+	}
+	if flagPrintResume && !flagFast {
+		return fmt.Errorf("--print-resume-keys requires --fast")
+	}
+
+	if flagBrainwallet != "" {
+		if flagMnemonic || flagFast {
+			return fmt.Errorf("--brainwallet cannot be combined with --mnemonic or --fast")
+		}
+		printBrainwalletWarning()
+	}
+
+	if flagStdinKey {
+		for _, name := range []string{"fast", "mnemonic", "brainwallet", "auto-workers", "batch"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--stdin-key cannot be combined with --%s", name)
+			}
+		}
+	}
+
+	var xpub *generator.XPub
+	if flagXPub != "" {
+		for _, name := range []string{"fast", "mnemonic", "brainwallet", "stdin-key", "reject-weak", "batch"} {
+			if cmd.Flags().Changed(name) {
+				return fmt.Errorf("--xpub cannot be combined with --%s", name)
+			}
+		}
+		if xpub, err = generator.ParseXPub(flagXPub); err != nil {
+			return fmt.Errorf("--xpub: %v", err)
+		}
+	}
+
+	if flagCount < 0 {
+		return fmt.Errorf("--count must not be negative")
+	}
+
+	var alternatives []string
+	if flagCountPerAlt < 0 {
+		return fmt.Errorf("--count-per-alt must not be negative")
+	}
+	if flagCountPerAlt > 0 {
+		switch {
+		case flagPattern.prefix == "":
+			return fmt.Errorf("--count-per-alt needs a --prefix to split into alternatives")
+		case cmd.Flags().Changed("count"):
+			return fmt.Errorf("--count-per-alt cannot be combined with --count: the count is per alternative")
+		case flagAny != "" || flagBatch != "" || flagPattern.prefixFile != "" || flagPattern.invert:
+			return fmt.Errorf("--count-per-alt cannot be combined with --any, --batch, --prefix-file or --invert")
+		}
+		alternatives = generator.PrefixAlternatives(flagPattern.prefix, flagPattern.caseSensitive)
+		flagCount = flagCountPerAlt * len(alternatives)
+	}
+
+	if flagMinScore < 0 {
+		return fmt.Errorf("--min-score must not be negative")
+	}
+
+	if cmd.Flags().Changed("abandon-at") && (flagAbandonAt <= 0 || flagAbandonAt >= 1) {
+		return fmt.Errorf("--abandon-at must be a probability between 0 and 1, e.g. 0.99")
+	}
+	if flagMaxAttempts < 0 {
+		return fmt.Errorf("--max-attempts must not be negative")
+	}
+	switch {
+	case flagMaxRetain < 0:
+		return fmt.Errorf("--max-retain must not be negative")
+	case flagMaxRetain > 0 && (flagAppend || flagSort || flagRankBy != "" || alternatives != nil || flagBatch != ""):
+		return fmt.Errorf("--max-retain cannot be combined with --append, --sort, --rank-by, --count-per-alt or --batch: they need every result at the end")
+	case flagMaxRetain > 0 && flagOutput == "" && flagFormat != "text" && flagFormat != "csv":
+		return fmt.Errorf("--max-retain needs --output, or --format text or csv, which print results as they are found: --format %s prints them only at the end, so dropped ones would be lost", flagFormat)
+	}
+
+	if flagProgressTick <= 0 {
+		return fmt.Errorf("--progress-interval must be positive")
+	}
+
+	if flagFormat != "text" && flagFormat != "table" && flagFormat != "json" && flagFormat != "csv" {
+		return fmt.Errorf("--format must be text, table, json or csv")
+	}
+	if flagFullKeys && flagFormat != "table" {
+		return fmt.Errorf("--full-keys only applies to --format table: the other formats print keys in full")
+	}
+	if flagFullKeys && flagMaskKeys {
+		return fmt.Errorf("--full-keys and --mask-keys cannot be combined")
+	}
+	if _, ok := rankMetrics[flagRankBy]; flagRankBy != "" && !ok {
+		return fmt.Errorf("--rank-by must be leading-zeros, zero-bytes, runs, palindrome or score")
+	}
+	if flagMaskKeys && flagFormat != "text" && flagFormat != "table" {
+		return fmt.Errorf("--mask-keys only applies to --format text and table: json and csv print keys in full")
+	}
+
+	if flagPubKeyFormat != generator.PublicKeyUncompressed && flagPubKeyFormat != generator.PublicKeyCompressed {
+		return fmt.Errorf("--pubkey-format must be uncompressed or compressed")
+	}
+	if cmd.Flags().Changed("pubkey-format") && !flagWithPubKey {
+		return fmt.Errorf("--pubkey-format requires --with-pubkey")
+	}
+
+	if !validAddressStyle(flagAddrStyle) {
+		return fmt.Errorf("--address-style must be checksum, lower, upper or bare")
+	}
+
+	if !validOutputFormat(flagOutputFormat) {
+		return fmt.Errorf("--output-format must be text, json or csv")
+	}
+
+	if flagAutoWorkers && cmd.Flags().Changed("workers") {
+		return fmt.Errorf("--auto-workers cannot be combined with --workers")
 	}
 
 	cfg := generator.Config{
-		Prefix:        flagPrefix,
-		Suffix:        flagSuffix,
-		Contains:      flagContains,
-		Regex:         flagRegex,
-		Workers:       flagWorkers,
-		Count:         flagCount,
-		CaseSensitive: flagCase,
-	}
-
-	magenta.Print(logoASCII)
-	bold.Printf("vanity-eth  •  workers: %d  •  target: %d address(es)\n", flagWorkers, flagCount)
-	printPattern(flagPrefix, flagSuffix, flagContains, flagRegex, flagCase)
-	fmt.Println()
+		Prefix:         pattern.Prefix,
+		Suffix:         pattern.Suffix,
+		Contains:       pattern.Contains,
+		Regex:          flagRegex,
+		RegexBody:      flagRegexBody,
+		Workers:        flagWorkers,
+		Count:          flagCount,
+		CountPerAlt:    flagCountPerAlt,
+		CaseSensitive:  pattern.CaseSensitive,
+		Near:           pattern.Near,
+		MaxDistance:    pattern.MaxDistance,
+		Mod:            pattern.Mod,
+		Remainder:      pattern.Remainder,
+		Prefixes:       pattern.Prefixes,
+		Patterns:       patterns,
+		Words:          pattern.Words,
+		Spellings:      pattern.Spellings,
+		MinScore:       flagMinScore,
+		Exclude:        pattern.Exclude,
+		Blocklist:      blocklist,
+		Avoid:          avoid,
+		Brainwallet:    flagBrainwallet,
+		MaxAttempts:    flagMaxAttempts,
+		Invert:         pattern.Invert,
+		Audit:          flagAudit,
+		RejectWeak:     flagRejectWeak,
+		Fast:           flagFast,
+		ResumeFrom:     resumeFrom,
+		Mnemonic:       flagMnemonic,
+		MnemonicWords:  flagMnemonicLen,
+		DerivationPath: flagDerivation,
+		Chain:          pattern.Chain,
+		KeyType:        flagKeyType,
+		XPub:           xpub,
+	}
+	if flagWithPubKey {
+		cfg.PublicKey = flagPubKeyFormat
+	}
+	if flagASCII != "" {
+		cfg.ContainsOffsets = generator.ASCIIOffsets(flagASCII)
+	}
+	if flagPinCPU {
+		cfg.PinCPU = true
+		if !generator.CPUPinning {
+			yellow.Fprintln(os.Stderr, "--pin-cpu: CPU affinity is not supported on this platform; workers are only locked to OS threads")
+		}
+	}
+	if flagStdinKey {
+		cfg.Keys = os.Stdin
+	}
+	if flagAccelerator != "" && flagAccelerator != generator.CPU.Name() {
+		acc, err := generator.LookupAccelerator(flagAccelerator)
+		if err != nil {
+			return fmt.Errorf("--accelerator: %v", err)
+		}
+		cfg.Accelerator = acc
+	}
+	// Contradictory criteria would search forever; inverted, they match
+	// everything instead.
+	if !cfg.Invert {
+		if err := generator.CheckCriteria(cfg); err != nil {
+			return err
+		}
+	}
+
+	// --abandon-at turns the odds into a budget of attempts for the first
+	// match.
+	var abandonAfter int64
+	if flagAbandonAt > 0 {
+		d := generator.Difficulty(cfg)
+		switch {
+		case flagBatch != "":
+			return fmt.Errorf("--abandon-at cannot be combined with --batch")
+		case d == nil || cfg.Invert:
+			return fmt.Errorf("--abandon-at needs a pattern whose odds can be estimated, not --regex, --min-score or --invert")
+		}
+		abandonAfter = math.MaxInt64
+		if n := generator.AttemptsForConfidence(d, flagAbandonAt); n.IsInt64() {
+			abandonAfter = n.Int64()
+		}
+	}
+
+	if flagPreview {
+		return printPreview(cfg)
+	}
+	if flagSample > 0 {
+		return printSamples(cfg, flagSample)
+	}
+	if flagAutoWorkers {
+		cfg.Workers = autoWorkers(cfg)
+	}
+	if flagDumpConfig {
+		if err := dumpConfig(os.Stderr, cfg); err != nil {
+			return fmt.Errorf("--dump-config: %v", err)
+		}
+	}
+	if flagBatch != "" {
+		return runBatch(cmd, cfg)
+	}
+	// Results go to --output as they arrive unless something reorders
+	// them at the end or they join an existing file.
+	stream := flagOutput != "" && !flagAppend && !flagSort && flagRankBy == "" && alternatives == nil
+	if flagOutput != "" && !stream && isFifo(flagOutput) {
+		return fmt.Errorf("--output: %s is a fifo, written as results arrive, which rules out --append, --sort, --rank-by and --count-per-alt", flagOutput)
+	}
+
+	// CSV goes straight to spreadsheets and pipes, so it gets no decoration.
+	decorate := flagFormat != "csv" && !flagQuiet
+	showProgress = decorate && !flagNoProgress && term.IsTerminal(os.Stdout.Fd())
+
+	// Ask before an unbounded search that looks set to run for hours, when
+	// someone is at the terminal to answer. The odds below and the prompt
+	// share one quick benchmark.
+	confirm := !flagYes && !flagQuiet && !flagStdinKey && flagTimeout == 0 && flagMaxAttempts == 0 &&
+		!cfg.Invert && generator.Difficulty(cfg) != nil &&
+		term.IsTerminal(os.Stdout.Fd()) && term.IsTerminal(os.Stdin.Fd())
+	var benchRate float64
+	if confirm || decorate && flagTimeout > 0 && !flagStdinKey {
+		benchRate = generator.MeasureRate(cfg, 500*time.Millisecond)
+	}
+
+	if decorate {
+		if showProgress {
+			magenta.Print(logoASCII)
+		}
+		bold.Printf("vanity-eth  •  workers: %d  •  target: %s address(es)\n", cfg.Workers, countLabel(flagCount))
+		printPattern(cfg)
+		if flagFast {
+			yellow.Println("fast mode: keys are consecutive from one random base per worker")
+		}
+		if flagMnemonic {
+			yellow.Printf("mnemonic mode: %d-word BIP-39 phrases, path %s\n", flagMnemonicLen, flagDerivation)
+		}
+		if flagBrainwallet != "" {
+			yellow.Println("brainwallet mode: one scrypt run per salt, so only short patterns are practical")
+		}
+		if xpub != nil {
+			yellow.Println("xpub mode: searching child public keys; results carry an index, not a private key")
+		}
+		if flagASCII != "" {
+			yellow.Printf("ascii mode: %q is %s, counted only on a byte boundary\n", flagASCII, flagPattern.contains)
+		}
+		if flagStdinKey {
+			yellow.Println("stdin mode: matching private keys read from stdin; rates count keys consumed")
+		}
+		// The odds assume keys generated here, at the measured rate.
+		if flagTimeout > 0 && !flagStdinKey {
+			printTimeoutOdds(cfg, flagTimeout, benchRate)
+		}
+		if abandonAfter > 0 {
+			cyan.Printf("giving up after %s attempts without a match (%s likely to have one by then)\n",
+				formatBig(abandonAfter), fmtPercent(flagAbandonAt))
+		}
+		fmt.Println()
+	}
+	if confirm && !confirmLongSearch(os.Stdin, os.Stderr, computeETA(cfg, 0, flagCount, benchRate)) {
+		fmt.Fprintln(os.Stderr, "not started (pass --yes to skip this question)")
+		cmd.SilenceErrors, cmd.SilenceUsage = true, true
+		return errDeclined
+	}
+
+	// Opening a fifo waits for its reader, so do it while Ctrl+C still
+	// simply exits.
+	var saver *outputStream
+	if stream {
+		if decorate && isFifo(flagOutput) {
+			cyan.Printf("waiting for a reader on %s\n", flagOutput)
+		}
+		var err error
+		if saver, err = openOutputStream(flagOutput, flagOutputFormat, csvColumns()); err != nil {
+			return fmt.Errorf("--output: %w", err)
+		}
+	}
 
 	ctx, cancel := signal.NotifyContext(cmd.Context(), syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
+	if flagTimeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, flagTimeout)
+		defer cancelTimeout()
+	}
 
 	stats := &generator.Stats{}
-	resultCh := make(chan generator.Result, flagCount)
+	resultCh := make(chan generator.Result, max(flagCount, 1))
+
+	// Wipe the keys once they are printed and saved. This is registered
+	// first so it runs last, after the status servers stop reading results.
+	var collected []generator.Result
+	defer func() { generator.ZeroKeys(collected) }()
+	// kept holds what --max-retain lets stay in memory, and collected
+	// takes it over once the search ends; found counts every result.
+	kept := generator.Recent{Max: flagMaxRetain}
+	found := 0
+
+	start := time.Now()
+	// smoothed steadies the rate the progress line's ETA is based on.
+	var smoothed generator.RateSmoother
+	var server *statusServer
+	if flagServe != "" {
+		var err error
+		if server, err = startStatusServer(ctx, flagServe, cfg, stats, start, flagServeKeys, flagMaxRetain); err != nil {
+			return fmt.Errorf("--serve: %w", err)
+		}
+		defer server.stop()
+		if decorate {
+			cyan.Printf("serving stats on http://%s/stats\n\n", flagServe)
+		}
+	}
+	if flagMetrics != "" {
+		stopMetrics, err := startMetricsServer(ctx, flagMetrics, stats, start)
+		if err != nil {
+			return fmt.Errorf("--metrics: %w", err)
+		}
+		defer stopMetrics()
+		if decorate {
+			cyan.Printf("serving metrics on http://%s/metrics\n\n", flagMetrics)
+		}
+	}
+
+	// Run reports progress about every 100,000 attempts, which is
+	// close enough for a budget meant to catch hopeless patterns.
+	var abandoned atomic.Bool
+	if abandonAfter > 0 {
+		cfg.OnProgress = func(total, found int64) {
+			if found == 0 && total >= abandonAfter {
+				abandoned.Store(true)
+				cancel()
+			}
+		}
+	}
 
 	go generator.Run(ctx, cfg, resultCh, stats)
 
-	ticker := time.NewTicker(3 * time.Second)
+	ticker := time.NewTicker(flagProgressTick)
 	defer ticker.Stop()
-	start := time.Now()
+	frame := 0
 
-	var collected []generator.Result
+	dump := make(chan os.Signal, 1)
+	notifyStatsDump(dump)
+	defer ignoreStatsDump()
+
+	var csvOut *csvStream
+	if flagFormat == "csv" && !flagSort && flagRankBy == "" {
+		csvOut = newCSVStream(os.Stdout, csvColumns()...)
+	}
+	out := newConsole(os.Stdout)
+
+	// emit records a result and streams it in formats that print as they go.
+	// Run only de-duplicates when --count is above 1, so a repeat is
+	// dropped here too, whatever the count. seen outlives what kept drops,
+	// at 20 bytes an address, so a repeat is caught however long ago the
+	// first one was evicted.
+	var seen generator.Seen
+	emit := func(r generator.Result) {
+		if !seen.Add(r.Checksum) {
+			stats.Duplicates.Add(1)
+			r.PrivateKey.Zero()
+			return
+		}
+		r.Address = styleAddress(r.Address, flagAddrStyle)
+		withReverseNode(&r)
+		withASCII(&r)
+		withProof(&r)
+		found++
+		kept.Add(r)
+		if saver != nil {
+			saver.write(r)
+		}
+		if server != nil {
+			server.add(r)
+		}
+		switch flagFormat {
+		case "text":
+			if flagQuiet && flagRankBy != "" {
+				break // printed in rank order once the search ends
+			}
+			out.print(func(w io.Writer) {
+				if flagQuiet {
+					printQuietResult(w, r)
+					return
+				}
+				printResult(w, found, r, stats.Total.Load(), time.Since(start), cfg)
+			})
+		case "csv":
+			if csvOut != nil {
+				_ = csvOut.write(r)
+			}
+		}
+	}
 
 loop:
 	for {
@@ -151,120 +893,396 @@ loop:
 			if !ok {
 				break loop
 			}
-			collected = append(collected, r)
-			if flagFormat == "text" {
-				printResult(len(collected), r, stats.Total.Load(), time.Since(start))
-			}
+			emit(r)
+		case <-dump:
+			out.print(func(io.Writer) { printStatsDump(os.Stderr, stats, flagCount, time.Since(start), cfg) })
 		case <-ticker.C:
-			if flagFormat == "text" {
-				printProgress(stats.Total.Load(), int(stats.Found.Load()), flagCount, time.Since(start), cfg)
+			if (flagFormat == "text" || flagFormat == "table") && showProgress {
+				frame++
+				total, elapsed := stats.Total.Load(), time.Since(start)
+				out.showProgress(progressLine(frame, total, int(stats.Found.Load()), flagCount, elapsed, smoothed.Update(total, elapsed), cfg))
 			}
 		case <-ctx.Done():
 			ticker.Stop()
 			for r := range resultCh {
-				collected = append(collected, r)
-				if flagFormat == "text" {
-					printResult(len(collected), r, stats.Total.Load(), time.Since(start))
-				}
+				emit(r)
 			}
 			break loop
 		}
 	}
 
+	out.finish()
+	collected = kept.Results()
+	if flagSort {
+		sortResults(collected)
+	}
+	if flagRankBy != "" {
+		rankResults(collected, rankMetrics[flagRankBy])
+	}
+	if alternatives != nil {
+		groupByAlternative(collected, alternatives)
+	}
+
 	elapsed := time.Since(start)
 	total := stats.Total.Load()
 	rate := float64(total) / elapsed.Seconds()
 
-	if flagFormat == "json" {
-		enc := json.NewEncoder(os.Stdout)
-		enc.SetIndent("", "  ")
-		type jsonResult struct {
-			Address    string `json:"address"`
-			PrivateKey string `json:"privateKey"`
+	switch flagFormat {
+	case "json":
+		_ = ledger.WriteJSON(os.Stdout, collected)
+	case "csv":
+		if csvOut == nil {
+			_ = writeCSV(os.Stdout, collected)
 		}
-		out := make([]jsonResult, len(collected))
-		for i, r := range collected {
-			out[i] = jsonResult{Address: r.Address, PrivateKey: "0x" + r.PrivateKey}
+	case "text", "table":
+		if flagFormat == "table" {
+			if !flagQuiet {
+				fmt.Println()
+			}
+			printTable(os.Stdout, collected, flagFullKeys)
 		}
-		_ = enc.Encode(out)
-	} else {
-		fmt.Printf("\n%s  found %d/%d  •  %s tried  •  %.0f addr/s  •  %s\n",
+		if flagQuiet {
+			if flagRankBy != "" && flagFormat == "text" {
+				for _, r := range collected {
+					printQuietResult(os.Stdout, r)
+				}
+			}
+			break
+		}
+		fmt.Printf("\n%s  found %d/%s  •  %s tried  •  %.0f addr/s  •  %s\n",
 			bold.Sprint("done"),
-			len(collected), flagCount,
+			found, countLabel(flagCount),
 			formatBig(total),
 			rate,
 			elapsed.Round(time.Millisecond),
 		)
+		if flagRankBy != "" {
+			printRanking(os.Stdout, collected, flagRankBy, rankMetrics[flagRankBy])
+		}
+		if alternatives != nil {
+			printAlternatives(os.Stdout, collected, alternatives, flagCountPerAlt)
+		}
+		if flagAudit {
+			printNibbleHistogram(stats)
+		}
+	}
+
+	summary := runSummary{
+		Attempts:    total,
+		Found:       found,
+		Requested:   flagCount,
+		Rate:        rate,
+		ElapsedMS:   elapsed.Milliseconds(),
+		Interrupted: flagCount == 0 || found < flagCount,
+	}
+	switch {
+	case flagFormat == "json" && !flagQuiet:
+		_ = writeSummary(os.Stdout, summary)
+	case flagFormat == "csv" && !flagQuiet || flagSummaryJSON:
+		_ = writeSummary(os.Stderr, summary)
 	}
 
 	if flagOutput != "" {
-		if err := saveToFile(flagOutput, collected); err != nil {
-			fmt.Fprintf(os.Stderr, "error saving file: %v\n", err)
+		var err error
+		if saver != nil {
+			err = saver.close()
 		} else {
+			err = saveToFile(flagOutput, flagOutputFormat, collected, flagAppend)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error saving file: %v\n", err)
+		} else if decorate {
 			green.Printf("saved to %s\n", flagOutput)
 		}
 	}
 
-	return nil
-}
+	if n := kept.Dropped(); n > 0 && !flagQuiet {
+		where := "they were only printed"
+		if flagOutput != "" {
+			where = "--output has every one"
+		}
+		yellow.Fprintf(os.Stderr, "kept the last %d of %d results in memory (--max-retain); %s\n", kept.Len(), found, where)
+	}
+	if n := stats.Panics.Load(); n > 0 {
+		yellow.Fprintf(os.Stderr, "recovered from %d worker panic(s); last: %s\n", n, stats.LastPanic())
+	}
+	if n := stats.Weak.Load(); n > 0 {
+		yellow.Fprintf(os.Stderr, "discarded %d match(es) with a weak private key; check your entropy source\n", n)
+	}
+	if n := stats.Duplicates.Load(); n > 0 {
+		yellow.Fprintf(os.Stderr, "discarded %d repeated address(es); check your entropy source\n", n)
+	}
+	if flagFast {
+		printResumeKeys(stats.Walks())
+	}
 
-func saveToFile(path string, results []generator.Result) error {
-	f, err := os.Create(path)
-	if err != nil {
+	// Report an aborted search only after anything found so far is saved.
+	if err := stats.Err(); err != nil {
 		return err
 	}
-	defer f.Close()
-	for i, r := range results {
-		fmt.Fprintf(f, "#%d\n", i+1)
-		fmt.Fprintf(f, "Address:     %s\n", r.Address)
-		fmt.Fprintf(f, "Private Key: 0x%s\n\n", r.PrivateKey)
+	if abandoned.Load() && found == 0 {
+		yellow.Fprintf(os.Stderr, "no match after %s attempts, when one was %s likely by now: the pattern is probably not what you meant\n",
+			formatBig(total), fmtPercent(flagAbandonAt))
+		cmd.SilenceErrors, cmd.SilenceUsage = true, true
+		return errPartialResult
+	}
+	if flagMaxAttempts > 0 && found < flagCount && total >= flagMaxAttempts {
+		yellow.Fprintf(os.Stderr, "stopped at --max-attempts %d: found %d of %d\n", flagMaxAttempts, found, flagCount)
+		cmd.SilenceErrors, cmd.SilenceUsage = true, true
+		return errPartialResult
+	}
+	if flagStdinKey && ctx.Err() == nil && found < flagCount {
+		yellow.Fprintf(os.Stderr, "stopped at end of stdin: found %d of %d\n", found, flagCount)
+		cmd.SilenceErrors, cmd.SilenceUsage = true, true
+		return errPartialResult
+	}
+	if flagXPub != "" && ctx.Err() == nil && found < flagCount {
+		yellow.Fprintf(os.Stderr, "stopped after the last non-hardened index: found %d of %d\n", found, flagCount)
+		cmd.SilenceErrors, cmd.SilenceUsage = true, true
+		return errPartialResult
 	}
 	return nil
 }
 
-func printPattern(prefix, suffix, contains, regex string, caseSensitive bool) {
+func printPattern(cfg generator.Config) {
+	yellow.Printf("pattern: %s\n", strings.Join(patternParts(cfg, flagPattern.spells), "  "))
+
+	if d := generator.Difficulty(cfg); d != nil {
+		cyan.Printf("~1 in %s addresses match\n", humanize.BigInt(d))
+		if flagVerbose {
+			printDifficultyBreakdown(os.Stdout, cfg)
+		}
+		// A lone prefix would only be compared with itself.
+		if !(cfg.Prefix != "" && len(patternParts(cfg, flagPattern.spells)) == 1) {
+			cyan.Println(difficultyComparison(d))
+		}
+		if warning := generator.DifficultyWarning(d); warning != "" {
+			red.Printf("WARNING: %s\n", warning)
+		}
+		cyan.Printf("ETA will appear once the search starts\n")
+	} else if cfg.MinScore > 0 {
+		cyan.Printf("difficulty unknown: score thresholds have no estimate\n")
+	}
+}
+
+// printDifficultyBreakdown shows, for --verbose, how the odds of each hex
+// pattern come from its digits and, when matching is case-sensitive, from
+// the case of its letters. A pattern with alternatives counts its shortest,
+// and the total leaves out overlap and other criteria, so it can differ
+// from the estimate above.
+func printDifficultyBreakdown(w io.Writer, cfg generator.Config) {
+	var total generator.Factors
+	var lines []string
+	for _, p := range []struct{ name, pattern string }{
+		{"prefix", cfg.Prefix}, {"suffix", cfg.Suffix}, {"contains", cfg.Contains},
+	} {
+		if p.pattern == "" {
+			continue
+		}
+		f := generator.PatternFactors(p.pattern)
+		total.Nibbles += f.Nibbles
+		total.Letters += f.Letters
+		line := fmt.Sprintf("  %-9s %-14q %s", p.name, p.pattern, factorProduct(f, cfg.CaseSensitive))
+		if strings.Contains(p.pattern, "|") {
+			line += "  (shortest alternative)"
+		}
+		lines = append(lines, line)
+	}
+	if total.Nibbles == 0 {
+		return
+	}
+	fmt.Fprintln(w, "difficulty breakdown:")
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	if len(lines) > 1 {
+		fmt.Fprintf(w, "  %-24s %s\n", "together", factorProduct(total, cfg.CaseSensitive))
+	}
+	if !cfg.CaseSensitive && total.Letters > 0 {
+		fmt.Fprintf(w, "  --case-sensitive would make it %s times harder: 2^%d, a factor of 2 per letter\n",
+			humanize.BigInt(total.Case()), total.Letters)
+	}
+}
+
+// factorProduct writes out f's odds, e.g. "16^4 × 2^3 = 65.5K × 8 = 524.3K"
+// when the case of letters counts and "16^4 = 65.5K" when it does not.
+func factorProduct(f generator.Factors, caseSensitive bool) string {
+	if !caseSensitive {
+		return fmt.Sprintf("16^%d = %s", f.Nibbles, humanize.BigInt(f.Hex()))
+	}
+	return fmt.Sprintf("16^%d × 2^%d = %s × %s = %s", f.Nibbles, f.Letters,
+		humanize.BigInt(f.Hex()), humanize.BigInt(f.Case()), humanize.BigInt(new(big.Int).Mul(f.Hex(), f.Case())))
+}
+
+// difficultyComparison relates difficulty d to the hex prefix whose length
+// comes closest to it, which is easier to picture than a bare count: each
+// character makes a prefix 16 times harder.
+func difficultyComparison(d *big.Int) string {
+	f, _ := new(big.Float).SetInt(d).Float64()
+	n := max(1, int(math.Round(math.Log(f)/math.Log(16))))
+	ref := new(big.Int).Lsh(big.NewInt(1), uint(4*n))
+	about := "about "
+	if ref.Cmp(d) == 0 {
+		about = ""
+	}
+	return fmt.Sprintf("%sas hard as finding a specific %d-character prefix (~%s attempts)", about, n, humanize.BigInt(ref))
+}
+
+// patternParts describes each criterion in cfg, e.g. prefix="dead". spells
+// is the word cfg.Spellings were generated from.
+func patternParts(cfg generator.Config, spells string) []string {
 	var parts []string
-	if prefix != "" {
-		parts = append(parts, fmt.Sprintf("prefix=%q", prefix))
+	if cfg.Chain != "" && cfg.Chain != generator.ChainEthereum {
+		parts = append(parts, "chain="+cfg.Chain)
 	}
-	if suffix != "" {
-		parts = append(parts, fmt.Sprintf("suffix=%q", suffix))
+	if cfg.Prefix != "" {
+		parts = append(parts, fmt.Sprintf("prefix=%q", cfg.Prefix))
 	}
-	if contains != "" {
-		parts = append(parts, fmt.Sprintf("contains=%q", contains))
+	if cfg.Suffix != "" {
+		parts = append(parts, fmt.Sprintf("suffix=%q", cfg.Suffix))
 	}
-	if regex != "" {
-		parts = append(parts, fmt.Sprintf("regex=%q", regex))
+	if cfg.Contains != "" {
+		parts = append(parts, fmt.Sprintf("contains=%q", cfg.Contains))
 	}
-	yellow.Printf("pattern: %s\n", strings.Join(parts, "  "))
+	if cfg.Regex != "" {
+		parts = append(parts, fmt.Sprintf("regex=%q", cfg.Regex))
+	}
+	if cfg.RegexBody != "" {
+		parts = append(parts, fmt.Sprintf("regex-body=%q", cfg.RegexBody))
+	}
+	if cfg.Near != "" {
+		parts = append(parts, fmt.Sprintf("near=%s±%d", cfg.Near, cfg.MaxDistance))
+	}
+	if cfg.Mod != nil {
+		parts = append(parts, fmt.Sprintf("mod %s=%s", cfg.Mod, cfg.Remainder))
+	}
+	if cfg.CountPerAlt > 0 {
+		parts = append(parts, fmt.Sprintf("%d per prefix alternative", cfg.CountPerAlt))
+	}
+	if len(cfg.Prefixes) > 0 {
+		parts = append(parts, fmt.Sprintf("prefixes=%d", len(cfg.Prefixes)))
+	}
+	if len(cfg.Patterns) > 0 {
+		parts = append(parts, fmt.Sprintf("any of %d patterns", len(cfg.Patterns)))
+	}
+	if len(cfg.Words) > 0 {
+		parts = append(parts, fmt.Sprintf("words=%d", len(cfg.Words)))
+	}
+	if len(cfg.Spellings) > 0 {
+		parts = append(parts, fmt.Sprintf("spells=%q (%d spellings)", spells, len(cfg.Spellings)))
+	}
+	if cfg.MinScore > 0 {
+		parts = append(parts, fmt.Sprintf("min-score=%d", cfg.MinScore))
+	}
+	for _, ex := range cfg.Exclude {
+		parts = append(parts, fmt.Sprintf("exclude=%q", ex))
+	}
+	if len(cfg.Blocklist) > 0 {
+		parts = append(parts, fmt.Sprintf("blocklist=%d words", len(cfg.Blocklist)))
+	}
+	if cfg.Invert {
+		parts = append(parts, "(inverted)")
+	}
+	return parts
+}
 
-	if d := generator.HexDifficulty(prefix, suffix, contains, caseSensitive); d != nil {
-		cyan.Printf("~1 in %s addresses match\n", d.String())
-		cyan.Printf("ETA will appear once the search starts\n")
+// printSamples prints n synthesized addresses matching cfg. They are made up
+// to show the pattern satisfied and have no private key.
+func printSamples(cfg generator.Config, n int) error {
+	samples := make([]string, n)
+	for i := range samples {
+		addr, err := generator.SynthesizeMatch(cfg)
+		if err != nil {
+			return fmt.Errorf("--sample: %v", err)
+		}
+		samples[i] = addr
 	}
+	yellow.Printf("%d sample match(es) — FAKE addresses with no private key, for pattern checking only:\n", n)
+	for _, addr := range samples {
+		fmt.Print("  ")
+		highlightAddress(os.Stdout, addr, cfg.Prefix, cfg.Suffix)
+		fmt.Println()
+	}
+	return nil
 }
 
-func printProgress(total int64, found, count int, elapsed time.Duration, cfg generator.Config) {
+// printPreview prints the address skeleton for cfg's prefix, suffix and
+// contains patterns, with ? for the free nibbles.
+func printPreview(cfg generator.Config) error {
+	prefixLen, before, containsLen, after, suffixLen := generator.PreviewLayout(cfg.Prefix, cfg.Suffix, cfg.Contains)
+	if prefixLen+containsLen+suffixLen == 0 {
+		return fmt.Errorf("--preview shows --prefix, --suffix and --contains patterns; none given")
+	}
+	fmt.Print("0x")
+	if prefixLen > 0 {
+		green.Print(cfg.Prefix)
+	}
+	fmt.Print(strings.Repeat("?", before))
+	if containsLen > 0 {
+		cyan.Print(cfg.Contains)
+	}
+	fmt.Print(strings.Repeat("?", after))
+	if suffixLen > 0 {
+		green.Print(cfg.Suffix)
+	}
+	fmt.Println()
+	if containsLen == 0 && cfg.Contains != "" {
+		yellow.Println("contains pattern does not fit between prefix and suffix")
+	}
+	return nil
+}
+
+// spinnerFrames animate the progress line; one frame per refresh shows the
+// search is alive even when the numbers barely move.
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// progressLine renders the live progress line for frame. The ETA is based on
+// etaRate, a smoothed rate, rather than the raw total/elapsed.
+func progressLine(frame int, total int64, found, count int, elapsed time.Duration, etaRate float64, cfg generator.Config) string {
 	rate := float64(total) / elapsed.Seconds()
-	eta := computeETA(cfg, found, count, rate)
+	eta := computeETA(cfg, found, count, etaRate)
 	etaStr := ""
-	if eta > 0 {
+	switch {
+	case eta <= 0:
+	case count == 0:
+		etaStr = "  •  ~" + fmtDuration(eta) + " per match"
+	default:
 		etaStr = "  •  ETA " + fmtDuration(eta)
 	}
-	fmt.Printf("\r\033[K%s tried  •  %d/%d found  •  %.0f addr/s  •  %s%s",
-		formatBig(total), found, count, rate, elapsed.Round(time.Second), etaStr)
+	return fmt.Sprintf("%s %s tried  •  %d/%s found  •  %.0f addr/s  •  %s%s",
+		cyan.Sprint(string(spinnerFrames[frame%len(spinnerFrames)])), formatBig(total), found, countLabel(count), rate, elapsed.Round(time.Second), etaStr)
+}
+
+// printStatsDump writes a one-off snapshot of a running search to w.
+func printStatsDump(w io.Writer, stats *generator.Stats, count int, elapsed time.Duration, cfg generator.Config) {
+	total := stats.Total.Load()
+	found := int(stats.Found.Load())
+	rate := float64(total) / elapsed.Seconds()
+	etaStr := "—"
+	if eta := computeETA(cfg, found, count, rate); eta > 0 {
+		etaStr = fmtDuration(eta)
+	}
+	fmt.Fprintf(w, "stats: %d tried  •  %d/%s found  •  %.0f addr/s  •  %s elapsed  •  ETA %s  •  %d key failures  •  %d panics\n",
+		total, found, countLabel(count), rate, elapsed.Round(time.Second), etaStr, stats.KeyFailures.Load(), stats.Panics.Load())
 }
 
-// computeETA estimates remaining time using the current live rate and difficulty.
+// computeETA estimates remaining time using the current live rate and
+// difficulty. With count 0 (no limit) it is the expected time per match.
 func computeETA(cfg generator.Config, found, count int, ratePerSec float64) time.Duration {
 	if ratePerSec <= 0 {
 		return 0
 	}
-	d := generator.HexDifficulty(cfg.Prefix, cfg.Suffix, cfg.Contains, cfg.CaseSensitive)
+	if cfg.Invert {
+		return 0 // almost every address matches; an ETA is meaningless
+	}
+	d := generator.Difficulty(cfg)
 	if d == nil {
 		return 0 // regex patterns: can't estimate
 	}
 	remaining := count - found
+	if count == 0 {
+		remaining = 1 // no limit: report the expected time per match
+	}
 	if remaining <= 0 {
 		return 0
 	}
@@ -275,6 +1293,44 @@ func computeETA(cfg generator.Config, found, count int, ratePerSec float64) time
 	return time.Duration(secs * float64(time.Second))
 }
 
+// printTimeoutOdds prints the chance of finding the requested addresses
+// before the timeout expires at rate, as measured locally.
+func printTimeoutOdds(cfg generator.Config, timeout time.Duration, rate float64) {
+	d := generator.Difficulty(cfg)
+	if d == nil {
+		return // regex patterns: can't estimate
+	}
+	cyan.Printf("~%s chance of finding 1 within %s (at ~%.0f addr/s)\n",
+		fmtPercent(generator.ProbabilityWithin(d, 1, rate, timeout)), timeout, rate)
+	if cfg.Count > 1 {
+		cyan.Printf("~%s chance of finding all %d within %s\n",
+			fmtPercent(generator.ProbabilityWithin(d, cfg.Count, rate, timeout)), cfg.Count, timeout)
+	}
+	if cfg.Count == 0 {
+		df, _ := new(big.Float).SetInt(d).Float64()
+		cyan.Printf("~%.1f matches expected within %s\n", rate*timeout.Seconds()/df, timeout)
+	}
+}
+
+// countLabel renders a --count for display, where 0 means no limit.
+func countLabel(count int) string {
+	if count == 0 {
+		return "∞"
+	}
+	return strconv.Itoa(count)
+}
+
+func fmtPercent(p float64) string {
+	switch {
+	case p < 0.001:
+		return "<0.1%"
+	case p > 0.999:
+		return ">99.9%"
+	default:
+		return fmt.Sprintf("%.1f%%", p*100)
+	}
+}
+
 func fmtDuration(d time.Duration) string {
 	d = d.Round(time.Second)
 	h := int(d.Hours())
@@ -291,32 +1347,139 @@ func fmtDuration(d time.Duration) string {
 	return fmt.Sprintf("%02d:%02d", m, s)
 }
 
-func printResult(n int, r generator.Result, total int64, elapsed time.Duration) {
+// printQuietResult prints r as "address private_key" on one line, the only
+// output of a --quiet search in text format, so scripts can cut it apart.
+// In xpub mode the child index takes the key's place; --with-pubkey adds
+// the public key as a third field.
+func printQuietResult(w io.Writer, r generator.Result) {
+	if r.XPub != "" {
+		fmt.Fprintf(w, "%s %d", r.Address, r.ChildIndex)
+	} else {
+		fmt.Fprintf(w, "%s 0x%s", r.Address, shownKey(r.PrivateKey))
+	}
+	if r.PublicKey != "" {
+		fmt.Fprintf(w, " 0x%s", r.PublicKey)
+	}
+	if r.ReverseNode != "" {
+		fmt.Fprintf(w, " 0x%s", r.ReverseNode)
+	}
+	if r.Proof != "" {
+		fmt.Fprintf(w, " 0x%s", r.Proof)
+	}
+	fmt.Fprintln(w)
+}
+
+// printResult prints r, the nth result of the search for cfg, in text
+// format, highlighting the prefix and suffix cfg asked for.
+func printResult(w io.Writer, n int, r generator.Result, total int64, elapsed time.Duration, cfg generator.Config) {
 	rate := float64(total) / elapsed.Seconds()
-	fmt.Printf("\r\033[K")
-	fmt.Printf("\n%s  #%d found after %s (%.0f addr/s)\n",
+	fmt.Fprintf(w, "\n%s  #%d found after %s (%.0f addr/s)\n",
 		green.Sprint("✓"), n, formatBig(total), rate)
-	bold.Printf("  Address:     ")
-	highlightAddress(r.Address)
-	fmt.Println()
-	bold.Printf("  Private key: ")
-	red.Printf("0x%s\n", r.PrivateKey)
-	fmt.Println()
+	bold.Fprint(w, "  Address:     ")
+	highlightAddress(w, r.Address, cfg.Prefix, cfg.Suffix)
+	fmt.Fprintln(w)
+	if r.Checksum != "" && r.Checksum != r.Address {
+		bold.Fprint(w, "  Checksum:    ")
+		fmt.Fprintln(w, r.Checksum)
+	}
+	if r.Pattern != "" {
+		bold.Fprint(w, "  Pattern:     ")
+		fmt.Fprintln(w, r.Pattern)
+	}
+	// With --any the match starts with the pattern; show it only if it
+	// adds to that.
+	if r.Match != "" && r.Match != r.Pattern {
+		bold.Fprint(w, "  Match:       ")
+		green.Fprintln(w, r.Match)
+	}
+	if r.Mnemonic != "" {
+		bold.Fprint(w, "  Mnemonic:    ")
+		if flagMaskKeys {
+			fmt.Fprint(w, "(masked) ")
+		} else {
+			red.Fprintf(w, "%s ", r.Mnemonic)
+		}
+		fmt.Fprintf(w, "(%d words)\n", len(strings.Fields(r.Mnemonic)))
+		bold.Fprint(w, "  Path:        ")
+		fmt.Fprintln(w, r.DerivationPath)
+	}
+	if r.Passphrase != "" {
+		bold.Fprint(w, "  Salt:        ")
+		fmt.Fprintln(w, r.Salt)
+	}
+	if r.PublicKey != "" {
+		bold.Fprint(w, "  Public key:  ")
+		fmt.Fprintf(w, "0x%s\n", r.PublicKey)
+	}
+	if r.ReverseNode != "" {
+		bold.Fprint(w, "  ENS reverse: ")
+		fmt.Fprintf(w, "0x%s\n", r.ReverseNode)
+	}
+	if r.Proof != "" {
+		bold.Fprint(w, "  Proof:       ")
+		fmt.Fprintf(w, "0x%s\n", r.Proof)
+	}
+	if r.XPub != "" {
+		bold.Fprint(w, "  Index:       ")
+		fmt.Fprintf(w, "%d (derive the key offline at <xpub path>/%d)\n\n", r.ChildIndex, r.ChildIndex)
+		return
+	}
+	bold.Fprint(w, "  Private key: ")
+	red.Fprintf(w, "0x%s\n", shownKey(r.PrivateKey))
+	if flagPattern.chain == generator.ChainBitcoin {
+		wif := generator.BitcoinWIF(r.PrivateKey)
+		if flagMaskKeys {
+			wif = generator.MaskSecret(wif)
+		}
+		bold.Fprint(w, "  WIF:         ")
+		red.Fprintln(w, wif)
+	}
+	fmt.Fprintln(w)
 }
 
-func highlightAddress(addr string) {
-	bare := addr[2:] // strip 0x
-	fmt.Print("0x")
-	prefixLen := len(flagPrefix)
-	suffixLen := len(flagSuffix)
+// shownKey returns key in hex as printed to the terminal: masked with
+// --mask-keys, in full otherwise.
+func shownKey(key generator.PrivateKey) string {
+	if flagMaskKeys {
+		return key.Masked()
+	}
+	return key.String()
+}
+
+// highlightAddress prints addr with the characters matched by prefix and
+// suffix, the patterns of the search that found it, in green.
+func highlightAddress(w io.Writer, addr, prefix, suffix string) {
+	bare := strings.TrimPrefix(addr, "0x")
+	if bare != addr {
+		fmt.Fprint(w, "0x")
+	}
+	prefixLen := len(prefix)
+	suffixLen := len(suffix)
 	addrLen := len(bare)
 	for i, ch := range bare {
 		inPrefix := prefixLen > 0 && i < prefixLen
 		inSuffix := suffixLen > 0 && i >= addrLen-suffixLen
 		if inPrefix || inSuffix {
-			green.Printf("%c", ch)
+			green.Fprintf(w, "%c", ch)
 		} else {
-			fmt.Printf("%c", ch)
+			fmt.Fprintf(w, "%c", ch)
+		}
+	}
+}
+
+// printNibbleHistogram shows each leading nibble's share of all candidates
+// and its deviation from the uniform 6.25%.
+func printNibbleHistogram(stats *generator.Stats) {
+	total := stats.Total.Load()
+	if total == 0 {
+		return
+	}
+	bold.Println("\nleading nibble distribution (expect 6.25% each):")
+	for n := range stats.Nibbles {
+		share := float64(stats.Nibbles[n].Load()) / float64(total) * 100
+		fmt.Printf("  %x  %5.2f%%  %+5.2f", n, share, share-6.25)
+		if n%4 == 3 {
+			fmt.Println()
 		}
 	}
 }
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"vanity-eth/internal/identicon"
+)
+
+var (
+	flagScoreIdenticon string
+	flagScoreTop       int
+)
+
+var scoreCmd = &cobra.Command{
+	Use:   "score [address...]",
+	Short: "Rank addresses by how closely they resemble a reference",
+	Long: `score ranks addresses, given as arguments or found anywhere in stdin
+(text, JSON or CSV output of a search), from best to worst.
+
+--identicon-seed scores by how similar each address's blockie identicon
+(the picture wallets show next to an address) is to the reference's.
+
+Example:
+  vanity-eth -p 00 -n 50 --format csv | vanity-eth score --identicon-seed 0xdead…`,
+	RunE: runScore,
+}
+
+func init() {
+	scoreCmd.Flags().StringVar(&flagScoreIdenticon, "identicon-seed", "", "reference address whose identicon candidates should resemble")
+	scoreCmd.Flags().IntVar(&flagScoreTop, "top", 0, "only print the N best addresses (0 = all)")
+	_ = scoreCmd.MarkFlagRequired("identicon-seed")
+	rootCmd.AddCommand(scoreCmd)
+}
+
+var addressRe = regexp.MustCompile(`\b0x[0-9a-fA-F]{40}\b`)
+
+func runScore(cmd *cobra.Command, args []string) error {
+	if !common.IsHexAddress(flagScoreIdenticon) {
+		return fmt.Errorf("--identicon-seed: not a valid address: %q", flagScoreIdenticon)
+	}
+	ref := identicon.New(flagScoreIdenticon)
+
+	addrs := args
+	if len(addrs) == 0 {
+		sc := bufio.NewScanner(os.Stdin)
+		for sc.Scan() {
+			addrs = append(addrs, addressRe.FindAllString(sc.Text(), -1)...)
+		}
+		if err := sc.Err(); err != nil {
+			return err
+		}
+	}
+
+	type scored struct {
+		addr  string
+		score float64
+	}
+	seen := make(map[string]bool, len(addrs))
+	var ranked []scored
+	for _, a := range addrs {
+		if !common.IsHexAddress(a) {
+			return fmt.Errorf("not a valid address: %q", a)
+		}
+		if seen[a] {
+			continue
+		}
+		seen[a] = true
+		ranked = append(ranked, scored{a, identicon.Similarity(ref, identicon.New(a))})
+	}
+	if len(ranked) == 0 {
+		return fmt.Errorf("no addresses to score")
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].score > ranked[j].score })
+	if flagScoreTop > 0 && flagScoreTop < len(ranked) {
+		ranked = ranked[:flagScoreTop]
+	}
+	for _, r := range ranked {
+		fmt.Printf("%5.1f%%  %s\n", r.score*100, r.addr)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"vanity-eth/internal/generator"
+	"vanity-eth/internal/ledger"
+)
+
+// statusServer exposes the state of a running CLI search over HTTP for
+// --serve. Private keys, and the phrases and inputs they derive from, are
+// only served when withKeys is set.
+type statusServer struct {
+	cfg      generator.Config
+	stats    *generator.Stats
+	start    time.Time
+	withKeys bool
+
+	mu      sync.Mutex
+	results generator.Recent
+
+	srv *http.Server
+}
+
+type statsResponse struct {
+	Total   int64    `json:"total"`
+	Found   int64    `json:"found"`
+	Rate    float64  `json:"rate"`
+	Elapsed float64  `json:"elapsed"`
+	ETA     *float64 `json:"eta"`
+}
+
+// startStatusServer listens on addr and serves /stats and /results until
+// ctx is cancelled or stop is called. /results lists the last keep results,
+// or all of them if keep is 0.
+func startStatusServer(ctx context.Context, addr string, cfg generator.Config, stats *generator.Stats, start time.Time, withKeys bool, keep int) (*statusServer, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	s := &statusServer{cfg: cfg, stats: stats, start: start, withKeys: withKeys}
+	s.results.Max = keep
+	s.srv = &http.Server{Handler: s.handler(), ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			red.Printf("--serve: %v\n", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		s.stop()
+	}()
+	return s, nil
+}
+
+// handler routes /stats and /results.
+func (s *statusServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /stats", s.handleStats)
+	mux.HandleFunc("GET /results", s.handleResults)
+	return mux
+}
+
+func (s *statusServer) add(r generator.Result) {
+	s.mu.Lock()
+	s.results.Add(r)
+	s.mu.Unlock()
+}
+
+func (s *statusServer) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = s.srv.Shutdown(ctx)
+}
+
+func (s *statusServer) handleStats(w http.ResponseWriter, _ *http.Request) {
+	elapsed := time.Since(s.start)
+	total := s.stats.Total.Load()
+	found := s.stats.Found.Load()
+	rate := float64(total) / elapsed.Seconds()
+
+	resp := statsResponse{Total: total, Found: found, Rate: rate, Elapsed: elapsed.Seconds()}
+	if eta := computeETA(s.cfg, int(found), s.cfg.Count, rate); eta > 0 {
+		secs := eta.Seconds()
+		resp.ETA = &secs
+	}
+	writeJSONResponse(w, resp)
+}
+
+func (s *statusServer) handleResults(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	out := make([]ledger.JSONResult, s.results.Len())
+	for i, r := range s.results.Results() {
+		if !s.withKeys {
+			r = r.Public()
+		}
+		out[i] = ledger.ToJSON(r)
+	}
+	s.mu.Unlock()
+	writeJSONResponse(w, out)
+}
+
+func writeJSONResponse(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
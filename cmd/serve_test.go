@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"vanity-eth/internal/generator"
+	"vanity-eth/internal/ledger"
+)
+
+// getJSON fetches path from srv and decodes the JSON body into v.
+func getJSON(t *testing.T, srv *httptest.Server, path string, v any) {
+	t.Helper()
+	resp, err := srv.Client().Get(srv.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: %s", path, resp.Status)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("GET %s: Content-Type %q", path, ct)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("GET %s: %v", path, err)
+	}
+}
+
+func newTestStatusServer(withKeys bool) (*statusServer, *httptest.Server) {
+	stats := &generator.Stats{}
+	stats.Total.Store(1000)
+	stats.Found.Store(1)
+	s := &statusServer{
+		cfg:      generator.Config{Prefix: "dead", Count: 3},
+		stats:    stats,
+		start:    time.Now().Add(-10 * time.Second),
+		withKeys: withKeys,
+	}
+	s.results.Max = 2
+	return s, httptest.NewServer(s.handler())
+}
+
+func TestStatusServer_Stats(t *testing.T) {
+	_, srv := newTestStatusServer(false)
+	defer srv.Close()
+
+	var got statsResponse
+	getJSON(t, srv, "/stats", &got)
+	if got.Total != 1000 || got.Found != 1 {
+		t.Errorf("total, found = %d, %d; want 1000, 1", got.Total, got.Found)
+	}
+	if got.Elapsed < 10 || got.Rate <= 0 || got.Rate > 100 {
+		t.Errorf("elapsed %.1fs at %.1f/s, want about 10s at 100/s", got.Elapsed, got.Rate)
+	}
+	if got.ETA == nil || *got.ETA <= 0 {
+		t.Errorf("eta = %v, want a positive estimate for 2 more matches", got.ETA)
+	}
+}
+
+func TestStatusServer_Results(t *testing.T) {
+	key := make(generator.PrivateKey, 32)
+	key[31] = 7
+	for _, withKeys := range []bool{false, true} {
+		s, srv := newTestStatusServer(withKeys)
+		// Three results into a server that keeps two: the oldest goes.
+		for _, addr := range []string{"0xdead01", "0xdead02", "0xdead03"} {
+			s.add(generator.Result{Address: addr, PrivateKey: key})
+		}
+
+		var got []ledger.JSONResult
+		getJSON(t, srv, "/results", &got)
+		srv.Close()
+		if len(got) != 2 || got[0].Address != "0xdead02" || got[1].Address != "0xdead03" {
+			t.Fatalf("withKeys=%v: results = %+v, want the last two", withKeys, got)
+		}
+		for _, r := range got {
+			if withKeys && r.PrivateKey != "0x"+key.String() {
+				t.Errorf("--serve-keys: key = %q, want %q", r.PrivateKey, "0x"+key.String())
+			}
+			if !withKeys && r.PrivateKey != "" {
+				t.Errorf("key %q served without --serve-keys", r.PrivateKey)
+			}
+		}
+		// The server's copy keeps its keys either way, for the output.
+		for _, r := range s.results.Results() {
+			if len(r.PrivateKey) == 0 {
+				t.Errorf("withKeys=%v: stored result lost its key", withKeys)
+			}
+		}
+	}
+}
+
+// In mnemonic mode the phrase controls the key, so it is withheld with it.
+func TestStatusServer_ResultsWithholdMnemonic(t *testing.T) {
+	const phrase = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	key := make(generator.PrivateKey, 32)
+	key[31] = 7
+	for _, withKeys := range []bool{false, true} {
+		s, srv := newTestStatusServer(withKeys)
+		s.add(generator.Result{Address: "0xdead01", PrivateKey: key, Mnemonic: phrase, DerivationPath: "m/44'/60'/0'/0/0"})
+
+		resp, err := srv.Client().Get(srv.URL + "/results")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		srv.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, secret := range []string{phrase, "mnemonic", "m/44", key.String()} {
+			if got := strings.Contains(string(body), secret); got != withKeys {
+				t.Errorf("withKeys=%v: body contains %q = %v:\n%s", withKeys, secret, got, body)
+			}
+		}
+	}
+}
+
+func TestStatusServer_RejectsOtherMethods(t *testing.T) {
+	_, srv := newTestStatusServer(false)
+	defer srv.Close()
+	resp, err := srv.Client().Post(srv.URL+"/stats", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("POST /stats: %s, want 405", resp.Status)
+	}
+}
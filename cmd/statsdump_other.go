@@ -0,0 +1,11 @@
+//go:build !unix
+
+package cmd
+
+import "os"
+
+// notifyStatsDump is a no-op: there is no SIGUSR1 on this platform.
+func notifyStatsDump(chan<- os.Signal) {}
+
+// ignoreStatsDump is a no-op: there is no SIGUSR1 on this platform.
+func ignoreStatsDump() {}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyStatsDump relays SIGUSR1 to ch so `kill -USR1 <pid>` prints a stats
+// snapshot without waiting for the next progress tick.
+func notifyStatsDump(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGUSR1)
+}
+
+// ignoreStatsDump makes SIGUSR1 do nothing, undoing notifyStatsDump, so the
+// signal never kills the process while no search is listening for it.
+func ignoreStatsDump() {
+	signal.Ignore(syscall.SIGUSR1)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"vanity-eth/internal/generator"
+)
+
+var (
+	flagSuggestBudget     time.Duration
+	flagSuggestPrefixOnly bool
+	flagSuggestWorkers    int
+	flagSuggestCase       bool
+	flagSuggestFast       bool
+)
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest",
+	Short: "Suggest the longest pattern findable within a time budget",
+	Long: `suggest benchmarks this machine's rate and prints the longest pattern
+whose expected search time fits in --budget. With --case-sensitive it gives
+two lengths: for a pattern of digits, and for one of letters, each of which
+must also match its checksum case. Mixed patterns fall in between.
+
+Examples:
+  vanity-eth suggest --budget 10m
+  vanity-eth suggest --budget 1h --prefix-only --case-sensitive`,
+	Args: cobra.NoArgs,
+	RunE: runSuggest,
+}
+
+func init() {
+	suggestCmd.Flags().DurationVar(&flagSuggestBudget, "budget", 10*time.Minute, "how long you are willing to search, e.g. 10m or 2h")
+	suggestCmd.Flags().BoolVar(&flagSuggestPrefixOnly, "prefix-only", false, "only suggest a prefix length")
+	suggestCmd.Flags().IntVarP(&flagSuggestWorkers, "workers", "w", generator.DefaultWorkers(), "number of parallel workers to benchmark")
+	suggestCmd.Flags().BoolVar(&flagSuggestCase, "case-sensitive", false, "assume case-sensitive (checksummed) matching")
+	suggestCmd.Flags().BoolVar(&flagSuggestFast, "fast", false, "benchmark the incremental --fast mode")
+	rootCmd.AddCommand(suggestCmd)
+}
+
+func runSuggest(cmd *cobra.Command, args []string) error {
+	if flagSuggestBudget <= 0 {
+		return fmt.Errorf("--budget must be positive")
+	}
+	if flagSuggestWorkers < 1 {
+		return fmt.Errorf("--workers must be at least 1")
+	}
+
+	cfg := generator.Config{Workers: flagSuggestWorkers, Fast: flagSuggestFast}
+	cyan.Printf("measuring rate with %d workers...\n", cfg.Workers)
+	rate := generator.MeasureRate(cfg, time.Second)
+	if rate <= 0 {
+		return fmt.Errorf("could not measure a generation rate")
+	}
+	budget := attemptsWithin(rate, flagSuggestBudget)
+	cyan.Printf("~%.0f addr/s, ~%s attempts within %s\n\n", rate, budget.String(), flagSuggestBudget)
+
+	prefix := func(pattern string) *big.Int {
+		return generator.HexDifficulty(pattern, "", "", flagSuggestCase)
+	}
+	if flagSuggestPrefixOnly {
+		suggestLengths("prefix", budget, rate, prefix)
+		return nil
+	}
+	suggestLengths("prefix/suffix", budget, rate, prefix)
+	suggestLengths("contains", budget, rate, func(pattern string) *big.Int {
+		return generator.HexDifficulty("", "", pattern, flagSuggestCase)
+	})
+	return nil
+}
+
+// suggestLengths prints the longest pattern of the given kind that fits in
+// budget. Case-sensitive, a letter costs twice what a digit does, since its
+// checksum case must match too, so the length depends on the pattern: it is
+// given for all digits, the longest, and for all letters, the shortest.
+func suggestLengths(kind string, budget *big.Int, ratePerSec float64, difficulty func(pattern string) *big.Int) {
+	of := func(c string) func(n int) *big.Int {
+		return func(n int) *big.Int { return difficulty(strings.Repeat(c, n)) }
+	}
+	if !flagSuggestCase {
+		printSuggestion(kind, budget, ratePerSec, of("a"))
+		return
+	}
+	printSuggestion(kind+", all digits", budget, ratePerSec, of("0"))
+	printSuggestion(kind+", all letters", budget, ratePerSec, of("a"))
+}
+
+// attemptsWithin returns how many attempts fit in budget at ratePerSec.
+func attemptsWithin(ratePerSec float64, budget time.Duration) *big.Int {
+	n, _ := big.NewFloat(ratePerSec * budget.Seconds()).Int(nil)
+	return n
+}
+
+// longestWithin inverts a difficulty function: it returns the longest hex
+// length n (up to 40) whose expected attempts are at most budget, or 0 if even
+// a single character is out of reach.
+func longestWithin(budget *big.Int, difficulty func(n int) *big.Int) int {
+	best := 0
+	for n := 1; n <= 40; n++ {
+		d := difficulty(n)
+		if d == nil || d.Cmp(budget) > 0 {
+			break
+		}
+		best = n
+	}
+	return best
+}
+
+// printSuggestion prints the longest pattern of the given kind that fits in
+// budget, with its difficulty and expected time.
+func printSuggestion(kind string, budget *big.Int, ratePerSec float64, difficulty func(n int) *big.Int) {
+	n := longestWithin(budget, difficulty)
+	if n == 0 {
+		yellow.Printf("%s: not even 1 hex char fits in the budget\n", kind)
+		return
+	}
+	d := difficulty(n)
+	secs, _ := new(big.Float).Quo(new(big.Float).SetInt(d), big.NewFloat(ratePerSec)).Float64()
+	green.Printf("%s: %d hex chars", kind, n)
+	fmt.Printf("  (~1 in %s, expected %s)\n", d.String(), fmtDuration(time.Duration(secs*float64(time.Second))))
+}
@@ -7,6 +7,12 @@ import (
 
 func runTUI() error {
 	m := tui.New()
+	if flagAppend && flagOutput != "" {
+		m = m.WithLedger(flagOutput)
+	}
+	if flagMaskKeys {
+		m = m.WithMaskedKeys()
+	}
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	_, err := p.Run()
 	return err
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"vanity-eth/internal/proof"
+)
+
+var (
+	flagVerifyKey     string
+	flagVerifyAddress string
+	flagVerifyProof   string
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Check that a private key, or a --prove signature, belongs to an address",
+	Long: `verify derives the address of --key and compares it with --address.
+With --proof instead of --key, it recovers the signer of the signature
+--prove made for the address and checks that it is the address itself,
+so a finder can show they hold the key without handing it over.
+A mixed-case --address must also carry a valid EIP-55 checksum.
+Exits non-zero on mismatch.
+
+Examples:
+  vanity-eth verify --key 0x… --address 0xdead…
+  vanity-eth verify --proof 0x… --address 0xdead…`,
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runVerify,
+}
+
+func init() {
+	verifyCmd.Flags().StringVar(&flagVerifyKey, "key", "", "private key in hex (with or without 0x)")
+	verifyCmd.Flags().StringVar(&flagVerifyAddress, "address", "", "address the key is claimed to produce")
+	verifyCmd.Flags().StringVar(&flagVerifyProof, "proof", "", "signature from --prove to check instead of a key")
+	verifyCmd.MarkFlagsOneRequired("key", "proof")
+	verifyCmd.MarkFlagsMutuallyExclusive("key", "proof")
+	_ = verifyCmd.MarkFlagRequired("address")
+	rootCmd.AddCommand(verifyCmd)
+}
+
+func runVerify(cmd *cobra.Command, args []string) error {
+	if flagVerifyProof != "" {
+		return verifyProof()
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(flagVerifyKey), "0x"), "0X"))
+	if err != nil {
+		return fmt.Errorf("--key: %v", err)
+	}
+	claimed := strings.TrimSpace(flagVerifyAddress)
+	if !common.IsHexAddress(claimed) {
+		return fmt.Errorf("--address: not a valid address: %q", claimed)
+	}
+
+	derived := crypto.PubkeyToAddress(key.PublicKey)
+	fmt.Printf("  %s %s\n", bold.Sprint("Derived:"), derived.Hex())
+	if derived != common.HexToAddress(claimed) {
+		red.Println("✗ mismatch: the key does not produce this address")
+		return fmt.Errorf("address mismatch")
+	}
+	if !checksumOK(claimed) {
+		yellow.Println("✗ the key matches, but the address has an invalid EIP-55 checksum")
+		return fmt.Errorf("bad checksum")
+	}
+	green.Println("✓ the key produces this address")
+	return nil
+}
+
+// verifyProof checks that --proof is --address's own signature of
+// proof.Message(--address).
+func verifyProof() error {
+	claimed := strings.TrimSpace(flagVerifyAddress)
+	if !common.IsHexAddress(claimed) {
+		return fmt.Errorf("--address: not a valid address: %q", claimed)
+	}
+	signer, err := proof.Recover(claimed, flagVerifyProof)
+	if err != nil {
+		return fmt.Errorf("--proof: %v", err)
+	}
+	fmt.Printf("  %s %s\n", bold.Sprint("Message:"), proof.Message(claimed))
+	fmt.Printf("  %s  %s\n", bold.Sprint("Signer:"), signer.Hex())
+	if signer != common.HexToAddress(claimed) {
+		red.Println("✗ mismatch: the address did not sign this")
+		return fmt.Errorf("address mismatch")
+	}
+	if !checksumOK(claimed) {
+		yellow.Println("✗ the signature matches, but the address has an invalid EIP-55 checksum")
+		return fmt.Errorf("bad checksum")
+	}
+	green.Println("✓ the address's own key signed this")
+	return nil
+}
+
+// checksumOK reports whether addr is all-lowercase, all-uppercase, or a
+// correct EIP-55 mixed-case checksum.
+func checksumOK(addr string) bool {
+	body := strings.TrimPrefix(strings.TrimPrefix(addr, "0x"), "0X")
+	if body == strings.ToLower(body) || body == strings.ToUpper(body) {
+		return true
+	}
+	return "0x"+body == common.HexToAddress(addr).Hex()
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// The address of private key 1.
+const keyOneAddress = "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"
+
+func TestRunVerify_KeyAndAddress(t *testing.T) {
+	defer func(key, addr string) { flagVerifyKey, flagVerifyAddress = key, addr }(flagVerifyKey, flagVerifyAddress)
+	one := fmt.Sprintf("%064x", 1)
+	for _, tc := range []struct {
+		name, key, addr, wantErr string
+	}{
+		{"checksummed", "0x" + one, keyOneAddress, ""},
+		{"bare key", one, keyOneAddress, ""},
+		{"uppercase 0X key", "0X" + one, keyOneAddress, ""},
+		{"lowercase address", one, strings.ToLower(keyOneAddress), ""},
+		{"uppercase address", one, "0x" + strings.ToUpper(keyOneAddress[2:]), ""},
+		{"padded", "  0x" + one + "\n", " " + keyOneAddress + " ", ""},
+		{"other key's address", one, "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF", "address mismatch"},
+		{"bad checksum", one, "0x7e5F4552091A69125d5DfCb7b8C2659029395Bdf", "bad checksum"},
+		{"bad key", "0xzz", keyOneAddress, "--key:"},
+		{"short address", one, "0x7E5F", "--address:"},
+	} {
+		flagVerifyKey, flagVerifyAddress = tc.key, tc.addr
+		err := runVerify(verifyCmd, nil)
+		switch {
+		case tc.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		case tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)):
+			t.Errorf("%s: error = %v, want %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestChecksumOK(t *testing.T) {
+	for _, tc := range []struct {
+		addr string
+		want bool
+	}{
+		{keyOneAddress, true},
+		{strings.ToLower(keyOneAddress), true},
+		{"0X" + strings.ToUpper(keyOneAddress[2:]), true},
+		{"0x7e5F4552091A69125d5DfCb7b8C2659029395Bdf", false},
+		{"0x7E5F4552091A69125d5DfCb7b8C2659029395BDF", false},
+	} {
+		if got := checksumOK(tc.addr); got != tc.want {
+			t.Errorf("checksumOK(%s) = %v, want %v", tc.addr, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Package ens computes ENS (Ethereum Name Service) namehashes as defined in
+// EIP-137, in particular the reverse-record node of an address, which is
+// what an ENS reverse registrar sets the address's primary name on.
+package ens
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Namehash returns the EIP-137 node of name: the zero node for the empty
+// name, and keccak256(Namehash(parent) || keccak256(label)) otherwise.
+// name must already be normalized (UTS-46, which for the ASCII names used
+// here means lowercase); Namehash does not normalize it.
+func Namehash(name string) [32]byte {
+	var node [32]byte
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		copy(node[:], crypto.Keccak256(node[:], crypto.Keccak256([]byte(labels[i]))))
+	}
+	return node
+}
+
+// ReverseName returns the reverse-record name of a hex address, with or
+// without 0x: the lowercase hex digits followed by ".addr.reverse".
+func ReverseName(addr string) string {
+	addr = strings.TrimPrefix(strings.TrimPrefix(addr, "0x"), "0X")
+	return strings.ToLower(addr) + ".addr.reverse"
+}
+
+// ReverseNode returns the namehash of addr's reverse-record name.
+func ReverseNode(addr string) [32]byte {
+	return Namehash(ReverseName(addr))
+}
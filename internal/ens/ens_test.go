@@ -0,0 +1,32 @@
+package ens
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestNamehash(t *testing.T) {
+	// Vectors from EIP-137 and the ENS documentation.
+	cases := []struct{ name, want string }{
+		{"", "0000000000000000000000000000000000000000000000000000000000000000"},
+		{"eth", "93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"},
+		{"foo.eth", "de9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+		{"addr.reverse", "91d1777781884d03a6757a803996e38de2a42967fb37eeaca72729271025a9e2"},
+	}
+	for _, c := range cases {
+		got := Namehash(c.name)
+		if hex.EncodeToString(got[:]) != c.want {
+			t.Errorf("Namehash(%q) = %x, want %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestReverseNode_IgnoresCaseAndPrefix(t *testing.T) {
+	const checksum = "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"
+	if got, want := ReverseName(checksum), "7e5f4552091a69125d5dfcb7b8c2659029395bdf.addr.reverse"; got != want {
+		t.Fatalf("ReverseName = %q, want %q", got, want)
+	}
+	if ReverseNode(checksum) != ReverseNode("7e5f4552091a69125d5dfcb7b8c2659029395bdf") {
+		t.Fatal("ReverseNode depends on case or 0x")
+	}
+}
@@ -0,0 +1,199 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Accelerator is a search backend: the CPU worker pool, or hardware such
+// as a GPU. Run prepares cfg and the matcher and hands the search to
+// cfg.Accelerator. Backends other than CPU live in files behind a build
+// tag and call RegisterAccelerator from an init function, so a default
+// build carries none of their dependencies.
+type Accelerator interface {
+	// Name is the name LookupAccelerator finds the accelerator by.
+	Name() string
+
+	// Search tries candidates until ctx is cancelled, cfg.Count results
+	// have been delivered, or it cannot go on, sending each match to
+	// resultCh and counting its work in stats as the CPU pool does. It may
+	// call cancel to end the whole run, for instance once the last result
+	// is sent, and returns when it will send nothing more; Run then closes
+	// resultCh.
+	Search(ctx context.Context, cancel context.CancelFunc, cfg Config, m Matcher, resultCh chan<- Result, stats *Stats)
+}
+
+// Matcher is the match Run builds from a Config for an Accelerator.
+type Matcher struct {
+	// Filter, if not nil, rejects candidates from their 20 raw address
+	// bytes before they are encoded for Match. A backend that cannot run it
+	// may skip it: Match alone is authoritative.
+	Filter func([]byte) bool
+
+	// Match reports whether an encoded address matches, and which part of
+	// the criteria it matched.
+	Match func(string) (string, bool)
+
+	// pattern is Match that also returns the index of the Config.Patterns
+	// entry matched, or -1, for the CPU pool to count results per pattern.
+	pattern func(string) (string, int, bool)
+}
+
+// CPU is the default Accelerator: cfg.Workers goroutines generating and
+// matching keys on the CPU, or as many as cfg.Scaler asks for.
+var CPU Accelerator = cpuPool{}
+
+type cpuPool struct{}
+
+func (cpuPool) Name() string { return "cpu" }
+
+// Search keeps the pool at its target size until every worker is done. A
+// worker that returns without being stopped ends the search, or its key
+// source ran dry, so no more are started after one has. Surplus workers
+// are stopped highest first and their numbers reused only once they have
+// returned, so that no two workers ever share a Fast walk.
+func (cpuPool) Search(ctx context.Context, cancel context.CancelFunc, cfg Config, m Matcher, resultCh chan<- Result, stats *Stats) {
+	type worker struct {
+		stop    context.CancelFunc
+		stopped bool
+	}
+	if m.pattern == nil {
+		m.pattern = patternMatch(nil, m.Match)
+	}
+	alive := map[int]*worker{}
+	exited := make(chan int)
+	start := func(i int) {
+		wctx, stop := context.WithCancel(ctx)
+		alive[i] = &worker{stop: stop}
+		cfg := cfg
+		cfg.worker = i
+		go func() {
+			defer func() { exited <- i }()
+			defer stop()
+			if cfg.PinCPU {
+				defer pinWorker(i)()
+			}
+			supervise(wctx, cancel, stats, func() {
+				runWorker(wctx, cancel, cfg, m.Filter, m.pattern, resultCh, stats)
+			})
+		}()
+	}
+
+	target := func() int { return cfg.Workers }
+	var changed <-chan struct{}
+	if cfg.Scaler != nil {
+		target, changed = cfg.Scaler.Workers, cfg.Scaler.changed
+	}
+	done := false
+	reconcile := func() {
+		var active []int
+		for i, w := range alive {
+			if !w.stopped {
+				active = append(active, i)
+			}
+		}
+		slices.Sort(active)
+		n := target()
+		for i := len(active) - 1; i >= n; i-- {
+			alive[active[i]].stopped = true
+			alive[active[i]].stop()
+			active = active[:i]
+		}
+		for i := 0; len(active) < n && !done; i++ {
+			if _, ok := alive[i]; !ok {
+				start(i)
+				active = append(active, i)
+			}
+		}
+		stats.Workers.Store(int64(len(active)))
+	}
+
+	reconcile()
+	for len(alive) > 0 {
+		select {
+		case i := <-exited:
+			if !alive[i].stopped {
+				done = true
+				stats.Workers.Add(-1)
+			}
+			delete(alive, i)
+		case <-changed:
+			reconcile()
+		case <-ctx.Done():
+			done, changed = true, nil
+		}
+	}
+	stats.Workers.Store(0)
+}
+
+// Scaler changes the number of CPU workers of a running search; see
+// Config.Scaler. It may be set from any goroutine, before or during Run.
+type Scaler struct {
+	target  atomic.Int64
+	changed chan struct{}
+}
+
+// NewScaler returns a Scaler asking for n workers.
+func NewScaler(n int) *Scaler {
+	s := &Scaler{changed: make(chan struct{}, 1)}
+	s.target.Store(int64(max(n, 1)))
+	return s
+}
+
+// Set asks for n workers, at least one. The pool starts or stops workers
+// to match within moments; a worker being stopped first finishes the key
+// batch it is on.
+func (s *Scaler) Set(n int) {
+	s.target.Store(int64(max(n, 1)))
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Workers returns the number of workers asked for.
+func (s *Scaler) Workers() int { return int(s.target.Load()) }
+
+var (
+	acceleratorsMu sync.Mutex
+	accelerators   = map[string]Accelerator{CPU.Name(): CPU}
+)
+
+// RegisterAccelerator makes a available to LookupAccelerator under its
+// Name. It panics if that name is taken, like a duplicate database/sql
+// driver.
+func RegisterAccelerator(a Accelerator) {
+	acceleratorsMu.Lock()
+	defer acceleratorsMu.Unlock()
+	if _, dup := accelerators[a.Name()]; dup {
+		panic("generator: RegisterAccelerator called twice for " + a.Name())
+	}
+	accelerators[a.Name()] = a
+}
+
+// Accelerators returns the names of the registered accelerators, sorted.
+func Accelerators() []string {
+	acceleratorsMu.Lock()
+	defer acceleratorsMu.Unlock()
+	names := make([]string, 0, len(accelerators))
+	for name := range accelerators {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LookupAccelerator returns the registered accelerator called name.
+func LookupAccelerator(name string) (Accelerator, error) {
+	acceleratorsMu.Lock()
+	a, ok := accelerators[name]
+	acceleratorsMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown accelerator %q (this build has %s)", name, strings.Join(Accelerators(), ", "))
+	}
+	return a, nil
+}
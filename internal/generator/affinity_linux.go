@@ -0,0 +1,52 @@
+//go:build linux
+
+package generator
+
+import (
+	"runtime"
+
+	"golang.org/x/sys/unix"
+)
+
+// CPUPinning reports whether Config.PinCPU has any effect on this platform.
+const CPUPinning = true
+
+// pinWorker locks the calling goroutine to its OS thread and binds that
+// thread to one of the CPUs the process may run on, the i-th modulo their
+// number, so workers spread one per core. It returns a function that gives
+// the thread its original affinity back and then undoes the lock; should
+// that fail, the thread stays locked, and the runtime discards it when the
+// goroutine exits rather than hand it to others bound to one CPU. If the
+// affinity cannot be set, the worker simply runs unpinned.
+func pinWorker(i int) (unpin func()) {
+	runtime.LockOSThread()
+	var allowed unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &allowed); err != nil {
+		return runtime.UnlockOSThread
+	}
+	cpus := cpusIn(&allowed)
+	if len(cpus) == 0 {
+		return runtime.UnlockOSThread
+	}
+	var set unix.CPUSet
+	set.Set(cpus[i%len(cpus)])
+	if err := unix.SchedSetaffinity(0, &set); err != nil {
+		return runtime.UnlockOSThread
+	}
+	return func() {
+		if unix.SchedSetaffinity(0, &allowed) == nil {
+			runtime.UnlockOSThread()
+		}
+	}
+}
+
+// cpusIn lists the CPUs in set in ascending order.
+func cpusIn(set *unix.CPUSet) []int {
+	var cpus []int
+	for cpu := 0; len(cpus) < set.Count(); cpu++ {
+		if set.IsSet(cpu) {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus
+}
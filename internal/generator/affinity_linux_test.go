@@ -0,0 +1,37 @@
+//go:build linux
+
+package generator
+
+import (
+	"runtime"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestPinWorker_RestoresAffinity(t *testing.T) {
+	// Hold our own lock so the thread can still be inspected after unpin.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	var before unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &before); err != nil {
+		t.Skipf("no affinity here: %v", err)
+	}
+	unpin := pinWorker(1)
+	var pinned unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &pinned); err != nil {
+		t.Fatal(err)
+	}
+	if before.Count() > 1 && pinned.Count() != 1 {
+		t.Errorf("pinned to %d CPUs, want 1", pinned.Count())
+	}
+	unpin()
+	var after unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &after); err != nil {
+		t.Fatal(err)
+	}
+	if after != before {
+		t.Errorf("affinity after unpin = %d CPUs, want the original %d", after.Count(), before.Count())
+	}
+}
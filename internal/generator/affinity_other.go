@@ -0,0 +1,15 @@
+//go:build !linux
+
+package generator
+
+import "runtime"
+
+// CPUPinning reports whether Config.PinCPU has any effect on this platform.
+const CPUPinning = false
+
+// pinWorker only locks the calling goroutine to its OS thread: there is no
+// portable way to bind a thread to a CPU here.
+func pinWorker(int) (unpin func()) {
+	runtime.LockOSThread()
+	return runtime.UnlockOSThread
+}
@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ASCIIPattern turns a word into the criteria for an address whose bytes
+// spell it in ASCII: contains is the hex of its bytes ("cafe" gives
+// "63616665"), for Config.Contains, and body a Config.RegexBody that holds
+// it to a byte boundary, where the bytes decode to the word rather than
+// straddling two characters. Set Config.ContainsOffsets to ASCIIOffsets of
+// the word for the odds to count only those boundaries.
+func ASCIIPattern(word string) (contains, body string, err error) {
+	if word == "" {
+		return "", "", fmt.Errorf("word is empty")
+	}
+	if len(word) > 20 {
+		return "", "", fmt.Errorf("%q is longer than the 20 bytes of an address", word)
+	}
+	for _, c := range []byte(word) {
+		if c < 0x20 || c > 0x7e {
+			return "", "", fmt.Errorf("%q has a character that is not printable ASCII", word)
+		}
+	}
+	contains = hex.EncodeToString([]byte(word))
+	return contains, "(?i)^(?:[0-9a-f]{2})*" + contains, nil
+}
+
+// ASCIIOffsets returns the number of byte boundaries in an address that a
+// word can start at, for Config.ContainsOffsets: 17 for "cafe", against the
+// 33 offsets its 8 hex characters could otherwise match at.
+func ASCIIOffsets(word string) int {
+	return max(0, 20-len(word)+1)
+}
+
+// ASCIIText renders the 20 bytes of addr as ASCII, with a dot for each byte
+// that is not printable, as hexdump -C does: 0x…63616665… shows as
+// "…cafe…". It returns "" if addr is not a hex address.
+func ASCIIText(addr string) string {
+	b, err := hex.DecodeString(strings.TrimPrefix(addr, "0x"))
+	if err != nil || len(b) != 20 {
+		return ""
+	}
+	for i, c := range b {
+		if c < 0x20 || c > 0x7e {
+			b[i] = '.'
+		}
+	}
+	return string(b)
+}
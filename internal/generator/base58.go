@@ -0,0 +1,372 @@
+package generator
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Encode encodes b in the Bitcoin base58 alphabet, one leading 1 per
+// leading zero byte.
+func base58Encode(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+	// log(256)/log(58) < 1.37, so this always has room.
+	digits := make([]byte, len(b)*137/100+1)
+	n := 0
+	for _, c := range b[zeros:] {
+		carry := int(c)
+		for i := 0; i < n; i++ {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits[n] = byte(carry % 58)
+			carry /= 58
+			n++
+		}
+	}
+	out := make([]byte, zeros+n)
+	for i := 0; i < zeros; i++ {
+		out[i] = '1'
+	}
+	for i := 0; i < n; i++ {
+		out[zeros+i] = base58Alphabet[digits[n-1-i]]
+	}
+	return string(out)
+}
+
+// base58Decode is the inverse of base58Encode.
+func base58Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	// log(58)/log(256) < 0.74, so this always has room.
+	buf := make([]byte, len(s)*74/100+1)
+	n := 0
+	for i := zeros; i < len(s); i++ {
+		carry := strings.IndexByte(base58Alphabet, s[i])
+		if carry < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", s[i])
+		}
+		for j := 0; j < n; j++ {
+			carry += int(buf[j]) * 58
+			buf[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			buf[n] = byte(carry)
+			carry >>= 8
+			n++
+		}
+	}
+	out := make([]byte, zeros+n)
+	for i := 0; i < n; i++ {
+		out[zeros+i] = buf[n-1-i]
+	}
+	return out, nil
+}
+
+// base58Format describes the base58check addresses of one chain for
+// matching and difficulty estimates.
+type base58Format struct {
+	// length is the usual address length, used to count the positions a
+	// contains pattern can take.
+	length int
+	// share returns the fraction of addresses starting with exactly
+	// prefix, counted over the encoded numbers since the leading
+	// characters of an address are far from uniform.
+	share func(prefix string) *big.Rat
+	// starts describes how addresses begin, for error messages.
+	starts string
+}
+
+// base58Formats maps the base58 chains to their formats.
+var base58Formats = map[string]base58Format{
+	ChainTron:    {length: 34, share: tronPrefixShare, starts: "T, then 9 to Z"},
+	ChainBitcoin: {length: 34, share: btcPrefixShare, starts: "1"},
+}
+
+// isBase58Chain reports whether chain matches base58 patterns rather than
+// hex ones.
+func isBase58Chain(chain string) bool {
+	_, ok := base58Formats[chain]
+	return ok
+}
+
+// payloadSpan is the number of 24-byte values after the version byte: the
+// 20-byte hash and the 4-byte checksum, treated as uniform.
+var payloadSpan = new(big.Int).Lsh(big.NewInt(1), 192)
+
+// tronPrefixShare counts over the 25-byte values a Tron address encodes.
+// All of them are 34 characters long in base58.
+func tronPrefixShare(prefix string) *big.Rat {
+	low := new(big.Int).Mul(big.NewInt(tronVersion), payloadSpan)
+	high := new(big.Int).Add(low, payloadSpan)
+	return new(big.Rat).SetFrac(base58PrefixCount(prefix, low, high), payloadSpan)
+}
+
+// btcPrefixShare counts over the 24 bytes after a P2PKH address's zero
+// version byte, which encodes as its leading 1. Each further leading zero
+// byte adds another 1, so addresses vary in length.
+func btcPrefixShare(prefix string) *big.Rat {
+	if !strings.HasPrefix(prefix, "1") {
+		return new(big.Rat)
+	}
+	return new(big.Rat).SetFrac(zeroPaddedPrefixCount(prefix[1:], 24), payloadSpan)
+}
+
+// zeroPaddedPrefixCount counts the n-byte values whose base58Encode form
+// (leading zero bytes as 1s) starts with prefix.
+func zeroPaddedPrefixCount(prefix string, n int) *big.Int {
+	if prefix == "" {
+		return new(big.Int).Lsh(big.NewInt(1), uint(8*n))
+	}
+	if n == 0 {
+		return new(big.Int)
+	}
+	if prefix[0] == '1' {
+		// A leading 1 is a leading zero byte.
+		return zeroPaddedPrefixCount(prefix[1:], n-1)
+	}
+	low := new(big.Int).Lsh(big.NewInt(1), uint(8*(n-1)))
+	high := new(big.Int).Lsh(big.NewInt(1), uint(8*n))
+	return base58PrefixCount(prefix, low, high)
+}
+
+// base58PrefixCount counts the numbers in [low, high) whose base58 digits,
+// written without leading zeros, start with prefix.
+func base58PrefixCount(prefix string, low, high *big.Int) *big.Int {
+	total := new(big.Int)
+	if prefix == "" || prefix[0] == '1' {
+		return total
+	}
+	v := new(big.Int)
+	for i := 0; i < len(prefix); i++ {
+		v.Mul(v, big.NewInt(58))
+		v.Add(v, big.NewInt(int64(strings.IndexByte(base58Alphabet, prefix[i]))))
+	}
+	// Numbers of digits digits starting with prefix lie in
+	// [v·58^(digits-k), (v+1)·58^(digits-k)), and that is all of them.
+	scale := big.NewInt(1)
+	for digits := len(prefix); ; digits++ {
+		lo := new(big.Int).Mul(v, scale)
+		if lo.Cmp(high) >= 0 {
+			return total
+		}
+		hi := new(big.Int).Add(lo, scale)
+		if lo.Cmp(low) < 0 {
+			lo.Set(low)
+		}
+		if hi.Cmp(high) > 0 {
+			hi.Set(high)
+		}
+		if hi.Cmp(lo) > 0 {
+			total.Add(total, hi.Sub(hi, lo))
+		}
+		scale.Mul(scale, big.NewInt(58))
+	}
+}
+
+// maxCaseVariants bounds how many case spellings of a prefix
+// base58PrefixProbability sums exactly before it approximates.
+const maxCaseVariants = 4096
+
+// base58Alts splits a base58 pattern into its | alternatives, in the case
+// they are matched in.
+func base58Alts(pattern string, caseSensitive bool) []string {
+	if strings.TrimSpace(pattern) == "" {
+		return nil
+	}
+	if !caseSensitive {
+		pattern = strings.ToLower(pattern)
+	}
+	var alts []string
+	for _, alt := range strings.Split(pattern, "|") {
+		alts = append(alts, strings.TrimSpace(alt))
+	}
+	return alts
+}
+
+// base58Spellings returns the base58 characters c stands for: c itself, or
+// unless caseSensitive, either case of it that the alphabet contains.
+func base58Spellings(c byte, caseSensitive bool) []byte {
+	var out []byte
+	for _, d := range []byte(base58Alphabet) {
+		if d == c || !caseSensitive && strings.EqualFold(string(d), string(c)) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// ValidateBase58Pattern checks a prefix, suffix or contains pattern for a
+// base58 chain such as ChainTron: base58 characters, with | separating
+// alternatives. Unless caseSensitive, letters may be typed in either case.
+// Patterns are matched against the whole address, so a prefix includes the
+// leading T or 1.
+func ValidateBase58Pattern(chain, pattern string, isPrefix, caseSensitive bool) error {
+	format, ok := base58Formats[chain]
+	if !ok {
+		return fmt.Errorf("chain %q does not use base58 addresses", chain)
+	}
+	for _, alt := range base58Alts(pattern, true) {
+		if alt == "" {
+			return ErrEmptyAlternative
+		}
+		if len(alt) > format.length {
+			return fmt.Errorf("%q is longer than an address (%d characters)", alt, format.length)
+		}
+		for i := 0; i < len(alt); i++ {
+			if len(base58Spellings(alt[i], caseSensitive)) == 0 {
+				return fmt.Errorf("invalid base58 character %q in %q", alt[i], alt)
+			}
+		}
+		if isPrefix && base58PrefixProbability(format, alt, caseSensitive).Sign() == 0 {
+			return fmt.Errorf("no address starts with %q (they start with %s)", alt, format.starts)
+		}
+	}
+	return nil
+}
+
+// Base58Matcher is BuildMatcher for base58 addresses: prefix, suffix and
+// contains are base58 patterns (see ValidateBase58Pattern) matched against
+// the whole address, and re against the address as displayed.
+func Base58Matcher(prefix, suffix, contains string, re *regexp.Regexp, caseSensitive bool) func(string) bool {
+	prefixAlts := base58Alts(prefix, caseSensitive)
+	suffixAlts := base58Alts(suffix, caseSensitive)
+	containsAlts := base58Alts(contains, caseSensitive)
+	return func(addr string) bool {
+		a := addr
+		if !caseSensitive {
+			a = strings.ToLower(addr)
+		}
+		if len(prefixAlts) > 0 && !matchAlt(a, prefixAlts, strings.HasPrefix) {
+			return false
+		}
+		if len(suffixAlts) > 0 && !matchAlt(a, suffixAlts, strings.HasSuffix) {
+			return false
+		}
+		if len(containsAlts) > 0 && !matchAlt(a, containsAlts, strings.Contains) {
+			return false
+		}
+		if re != nil && !re.MatchString(addr) {
+			return false
+		}
+		return true
+	}
+}
+
+// base58PatternProbability is hexProbability for a base58 chain. Prefixes
+// are exact; suffix and contains treat each character as uniform over the
+// 58-letter alphabet.
+func base58PatternProbability(chain, prefix, suffix, contains string, caseSensitive bool) *big.Rat {
+	if prefix == "" && suffix == "" && contains == "" {
+		return nil
+	}
+	format := base58Formats[chain]
+	p := big.NewRat(1, 1)
+	if prefix != "" {
+		sum := new(big.Rat)
+		for _, alt := range reducePrefixes(base58Alts(prefix, caseSensitive)) {
+			sum.Add(sum, base58PrefixProbability(format, alt, caseSensitive))
+		}
+		p.Mul(p, capOne(sum))
+	}
+	if suffix != "" {
+		sum := new(big.Rat)
+		for _, alt := range base58Alts(suffix, caseSensitive) {
+			sum.Add(sum, uniformBase58Probability(alt, caseSensitive))
+		}
+		p.Mul(p, capOne(sum))
+	}
+	if contains != "" {
+		sum := new(big.Rat)
+		for _, alt := range base58Alts(contains, caseSensitive) {
+			positions := int64(format.length - len(alt) + 1)
+			if positions > 0 {
+				q := uniformBase58Probability(alt, caseSensitive)
+				sum.Add(sum, q.Mul(q, big.NewRat(positions, 1)))
+			}
+		}
+		p.Mul(p, capOne(sum))
+	}
+	return p
+}
+
+// reducePrefixes drops alternatives that extend a shorter one, which
+// already covers them.
+func reducePrefixes(alts []string) []string {
+	var out []string
+	for _, a := range alts {
+		covered := false
+		for _, b := range alts {
+			if len(b) < len(a) && strings.HasPrefix(a, b) {
+				covered = true
+				break
+			}
+		}
+		if !covered && !slices.Contains(out, a) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// capOne returns min(p, 1).
+func capOne(p *big.Rat) *big.Rat {
+	if p.Cmp(big.NewRat(1, 1)) > 0 {
+		return big.NewRat(1, 1)
+	}
+	return p
+}
+
+// uniformBase58Probability returns the chance that s appears at a given
+// position of a uniformly random base58 string.
+func uniformBase58Probability(s string, caseSensitive bool) *big.Rat {
+	p := big.NewRat(1, 1)
+	for i := 0; i < len(s); i++ {
+		p.Mul(p, big.NewRat(int64(len(base58Spellings(s[i], caseSensitive))), 58))
+	}
+	return p
+}
+
+// base58PrefixProbability returns the share of format's addresses that
+// start with prefix, in any case the alphabet allows unless caseSensitive.
+func base58PrefixProbability(format base58Format, prefix string, caseSensitive bool) *big.Rat {
+	variants := []string{""}
+	for i := 0; i < len(prefix); i++ {
+		spellings := base58Spellings(prefix[i], caseSensitive)
+		if len(variants)*len(spellings) > maxCaseVariants {
+			// Too many to sum: assume the remaining characters are
+			// uniform, as they are for all but the first few.
+			return new(big.Rat).Mul(variantsShare(format, variants), uniformBase58Probability(prefix[i:], caseSensitive))
+		}
+		next := make([]string, 0, len(variants)*len(spellings))
+		for _, v := range variants {
+			for _, c := range spellings {
+				next = append(next, v+string(c))
+			}
+		}
+		variants = next
+	}
+	return variantsShare(format, variants)
+}
+
+// variantsShare sums format.share over distinct prefixes of one length,
+// which no address can start with two of.
+func variantsShare(format base58Format, prefixes []string) *big.Rat {
+	total := new(big.Rat)
+	for _, p := range prefixes {
+		total.Add(total, format.share(p))
+	}
+	return total
+}
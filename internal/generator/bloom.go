@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"strings"
+)
+
+// AvoidFalsePositives is the false-positive rate ParseAvoidList sizes its
+// filter for: about one address in a thousand outside the list is rejected
+// too, which only costs that much extra searching.
+const AvoidFalsePositives = 0.001
+
+// Bloom is a bloom filter of addresses, for Config.Avoid. Membership tests
+// never miss an added address but may report one that was never added.
+// Addresses are Keccak-256 output, but vanity ones share exactly the bytes
+// a search pins down, so every byte is mixed into the hash values; that
+// still costs only a few multiplications and bit lookups per test.
+type Bloom struct {
+	bits []uint64
+	mask uint64 // number of bits - 1; the size is a power of two
+	k    int    // bits set per address
+	n    int    // addresses added
+}
+
+// NewBloom returns a filter sized for n addresses at false-positive rate p.
+func NewBloom(n int, p float64) *Bloom {
+	n = max(n, 1)
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	m = max(64, uint64(1)<<bits.Len64(m-1))
+	k := max(1, int(math.Round(float64(m)/float64(n)*math.Ln2)))
+	return &Bloom{bits: make([]uint64, m/64), mask: m - 1, k: min(k, 16)}
+}
+
+// Add adds the 20-byte address addr.
+func (b *Bloom) Add(addr []byte) {
+	h1, h2 := bloomHashes(addr)
+	for i := 0; i < b.k; i++ {
+		bit := (h1 + uint64(i)*h2) & b.mask
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+	b.n++
+}
+
+// Has reports whether the 20-byte address addr may have been added.
+func (b *Bloom) Has(addr []byte) bool {
+	h1, h2 := bloomHashes(addr)
+	for i := 0; i < b.k; i++ {
+		bit := (h1 + uint64(i)*h2) & b.mask
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Len returns the number of addresses added.
+func (b *Bloom) Len() int { return b.n }
+
+// bloomHashes derives the two hashes of double hashing from all of addr's
+// bytes, so that addresses sharing a prefix or suffix, as a list of vanity
+// addresses and the candidates of a search do, still spread over the
+// table. The second is odd so that every probe of a power-of-two table
+// differs.
+func bloomHashes(addr []byte) (uint64, uint64) {
+	h := mix64(uint64(binary.LittleEndian.Uint32(addr[16:20])))
+	h = mix64(h ^ binary.LittleEndian.Uint64(addr[8:16]))
+	h = mix64(h ^ binary.LittleEndian.Uint64(addr[0:8]))
+	return h, mix64(h^0x9e3779b97f4a7c15) | 1
+}
+
+// mix64 is the SplitMix64 finalizer: a bijection on uint64 in which every
+// input bit affects every output bit.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	return x ^ x>>31
+}
+
+// ParseAvoidList reads addresses, one per line with or without 0x and in
+// any case, into a Bloom sized for AvoidFalsePositives. Blank lines and
+// lines starting with # are skipped.
+func ParseAvoidList(r io.Reader) (*Bloom, error) {
+	var addrs [][]byte
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X"))
+		if err != nil || len(b) != 20 {
+			return nil, fmt.Errorf("line %d: %q is not a 40-digit hex address", line, s)
+		}
+		addrs = append(addrs, b)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("address list is empty")
+	}
+	bloom := NewBloom(len(addrs), AvoidFalsePositives)
+	for _, a := range addrs {
+		bloom.Add(a)
+	}
+	return bloom, nil
+}
@@ -0,0 +1,63 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Brainwallet scrypt parameters. Every candidate costs one full scrypt run
+// (32 MiB, roughly 100ms per core), which is the point: whoever tries to
+// guess the passphrase pays the same price per salt. It also means only
+// very short patterns are practical in brainwallet mode.
+const (
+	brainwalletN = 1 << 15
+	brainwalletR = 8
+	brainwalletP = 1
+)
+
+// brainwalletBatch is how many salts a worker tries per batch.
+const brainwalletBatch = 2
+
+// BrainwalletKey derives the private key for passphrase at salt. The same
+// pair always gives the same key, so a match can be regenerated from the
+// passphrase and the reported salt alone.
+func BrainwalletKey(passphrase string, salt uint64) ([32]byte, error) {
+	var key [32]byte
+	k, err := scrypt.Key([]byte(passphrase), brainwalletSalt(salt), brainwalletN, brainwalletR, brainwalletP, len(key))
+	if err != nil {
+		return key, err
+	}
+	copy(key[:], k)
+	clear(k)
+	return key, nil
+}
+
+func brainwalletSalt(salt uint64) []byte {
+	return []byte("vanity-eth brainwallet " + strconv.FormatUint(salt, 10))
+}
+
+// fillBrainwallet derives a batch of keys from passphrase at the next salts
+// taken from salts, which all workers share so no salt is tried twice.
+func (g *keyGen) fillBrainwallet(b *keyBatch, passphrase string, salts *atomic.Uint64) error {
+	b.n = 0
+	for i := 0; i < brainwalletBatch; i++ {
+		salt := salts.Add(1) - 1
+		priv, err := BrainwalletKey(passphrase, salt)
+		if err != nil {
+			return err
+		}
+		if !g.scheme.publicKey(&priv, &b.pub[i]) {
+			clear(priv[:])
+			return fmt.Errorf("derived key out of range at salt %d", salt)
+		}
+		b.priv[i] = priv
+		clear(priv[:])
+		b.salt[i] = salt
+		b.addr[i] = g.hashAddress(&b.pub[i])
+	}
+	b.n = brainwalletBatch
+	return nil
+}
@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/crypto/ripemd160"
+)
+
+// Chains accepted by Config.Chain.
+const (
+	ChainEthereum = "eth"
+	ChainTron     = "tron"
+	ChainBitcoin  = "btc"
+)
+
+// addressEncoder renders a candidate the way a chain displays its address,
+// from the uncompressed public key (X then Y) or the Ethereum address
+// derived from it.
+type addressEncoder interface {
+	// encode returns the form patterns are matched against and results
+	// are reported in. Encodings where case carries no meaning honour
+	// caseSensitive as formatAddress does.
+	encode(pub *[64]byte, addr common.Address, caseSensitive bool) string
+	// canonical returns the form to copy, reported in Result.Checksum.
+	canonical(pub *[64]byte, addr common.Address) string
+}
+
+type ethEncoder struct{}
+
+func (ethEncoder) encode(_ *[64]byte, addr common.Address, caseSensitive bool) string {
+	return formatAddress(addr, caseSensitive)
+}
+
+func (ethEncoder) canonical(_ *[64]byte, addr common.Address) string { return addr.Hex() }
+
+// tronEncoder ignores caseSensitive: base58 is case-sensitive by nature, so
+// case-insensitive matching is done by the matcher instead.
+type tronEncoder struct{}
+
+func (tronEncoder) encode(_ *[64]byte, addr common.Address, _ bool) string {
+	return TronAddress(addr)
+}
+
+func (tronEncoder) canonical(_ *[64]byte, addr common.Address) string { return TronAddress(addr) }
+
+// btcEncoder ignores caseSensitive like tronEncoder.
+type btcEncoder struct{}
+
+func (btcEncoder) encode(pub *[64]byte, _ common.Address, _ bool) string {
+	return BitcoinAddress(pub)
+}
+
+func (btcEncoder) canonical(pub *[64]byte, _ common.Address) string { return BitcoinAddress(pub) }
+
+// encoders maps Config.Chain to its encoder; "" is Ethereum.
+var encoders = map[string]addressEncoder{
+	"":            ethEncoder{},
+	ChainEthereum: ethEncoder{},
+	ChainTron:     tronEncoder{},
+	ChainBitcoin:  btcEncoder{},
+}
+
+// ValidateChain checks that chain is a supported Config.Chain.
+func ValidateChain(chain string) error {
+	if _, ok := encoders[chain]; !ok {
+		return fmt.Errorf("unknown chain %q (want %s, %s or %s)", chain, ChainEthereum, ChainTron, ChainBitcoin)
+	}
+	return nil
+}
+
+// encoderFor returns the encoder for chain, falling back to Ethereum.
+func encoderFor(chain string) addressEncoder {
+	if e, ok := encoders[chain]; ok {
+		return e
+	}
+	return ethEncoder{}
+}
+
+// tronVersion is the version byte Tron puts in front of the 20-byte address
+// before base58check encoding it; it is why every address starts with T.
+const tronVersion = 0x41
+
+// TronAddress returns the base58check form Tron displays addr in: the
+// version byte, the 20 bytes and the first 4 bytes of their double SHA-256.
+func TronAddress(addr common.Address) string {
+	return base58Check(tronVersion, addr[:])
+}
+
+// Bitcoin base58check version bytes.
+const (
+	btcP2PKHVersion = 0x00 // mainnet pay-to-pubkey-hash, addresses start with 1
+	btcWIFVersion   = 0x80 // mainnet private keys
+)
+
+// BitcoinAddress returns the mainnet P2PKH address of the uncompressed
+// public key pub (X then Y): base58check of RIPEMD-160(SHA-256) of its
+// compressed form, which is what current wallets import a key as.
+func BitcoinAddress(pub *[64]byte) string {
+	var compressed [33]byte
+	compressed[0] = 0x02 | pub[63]&1
+	copy(compressed[1:], pub[:32])
+	sum := sha256.Sum256(compressed[:])
+	h := ripemd160.New()
+	h.Write(sum[:])
+	return base58Check(btcP2PKHVersion, h.Sum(nil))
+}
+
+// BitcoinWIF returns key in wallet import format, flagged as compressed so
+// that wallets derive the address BitcoinAddress reports.
+func BitcoinWIF(key PrivateKey) string {
+	payload := make([]byte, 0, len(key)+1)
+	payload = append(payload, key...)
+	payload = append(payload, 0x01)
+	defer clear(payload)
+	return base58Check(btcWIFVersion, payload)
+}
+
+// base58Check encodes version, payload and the first 4 bytes of their
+// double SHA-256 in base58.
+func base58Check(version byte, payload []byte) string {
+	buf := make([]byte, 0, 1+len(payload)+4)
+	buf = append(buf, version)
+	buf = append(buf, payload...)
+	first := sha256.Sum256(buf)
+	second := sha256.Sum256(first[:])
+	buf = append(buf, second[:4]...)
+	defer clear(buf)
+	return base58Encode(buf)
+}
+
+// base58CheckDecode decodes s and verifies its base58check checksum,
+// returning the version byte and payload together.
+func base58CheckDecode(s string) ([]byte, error) {
+	b, err := base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 5 {
+		return nil, errors.New("too short")
+	}
+	data, check := b[:len(b)-4], b[len(b)-4:]
+	first := sha256.Sum256(data)
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(check, second[:4]) {
+		return nil, errors.New("checksum mismatch")
+	}
+	return data, nil
+}
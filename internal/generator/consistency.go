@@ -0,0 +1,100 @@
+package generator
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// CheckCriteria looks for criteria in cfg that contradict each other, so
+// that no address can match and a search would never end: a prefix and
+// suffix that cannot share one address (see ValidatePatternFit), or a
+// Regex or RegexBody anchored with ^ or $ to literal text that disagrees
+// with Prefix or Suffix. It only catches such obvious cases; nil does not
+// promise that anything matches.
+func CheckCriteria(cfg Config) error {
+	if isBase58Chain(cfg.Chain) {
+		return nil
+	}
+	if err := ValidatePatternFit(cfg.Prefix, cfg.Suffix, cfg.Contains, cfg.CaseSensitive); err != nil {
+		return fmt.Errorf("--prefix/--suffix/--contains: %w", err)
+	}
+	for _, r := range []struct {
+		flag, expr, lead string
+	}{
+		{"regex", cfg.Regex, "0x"},
+		{"regex-body", cfg.RegexBody, ""},
+	} {
+		if r.expr == "" {
+			continue
+		}
+		head, tail, fold := regexAnchors(r.expr)
+		if fold {
+			head = strings.ToLower(head)
+		}
+		if head != "" {
+			if !strings.HasPrefix(r.lead, head) && !strings.HasPrefix(head, r.lead) {
+				return fmt.Errorf("--%s requires the address to start with %q, but it always starts with %q", r.flag, head, r.lead)
+			}
+			head = head[min(len(head), len(r.lead)):]
+		}
+		if alt, ok := agrees(head, cfg.Prefix, cfg.CaseSensitive, fold, strings.HasPrefix); !ok {
+			return fmt.Errorf("--%s requires the address to start with %q, which no --prefix alternative allows (such as %q)", r.flag, r.lead+head, alt)
+		}
+		if alt, ok := agrees(tail, cfg.Suffix, cfg.CaseSensitive, fold, strings.HasSuffix); !ok {
+			return fmt.Errorf("--%s requires the address to end with %q, which no --suffix alternative allows (such as %q)", r.flag, tail, alt)
+		}
+	}
+	return nil
+}
+
+// regexAnchors returns the literal text expr requires at the very start
+// (after ^) and the very end (before $) of the input, where it has any, and
+// whether those literals ignore case.
+func regexAnchors(expr string) (head, tail string, fold bool) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return "", "", false
+	}
+	re = re.Simplify()
+	if re.Op != syntax.OpConcat || len(re.Sub) < 2 {
+		return "", "", false
+	}
+	subs := re.Sub
+	if subs[0].Op == syntax.OpBeginText && subs[1].Op == syntax.OpLiteral {
+		head = string(subs[1].Rune)
+		fold = subs[1].Flags&syntax.FoldCase != 0
+	}
+	if n := len(subs); subs[n-1].Op == syntax.OpEndText && subs[n-2].Op == syntax.OpLiteral {
+		tail = string(subs[n-2].Rune)
+		fold = fold || subs[n-2].Flags&syntax.FoldCase != 0
+	}
+	return head, tail, fold
+}
+
+// agrees reports whether literal, required at one end of the address, is
+// compatible with some alternative of pattern at the same end: one of them
+// must extend the other. has is strings.HasPrefix or strings.HasSuffix.
+// Without caseSensitive, addresses are matched in lowercase. When it
+// returns false, alt is an alternative for the error message.
+func agrees(literal, pattern string, caseSensitive, fold bool, has func(s, affix string) bool) (alt string, ok bool) {
+	if literal == "" || pattern == "" {
+		return "", true
+	}
+	alts := PrefixAlternatives(pattern, caseSensitive)
+	if len(alts) == 0 {
+		return "", true
+	}
+	if fold {
+		literal = strings.ToLower(literal)
+	}
+	for _, a := range alts {
+		if fold {
+			a = strings.ToLower(a)
+		}
+		if has(literal, a) || has(a, literal) {
+			return "", true
+		}
+	}
+	return alts[0], false
+}
@@ -0,0 +1,81 @@
+package generator
+
+import (
+	"math"
+	"math/big"
+	"time"
+)
+
+// EstimateResult is the outlook of a search, as worked out by Estimate.
+type EstimateResult struct {
+	// Difficulty is the expected number of attempts per match.
+	Difficulty *big.Int
+	// ExpectedAttempts is the expected number of attempts to find all
+	// Config.Count matches, or one if Count is 0.
+	ExpectedAttempts *big.Int
+	// ETA is how long those attempts take at the given rate. It is 0 with
+	// Config.Invert, where nearly every address matches.
+	ETA time.Duration
+	// ProbabilityInTimeout is the chance of finding them within the given
+	// timeout, or 0 if there was none.
+	ProbabilityInTimeout float64
+}
+
+// Estimate works out the outlook of searching for cfg at rate addresses per
+// second, and within timeout unless it is 0. It reports false if cfg has no
+// criterion Difficulty can estimate.
+func Estimate(cfg Config, rate float64, timeout time.Duration) (EstimateResult, bool) {
+	d := Difficulty(cfg)
+	if d == nil {
+		return EstimateResult{}, false
+	}
+	count := max(cfg.Count, 1)
+	e := EstimateResult{
+		Difficulty:       d,
+		ExpectedAttempts: new(big.Int).Mul(d, big.NewInt(int64(count))),
+	}
+	if rate > 0 && !cfg.Invert {
+		expected, _ := new(big.Float).SetInt(e.ExpectedAttempts).Float64()
+		e.ETA = time.Duration(expected / rate * float64(time.Second))
+	}
+	if rate > 0 && timeout > 0 {
+		e.ProbabilityInTimeout = ProbabilityWithin(d, count, rate, timeout)
+	}
+	return e, true
+}
+
+// ProbabilityWithin returns the chance of finding at least count matches of
+// difficulty d within timeout at ratePerSec. Matches are modelled as a
+// Poisson process with mean λ = rate*timeout/difficulty, so for count 1 this
+// is 1 - exp(-λ).
+func ProbabilityWithin(d *big.Int, count int, ratePerSec float64, timeout time.Duration) float64 {
+	df, _ := new(big.Float).SetInt(d).Float64()
+	lambda := ratePerSec * timeout.Seconds() / df
+	if lambda <= 0 {
+		return 0
+	}
+	if count <= 1 {
+		return -math.Expm1(-lambda)
+	}
+	// P(X >= count) = 1 - Σ_{k<count} e^-λ λ^k / k!, summed in log space so
+	// large λ doesn't underflow.
+	var cdf float64
+	for k := 0; k < count; k++ {
+		lg, _ := math.Lgamma(float64(k + 1))
+		cdf += math.Exp(-lambda + float64(k)*math.Log(lambda) - lg)
+	}
+	return math.Max(0, 1-cdf)
+}
+
+// AttemptsForConfidence returns how many attempts give a chance p of at
+// least one match of difficulty d: -d·ln(1-p), rounded up, by the same
+// Poisson model as ProbabilityWithin. p must be between 0 and 1.
+func AttemptsForConfidence(d *big.Int, p float64) *big.Int {
+	n := new(big.Float).SetInt(d)
+	n.Mul(n, big.NewFloat(-math.Log1p(-p)))
+	attempts, acc := n.Int(nil)
+	if acc == big.Below {
+		attempts.Add(attempts, big.NewInt(1))
+	}
+	return attempts
+}
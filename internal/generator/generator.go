@@ -1,71 +1,720 @@
 package generator
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"vanity-eth/internal/scoring"
 )
 
-// Config holds all search parameters.
+// Config holds all search parameters. It marshals to JSON for display,
+// leaving out secrets (Brainwallet, ResumeFrom) and what has no useful
+// JSON form, such as readers, callbacks and Avoid.
 type Config struct {
-	Prefix        string
-	Suffix        string
-	Contains      string
-	Regex         string
-	Workers       int
-	Count         int
-	CaseSensitive bool
+	Prefix        string `json:"prefix,omitempty"`
+	Suffix        string `json:"suffix,omitempty"`
+	Contains      string `json:"contains,omitempty"`
+	Regex         string `json:"regex,omitempty"`
+	RegexBody     string `json:"regexBody,omitempty"` // like Regex, but applied to the 40 hex chars without 0x
+	Workers       int    `json:"workers"`
+	Count         int    `json:"count"` // 0 means no limit: search until ctx is cancelled
+	CaseSensitive bool   `json:"caseSensitive,omitempty"`
+
+	// Near, when set, restricts matches to addresses within MaxDistance
+	// differing nibbles of this target address (case-insensitive).
+	Near        string `json:"near,omitempty"`
+	MaxDistance int    `json:"maxDistance,omitempty"`
+
+	// ContainsOffsets, when positive, is the number of offsets Contains can
+	// match at because RegexBody pins it, as ASCIIPattern pins a word to
+	// byte boundaries (see ASCIIOffsets). Difficulty scales the estimate for
+	// Contains, which allows every offset, by the share left. It does not
+	// change what matches.
+	ContainsOffsets int `json:"containsOffsets,omitempty"`
+
+	// Mod, when set, requires the address, read as a 160-bit big-endian
+	// integer, to leave Remainder (nil means 0) when divided by Mod; see
+	// ParseMod.
+	Mod       *big.Int `json:"mod,omitempty"`
+	Remainder *big.Int `json:"remainder,omitempty"`
+
+	// Prefixes, when set, requires the address to start with one of these
+	// hex prefixes; the one found is reported in Result.Match. Unlike an
+	// alternation in Prefix, matching costs the same for any number of them.
+	Prefixes []string `json:"prefixes,omitempty"`
+
+	// Patterns, when set, searches for any of several patterns at once: a
+	// candidate must also match one of them, and the first it matches is
+	// reported in Result.Pattern and leads Result.Match. Count then applies
+	// to each pattern, and Run stops as soon as any one of them has Count
+	// results. The other criteria, Prefix, Suffix and Contains included,
+	// apply to every pattern.
+	Patterns []Pattern `json:"patterns,omitempty"`
+
+	// patterns counts results per pattern; Run compiles it from Patterns.
+	patterns *patternSet
+
+	// CountPerAlt, when positive, searches for this many results for each
+	// alternative of Prefix (see PrefixAlternatives) instead of Count in
+	// all: a match goes to the first alternative it starts with that still
+	// needs one, reported in Result.Pattern, and is dropped when none does.
+	// Run stops once every alternative has CountPerAlt results, and sets
+	// Count to that total.
+	CountPerAlt int `json:"countPerAlt,omitempty"`
+
+	// prefixQuota counts results per alternative; Run compiles it from
+	// Prefix.
+	prefixQuota *prefixQuota
+
+	// Words, when set, requires the address to contain at least one of
+	// these hex words; the one found is reported in Result.Match.
+	Words []string `json:"words,omitempty"`
+
+	// Spellings, when set, requires the address to contain one of these
+	// hex spellings of a word (see Spellings); the one found is reported in
+	// Result.Match.
+	Spellings []string `json:"spellings,omitempty"`
+
+	// MinScore, when positive, requires Scorer to rate the address at least
+	// this high; the score is reported in Result.Match. There is no
+	// difficulty estimate for it.
+	MinScore int `json:"minScore,omitempty"`
+	// Scorer rates an address for MinScore. Nil means scoring.Score.
+	Scorer func(addr string) int `json:"-"`
+
+	// Exclude rejects addresses containing any of these hex patterns, even
+	// when everything else matches.
+	Exclude []string `json:"exclude,omitempty"`
+
+	// Blocklist rejects addresses containing any of these hex words, in
+	// either case and even with Invert. Unlike Exclude it is a guard, not
+	// a criterion: see DefaultBlocklist.
+	Blocklist []string `json:"blocklist,omitempty"`
+
+	// Avoid rejects addresses in this set, checked on the raw address
+	// bytes before anything else and, like Blocklist, even with Invert. A
+	// bloom filter also rejects a small share of other addresses (see
+	// AvoidFalsePositives), which only makes the search a little longer.
+	Avoid *Bloom `json:"-"`
+
+	// Invert accepts exactly the addresses the other criteria reject.
+	Invert bool `json:"invert,omitempty"`
+
+	// Audit records the leading-nibble distribution in Stats.Nibbles.
+	Audit bool `json:"audit,omitempty"`
+
+	// RejectWeak discards matches whose private key is weak (see
+	// isWeakScalar), counting them in Stats.Weak. Fresh random keys are
+	// practically never weak; a broken custom Rand can make them so.
+	RejectWeak bool `json:"rejectWeak,omitempty"`
+
+	// Fast makes each worker walk forward from one random key by point
+	// addition instead of drawing a fresh key per attempt. It is an order
+	// of magnitude faster, but all keys a worker produces are consecutive
+	// integers from a single random base: anyone who learns one of them can
+	// recover its neighbours. Only the base keys carry fresh entropy.
+	Fast bool `json:"fast,omitempty"`
+
+	// ResumeFrom continues earlier Fast walks instead of starting new ones:
+	// worker i walks on from ResumeFrom[i], trying ResumeFrom[i]+1 first.
+	// Workers past its end start from random keys. Stats.Walks reports
+	// where the walks stopped, ready to pass back here.
+	ResumeFrom []PrivateKey `json:"-"`
+
+	// worker numbers the worker a copy of the Config belongs to; Run sets
+	// it.
+	worker int
+
+	// PinCPU locks each worker to an OS thread and, where CPUPinning is
+	// true, binds worker i to the i-th CPU the process may use. It is
+	// experimental: it can help cache locality on NUMA machines, and it
+	// can hurt when the machine has other work to schedule.
+	PinCPU bool `json:"pinCPU,omitempty"`
+
+	// Mnemonic derives every candidate from a fresh BIP-39 phrase of
+	// MnemonicWords words (0 means DefaultMnemonicWords) at DerivationPath
+	// (empty means DefaultDerivationPath), so a match can be imported into
+	// an HD wallet. It is far slower than raw keys because of the PBKDF2
+	// seed stretching.
+	Mnemonic       bool   `json:"mnemonic,omitempty"`
+	MnemonicWords  int    `json:"mnemonicWords,omitempty"`
+	DerivationPath string `json:"derivationPath,omitempty"`
+
+	// MaxAttempts stops the search once about this many candidates have
+	// been tried; 0 means no limit. Workers check it before each attempt,
+	// so the final Stats.Total can overshoot by up to Workers.
+	MaxAttempts int64 `json:"maxAttempts,omitempty"`
+
+	// Brainwallet derives every candidate from this passphrase and a salt
+	// counting up from 0 (see BrainwalletKey), so a match can be recreated
+	// from the passphrase and the salt in Result.Salt. Anyone who guesses
+	// the passphrase can do the same; human-chosen passphrases are guessed
+	// all the time.
+	Brainwallet string `json:"-"`
+
+	// salts hands out brainwallet salts; Run shares one across workers.
+	salts *atomic.Uint64
+
+	// Keys, if set, replaces key generation: workers take hex private keys
+	// from it, one per line (see keyStream.read), and Run stops once it is
+	// exhausted. Stats.Total then counts the keys consumed. It cannot be
+	// combined with Fast, Mnemonic or Brainwallet, and Rand goes unused.
+	Keys io.Reader `json:"-"`
+
+	// keys feeds Keys to the workers; Run starts it.
+	keys *keyStream
+
+	// XPub, if set, searches the non-hardened children of this extended
+	// public key at indices 0, 1, 2, … instead of generating keys. Results
+	// carry the index in Result.ChildIndex and no private key: the machine
+	// searching never has one, and the owner derives it offline. It cannot
+	// be combined with Keys, Fast, Mnemonic, Brainwallet or RejectWeak.
+	XPub *XPub `json:"xpub,omitempty"`
+
+	// xpubNext hands out child indices; Run shares one across workers.
+	xpubNext *atomic.Uint64
+
+	// PublicKey, if set, fills Result.PublicKey with each match's public
+	// key in this SEC1 encoding: PublicKeyCompressed, or
+	// PublicKeyUncompressed for any other value.
+	PublicKey string `json:"publicKey,omitempty"`
+
+	// seen holds the addresses already sent when Count > 1; Run shares one
+	// across workers.
+	seen *seenSet
+
+	// Chain selects how addresses are encoded and matched: ChainEthereum
+	// (or empty) for hex, or ChainTron or ChainBitcoin (P2PKH) for
+	// base58check, where Prefix, Suffix and Contains are base58 patterns
+	// (see Base58Matcher) and hex-only criteria such as Near, Words or
+	// Exclude do not apply.
+	Chain string `json:"chain,omitempty"`
+
+	// KeyType is the key scheme candidates are generated for, one of
+	// KeyTypes; empty means KeyTypeSecp256k1, so far the only one. Run
+	// stops at once with an error in Stats.Err for an unknown one.
+	KeyType string `json:"keyType,omitempty"`
+
+	// OnProgress, if set, is called with Stats.Total and Stats.Found each
+	// time another progressStep candidates have been tried. It runs on a
+	// goroutine of its own, so a slow callback delays only later calls,
+	// never the search. Run waits for a running call before it closes the
+	// result channel, so none is made after Run returns.
+	OnProgress func(total, found int64) `json:"-"`
+
+	// Rand is the entropy source for private keys; nil gives each worker a
+	// ChaCha20 stream of its own, seeded from crypto/rand. A Rand is shared
+	// by all workers and must be safe for concurrent use.
+	Rand io.Reader `json:"-"`
+
+	// Accelerator runs the search; nil means CPU. Other accelerators
+	// may not support every criterion or key source.
+	Accelerator Accelerator `json:"-"`
+
+	// Scaler, if not nil, sets the number of CPU workers while Run is
+	// going, starting from its own count rather than Workers. Other
+	// accelerators ignore it.
+	Scaler *Scaler `json:"-"`
 }
 
 // Result holds a found address and its private key.
 type Result struct {
 	Address    string
-	PrivateKey string
+	PrivateKey PrivateKey
+
+	// Checksum is the EIP-55 mixed-case form of Address, the canonical one
+	// to copy, whatever case Address was matched and displayed in. For
+	// base58 chains it is the same as Address.
+	Checksum string
+
+	// Match names the alternative that satisfied the search when the
+	// criteria have several worth telling apart (e.g. the word from
+	// Config.Words). Empty otherwise.
+	Match string
+
+	// Mnemonic is the BIP-39 phrase the key was derived from in mnemonic
+	// mode. It gives the key away, so it is withheld wherever PrivateKey
+	// is (see Public). Unlike PrivateKey it is a string and cannot be
+	// wiped; ZeroKeys only lets go of it.
+	Mnemonic string
+
+	// DerivationPath is the BIP-32 path the key was derived at in
+	// mnemonic mode, e.g. m/44'/60'/0'/0/0.
+	DerivationPath string
+
+	// Passphrase and Salt are the brainwallet inputs the key was derived
+	// from; Passphrase is empty outside brainwallet mode.
+	Passphrase string
+	Salt       uint64
+
+	// XPub and ChildIndex locate the key in xpub mode: it is XPub's
+	// non-hardened child at ChildIndex. PrivateKey is empty then, and XPub
+	// is empty outside xpub mode.
+	XPub       string
+	ChildIndex uint32
+
+	// PublicKey is the hex public key, without 0x, when Config.PublicKey
+	// asks for it.
+	PublicKey string
+
+	// ReverseNode is the ENS namehash of the address's reverse record
+	// (<address>.addr.reverse), in hex without 0x. Run leaves it empty;
+	// callers fill it in for display.
+	ReverseNode string
+
+	// Proof is the key's signature of the address, in hex without 0x, as
+	// made by proof.Sign. Run leaves it empty; callers fill it in.
+	Proof string
+
+	// Pattern is the spec the result was searched for when a caller runs
+	// several searches in one go (e.g. a --batch file), the ID of the
+	// Config.Patterns entry it matched, or with Config.CountPerAlt the
+	// Prefix alternative it counts towards. Empty otherwise.
+	Pattern string
+}
+
+// Public returns a copy of r with only the fields that are safe to show to
+// anyone: the address and what describes it. PrivateKey, Mnemonic,
+// DerivationPath and the brainwallet inputs are left out, as is any field
+// added to Result until it is listed here.
+func (r Result) Public() Result {
+	return Result{
+		Address:     r.Address,
+		Checksum:    r.Checksum,
+		Match:       r.Match,
+		XPub:        r.XPub,
+		ChildIndex:  r.ChildIndex,
+		PublicKey:   r.PublicKey,
+		ReverseNode: r.ReverseNode,
+		Proof:       r.Proof,
+		Pattern:     r.Pattern,
+	}
+}
+
+// PrivateKey is a raw 32-byte private key. It stays a byte slice so it can be
+// wiped with Zero once it is no longer needed; String converts it to hex only
+// at the display boundary.
+type PrivateKey []byte
+
+// ParsePrivateKey parses a hex private key, 0x optional, and checks that it
+// is a valid secp256k1 scalar.
+func ParsePrivateKey(s string) (PrivateKey, error) {
+	var priv [32]byte
+	defer clear(priv[:])
+	if err := parseKeyLine([]byte(strings.TrimSpace(s)), &priv); err != nil {
+		return nil, err
+	}
+	return PrivateKey(bytes.Clone(priv[:])), nil
+}
+
+// String returns the key as lowercase hex without a 0x prefix.
+func (k PrivateKey) String() string {
+	return hex.EncodeToString(k)
+}
+
+// Masked returns the key like String but masked by MaskSecret.
+func (k PrivateKey) Masked() string {
+	return MaskSecret(k.String())
+}
+
+// maskedChars is how many characters MaskSecret leaves showing at each end.
+const maskedChars = 4
+
+// MaskSecret hides all but the first and last few characters of a secret
+// such as a hex key or a WIF: enough to check it against a saved copy
+// without showing it.
+func MaskSecret(s string) string {
+	if len(s) <= 2*maskedChars {
+		return strings.Repeat("*", len(s))
+	}
+	return s[:maskedChars] + "****...****" + s[len(s)-maskedChars:]
+}
+
+// Zero overwrites the key bytes. This is best-effort: the garbage collector
+// may already have copied them, and any hex string made by String is
+// immutable and lingers until collected.
+func (k PrivateKey) Zero() {
+	clear(k)
+}
+
+// ZeroKeys wipes the private key of every result and drops its mnemonic,
+// so the phrase is not left in results whose key is gone.
+func ZeroKeys(results []Result) {
+	for i := range results {
+		results[i].PrivateKey.Zero()
+		results[i].Mnemonic = ""
+	}
 }
 
 // Stats holds live counters updated atomically during a search.
 type Stats struct {
 	Total atomic.Int64
 	Found atomic.Int64
+
+	// Nibbles counts candidates by the first nibble of their address. It is
+	// only filled when Config.Audit is set; a healthy RNG gives ~1/16 each.
+	Nibbles [16]atomic.Int64
+
+	// KeyFailures counts key-generation failures since the last success.
+	// Run gives up once it reaches maxKeyFailures.
+	KeyFailures atomic.Int64
+
+	// Panics counts worker panics Run recovered from; see LastPanic.
+	Panics atomic.Int64
+
+	// Weak counts matches discarded by Config.RejectWeak.
+	Weak atomic.Int64
+
+	// Duplicates counts matches discarded because their address was
+	// already sent.
+	Duplicates atomic.Int64
+
+	// Workers is how many CPU workers are searching; it follows
+	// Config.Scaler while Run is going.
+	Workers atomic.Int64
+
+	mu        sync.Mutex
+	err       error
+	lastPanic string
+	// walks holds the last key each Config.Fast walk checked; see Walks.
+	walks []PrivateKey
+}
+
+// ErrEntropy is reported by Stats.Err when Run stops because the entropy
+// source keeps failing.
+var ErrEntropy = errors.New("entropy source failing")
+
+// ErrWorkerPanics is reported by Stats.Err when Run stops because workers
+// keep panicking.
+var ErrWorkerPanics = errors.New("too many worker panics")
+
+// maxPanics is how many recovered worker panics Run tolerates in one search.
+const maxPanics = 1000
+
+// maxKeyFailures is how many key-generation failures in a row Run tolerates
+// before aborting the search.
+const maxKeyFailures = 100
+
+// Err returns the error that aborted the search, or nil if it ran normally.
+// It is final once the result channel has been closed.
+func (s *Stats) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// LastPanic describes the most recent recovered worker panic, if any.
+func (s *Stats) LastPanic() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastPanic
+}
+
+func (s *Stats) recordPanic(v any) {
+	s.Panics.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastPanic = fmt.Sprint(v)
+}
+
+// Walks returns the last key each worker's Config.Fast walk checked, in
+// worker order, or nil outside fast mode. Passed back as Config.ResumeFrom
+// they continue the walks where they stopped; an entry is nil if its worker
+// never started. The keys are secret: every later key of a walk follows
+// from them. Read Walks once Run has returned.
+func (s *Stats) Walks() []PrivateKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.walks)
+}
+
+// startWalks makes room for n walks, seeding them from from.
+func (s *Stats) startWalks(n int, from []PrivateKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.walks = make([]PrivateKey, n)
+	for i := 0; i < n && i < len(from); i++ {
+		s.walks[i] = bytes.Clone(from[i])
+	}
+}
+
+// walk returns where worker i's walk stands, or nil if it has not started.
+func (s *Stats) walk(i int) PrivateKey {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i >= len(s.walks) {
+		return nil
+	}
+	return s.walks[i]
+}
+
+// setWalk records key as the last one worker i's walk checked, making room
+// for workers a Scaler added.
+func (s *Stats) setWalk(i int, key PrivateKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i >= len(s.walks) {
+		s.walks = append(s.walks, make([]PrivateKey, i+1-len(s.walks))...)
+	}
+	s.walks[i].Zero()
+	s.walks[i] = key
+}
+
+func (s *Stats) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
 }
 
 // HexDifficulty returns the expected number of attempts to find a single match
-// for the combined hex pattern complexity (prefix + suffix + contains).
+// for the combined hex pattern complexity (prefix + suffix + contains), less
+// any address containing one of exclude.
 // When caseSensitive is true, letter case in a-f is treated as fixed.
-// Returns nil if all patterns are empty.
-func HexDifficulty(prefix, suffix, contains string, caseSensitive bool) *big.Int {
+// Returns nil if all patterns are empty, or if no address can match them
+// (see ValidatePatternFit).
+func HexDifficulty(prefix, suffix, contains string, caseSensitive bool, exclude ...string) *big.Int {
+	return expectedAttempts(hexProbability(prefix, suffix, contains, caseSensitive, exclude...))
+}
+
+// Factors splits a hex pattern's odds into the digits a match needs, 1 in
+// 16^Nibbles, and the letters among them whose case a checksummed match
+// must also get right, 1 in 2^Letters more. HexDifficulty multiplies them
+// when matching is case-sensitive.
+type Factors struct {
+	Nibbles, Letters int
+}
+
+// PatternFactors returns the Factors of pattern, of its shortest
+// alternative (with the fewest letters) if it has several, as the contains
+// estimate uses. Both are 0 for an empty or invalid pattern.
+func PatternFactors(pattern string) Factors {
+	n, letters := minPatternLenAndLetters(pattern)
+	return Factors{Nibbles: n, Letters: letters}
+}
+
+// Hex returns 16^Nibbles.
+func (f Factors) Hex() *big.Int { return patternDenominator(f.Nibbles, 0, false) }
+
+// Case returns 2^Letters.
+func (f Factors) Case() *big.Int { return new(big.Int).Lsh(big.NewInt(1), uint(f.Letters)) }
+
+// addressSpace is how many addresses there are: 16^40.
+var addressSpace = new(big.Int).Lsh(big.NewInt(1), 160)
+
+// hopelessAttempts is where a search stops being practical at any scale:
+// 2^128 attempts would keep every computer on Earth busy for far longer
+// than the universe has existed.
+var hopelessAttempts = new(big.Int).Lsh(big.NewInt(1), 128)
+
+// DifficultyWarning returns a warning when difficulty d, as returned by
+// Difficulty, puts a pattern out of practical reach, and "" otherwise.
+func DifficultyWarning(d *big.Int) string {
+	switch {
+	case d == nil:
+		return ""
+	case d.Cmp(addressSpace) > 0:
+		return "this pattern is rarer than 1 in 16^40, the number of addresses there are: quite possibly no address matches it at all"
+	case d.Cmp(hopelessAttempts) >= 0:
+		return "this pattern is practically unachievable: it needs 2^128 or more attempts, beyond every computer on Earth"
+	}
+	return ""
+}
+
+// Difficulty returns the expected number of attempts to find a single match
+// for every estimable criterion in cfg. Returns nil if none is set, or if
+// MinScore is, since score thresholds cannot be estimated. With Invert this
+// is ~1, since nearly every address avoids a pattern.
+func Difficulty(cfg Config) *big.Int {
+	if cfg.MinScore > 0 {
+		return nil
+	}
+	var p *big.Rat
+	if isBase58Chain(cfg.Chain) {
+		p = base58PatternProbability(cfg.Chain, cfg.Prefix, cfg.Suffix, cfg.Contains, cfg.CaseSensitive)
+	} else {
+		p = hexProbability(cfg.Prefix, cfg.Suffix, cfg.Contains, cfg.CaseSensitive, cfg.Exclude...)
+		if all := addressNibbles - MinHexPatternLen(cfg.Contains) + 1; cfg.ContainsOffsets > 0 && cfg.Contains != "" && all > cfg.ContainsOffsets {
+			p = mulProbability(p, big.NewRat(int64(cfg.ContainsOffsets), int64(all)))
+		}
+	}
+	if cfg.Near != "" {
+		p = mulProbability(p, nearProbability(cfg.MaxDistance))
+	}
+	if cfg.Mod != nil {
+		p = mulProbability(p, modProbability(cfg.Mod))
+	}
+	if len(cfg.Prefixes) > 0 {
+		p = mulProbability(p, prefixesProbability(cfg.Prefixes, cfg.CaseSensitive))
+	}
+	if len(cfg.Patterns) > 0 {
+		p = mulProbability(p, patternsProbability(cfg))
+	}
+	if len(cfg.Words) > 0 {
+		p = mulProbability(p, wordsProbability(cfg.Words, cfg.CaseSensitive))
+	}
+	if len(cfg.Spellings) > 0 {
+		p = mulProbability(p, wordsProbability(cfg.Spellings, cfg.CaseSensitive))
+	}
+	if cfg.Invert && p != nil {
+		p.Sub(big.NewRat(1, 1), p)
+	}
+	if len(cfg.Blocklist) > 0 {
+		p = mulProbability(p, hexProbability("", "", "", false, cfg.Blocklist...))
+	}
+	return expectedAttempts(p)
+}
+
+// mulProbability returns p*q, treating a nil p as "no constraint yet".
+func mulProbability(p, q *big.Rat) *big.Rat {
+	if p == nil {
+		return q
+	}
+	return p.Mul(p, q)
+}
+
+// ErrPatternTooLong is returned by ValidatePatternFit when no address body
+// can satisfy the prefix, suffix and contains patterns together.
+var ErrPatternTooLong = errors.New("pattern does not fit in a 40-character address")
+
+// ValidatePatternFit checks that some address can match prefix, suffix and
+// contains at once: no pattern may need more than 40 hex characters, and a
+// prefix and suffix that together do must agree where they overlap.
+func ValidatePatternFit(prefix, suffix, contains string, caseSensitive bool) error {
+	if p := hexProbability(prefix, suffix, contains, caseSensitive); p != nil && p.Sign() == 0 {
+		return ErrPatternTooLong
+	}
+	return nil
+}
+
+// hexProbability returns the chance that a random address satisfies the
+// prefix, suffix and contains patterns and avoids every exclude pattern, or
+// nil if all are empty. Exclusions are treated as independent of the rest
+// and of each other, which is close enough for short patterns. The chance
+// is 0 when the patterns cannot fit in an address together.
+func hexProbability(prefix, suffix, contains string, caseSensitive bool, exclude ...string) *big.Rat {
 	var active bool
 	totalP := big.NewRat(1, 1)
 
-	if p := edgePatternProbability(prefix, true, caseSensitive); p != nil {
-		totalP.Mul(totalP, p)
-		active = true
-	}
-	if p := edgePatternProbability(suffix, false, caseSensitive); p != nil {
+	if p, ok := overlapProbability(prefix, suffix, caseSensitive); ok {
 		totalP.Mul(totalP, p)
 		active = true
+	} else {
+		if p := edgePatternProbability(prefix, true, caseSensitive); p != nil {
+			totalP.Mul(totalP, p)
+			active = true
+		}
+		if p := edgePatternProbability(suffix, false, caseSensitive); p != nil {
+			totalP.Mul(totalP, p)
+			active = true
+		}
 	}
 	if p := containsPatternProbabilityApprox(contains, caseSensitive); p != nil {
+		if MinHexPatternLen(contains) > addressNibbles {
+			p = new(big.Rat)
+		}
 		totalP.Mul(totalP, p)
 		active = true
 	}
+	for _, ex := range exclude {
+		if p := containsPatternProbabilityApprox(ex, caseSensitive); p != nil {
+			totalP.Mul(totalP, new(big.Rat).Sub(big.NewRat(1, 1), p))
+			active = true
+		}
+	}
+
+	if !active {
+		return nil
+	}
+	return totalP
+}
+
+// overlapProbability handles prefix and suffix patterns too long to sit side
+// by side in an address body, where treating them as independent would
+// multiply probabilities for nibbles they share. When every pairing of their
+// alternatives needs more than 40 nibbles, each pairing that agrees where it
+// overlaps fixes the whole body, so the chance is 16^-40 per distinct body
+// (halved per letter when caseSensitive), and 0 when none agrees. ok is
+// false when some pairing fits without overlap and the independent estimate
+// applies.
+func overlapProbability(prefix, suffix string, caseSensitive bool) (p *big.Rat, ok bool) {
+	alts := func(pattern string) []string {
+		if !caseSensitive {
+			pattern = strings.ToLower(pattern)
+		}
+		a, err := compileHexPattern(pattern)
+		if err != nil || len(a) == 0 {
+			return []string{""}
+		}
+		return a
+	}
+	prefixAlts, suffixAlts := alts(prefix), alts(suffix)
+	for _, a := range prefixAlts {
+		for _, b := range suffixAlts {
+			if len(a)+len(b) <= addressNibbles {
+				return nil, false
+			}
+		}
+	}
+
+	bodies := make(map[string]struct{})
+	p = new(big.Rat)
+	for _, a := range prefixAlts {
+		for _, b := range suffixAlts {
+			if len(a) > addressNibbles || len(b) > addressNibbles {
+				continue
+			}
+			shared := len(a) + len(b) - addressNibbles
+			if a[len(a)-shared:] != b[:shared] {
+				continue
+			}
+			body := a + b[shared:]
+			if _, dup := bodies[body]; dup {
+				continue
+			}
+			bodies[body] = struct{}{}
+			q := new(big.Rat).SetFrac(big.NewInt(1), addressSpace)
+			if caseSensitive {
+				letters := 0
+				for i := 0; i < len(body); i++ {
+					if body[i] > '9' {
+						letters++
+					}
+				}
+				q.Mul(q, new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Lsh(big.NewInt(1), uint(letters))))
+			}
+			p.Add(p, q)
+		}
+	}
+	return p, true
+}
 
-	if !active || totalP.Sign() == 0 {
+// expectedAttempts converts a per-attempt match probability into the
+// expected number of attempts (~1/p). Returns nil for a nil or zero p.
+func expectedAttempts(p *big.Rat) *big.Int {
+	if p == nil || p.Sign() == 0 {
 		return nil
 	}
 
 	// expected attempts ~= 1 / probability
-	num := new(big.Int).Set(totalP.Num())
-	den := new(big.Int).Set(totalP.Denom())
+	num := new(big.Int).Set(p.Num())
+	den := new(big.Int).Set(p.Denom())
 	if num.Sign() == 0 {
 		return nil
 	}
@@ -89,6 +738,24 @@ func ValidateHexPattern(s string) error {
 	return err
 }
 
+// DecimalSuffix converts a decimal tag such as "1337" to the hex suffix an
+// address must end with to carry it: big-endian and zero-padded to whole
+// bytes, so 1337 (0x539) becomes "0539" and 255 becomes "ff". A leading
+// zero is needed for odd-length values because the tag is read as whole
+// trailing bytes; without it, "539" would also match an address ending in
+// ...f539, i.e. tag 62777.
+func DecimalSuffix(s string) (string, error) {
+	n, err := strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("%q is not a non-negative decimal number up to 2^64-1", s)
+	}
+	h := strconv.FormatUint(n, 16)
+	if len(h)%2 == 1 {
+		h = "0" + h
+	}
+	return h, nil
+}
+
 // MinHexPatternLen returns the shortest effective hex length in pattern.
 // Returns 0 for empty or invalid patterns.
 func MinHexPatternLen(pattern string) int {
@@ -98,16 +765,26 @@ func MinHexPatternLen(pattern string) int {
 
 // matchAlt returns true if check(haystack, alt) is true for any alternative.
 func matchAlt(haystack string, alts []string, check func(string, string) bool) bool {
+	_, ok := firstAlt(haystack, alts, check)
+	return ok
+}
+
+// firstAlt returns the first alternative for which check(haystack, alt) is true.
+func firstAlt(haystack string, alts []string, check func(string, string) bool) (string, bool) {
 	for _, alt := range alts {
 		if check(haystack, alt) {
-			return true
+			return alt, true
 		}
 	}
-	return false
+	return "", false
 }
 
-// BuildMatcher returns a match function for the given criteria.
-func BuildMatcher(prefix, suffix, contains string, re *regexp.Regexp, caseSensitive bool) func(string) bool {
+// BuildMatcher returns a match function for the given criteria. An address
+// containing any of the exclude patterns is rejected even if it matches the
+// rest. re is
+// matched against the full 0x address and reBody against the bare 40-char
+// body; when both are given, both must match.
+func BuildMatcher(prefix, suffix, contains string, re, reBody *regexp.Regexp, caseSensitive bool, exclude ...string) func(string) bool {
 	normalize := func(s string) string {
 		if caseSensitive {
 			return s
@@ -118,15 +795,23 @@ func BuildMatcher(prefix, suffix, contains string, re *regexp.Regexp, caseSensit
 	suffix = normalize(suffix)
 	contains = normalize(contains)
 	prefixAlts, _ := compileHexPattern(prefix)
+	prefixes := newPrefixTrie(prefixAlts)
 	suffixAlts, _ := compileHexPattern(suffix)
 	containsAlts, _ := compileHexPattern(contains)
+	var excludeAlts []string
+	for _, ex := range exclude {
+		alts, _ := compileHexPattern(normalize(ex))
+		excludeAlts = append(excludeAlts, alts...)
+	}
 
 	return func(addr string) bool {
 		a := normalize(addr)
 		bare := strings.TrimPrefix(a, "0x")
 
-		if len(prefixAlts) > 0 && !matchAlt(bare, prefixAlts, strings.HasPrefix) {
-			return false
+		if prefixes != nil {
+			if _, ok := prefixes.match(bare); !ok {
+				return false
+			}
 		}
 		if len(suffixAlts) > 0 && !matchAlt(bare, suffixAlts, strings.HasSuffix) {
 			return false
@@ -137,100 +822,640 @@ func BuildMatcher(prefix, suffix, contains string, re *regexp.Regexp, caseSensit
 		if re != nil && !re.MatchString(addr) {
 			return false
 		}
+		if reBody != nil && !reBody.MatchString(bare) {
+			return false
+		}
+		if len(excludeAlts) > 0 && matchAlt(bare, excludeAlts, strings.Contains) {
+			return false
+		}
 		return true
 	}
 }
 
+// configMatcher combines every criterion in cfg into one match function. The
+// returned tag is the alternative to report in Result.Match, if any.
+func configMatcher(cfg Config) func(string) (string, bool) {
+	var re, reBody *regexp.Regexp
+	if cfg.Regex != "" {
+		re, _ = regexp.Compile(cfg.Regex)
+	}
+	if cfg.RegexBody != "" {
+		reBody, _ = regexp.Compile(cfg.RegexBody)
+	}
+	var matcher func(string) bool
+	if isBase58Chain(cfg.Chain) {
+		matcher = Base58Matcher(cfg.Prefix, cfg.Suffix, cfg.Contains, re, cfg.CaseSensitive)
+	} else {
+		matcher = BuildMatcher(cfg.Prefix, cfg.Suffix, cfg.Contains, re, reBody, cfg.CaseSensitive, cfg.Exclude...)
+	}
+	if cfg.Near != "" {
+		near, base := NearMatcher(cfg.Near, cfg.MaxDistance), matcher
+		matcher = func(addr string) bool { return near(addr) && base(addr) }
+	}
+	if cfg.Mod != nil {
+		r := cfg.Remainder
+		if r == nil {
+			r = new(big.Int)
+		}
+		mod, base := ModMatcher(cfg.Mod, r), matcher
+		matcher = func(addr string) bool { return base(addr) && mod(addr) }
+	}
+	match := func(addr string) (string, bool) { return "", matcher(addr) }
+	// Once Run has compiled cfg.patterns, it matches them itself, after
+	// the rest: see patternMatch.
+	if len(cfg.Patterns) > 0 && cfg.patterns == nil {
+		set, base := newPatternSet(cfg), matcher
+		match = func(addr string) (string, bool) {
+			if !base(addr) {
+				return "", false
+			}
+			if i := set.first(addr); i >= 0 {
+				return set.ids[i], true
+			}
+			return "", false
+		}
+	}
+	if len(cfg.Words) > 0 {
+		words, base := WordMatcher(cfg.Words, cfg.CaseSensitive), match
+		match = func(addr string) (string, bool) {
+			if _, ok := base(addr); !ok {
+				return "", false
+			}
+			return words(addr)
+		}
+	}
+	if len(cfg.Prefixes) > 0 {
+		prefixes, base := PrefixMatcher(cfg.Prefixes, cfg.CaseSensitive), match
+		match = func(addr string) (string, bool) {
+			tag, ok := base(addr)
+			if !ok {
+				return "", false
+			}
+			prefix, ok := prefixes(addr)
+			if !ok {
+				return "", false
+			}
+			if tag != "" {
+				prefix = tag + "+" + prefix
+			}
+			return prefix, true
+		}
+	}
+	if cfg.MinScore > 0 {
+		score, base := cfg.Scorer, match
+		if score == nil {
+			score = scoring.Score
+		}
+		match = func(addr string) (string, bool) {
+			tag, ok := base(addr)
+			if !ok {
+				return "", false
+			}
+			n := score(addr)
+			if n < cfg.MinScore {
+				return "", false
+			}
+			if tag != "" {
+				return fmt.Sprintf("%s+score=%d", tag, n), true
+			}
+			return fmt.Sprintf("score=%d", n), true
+		}
+	}
+	if len(cfg.Spellings) > 0 {
+		spells, base := WordMatcher(cfg.Spellings, cfg.CaseSensitive), match
+		match = func(addr string) (string, bool) {
+			tag, ok := base(addr)
+			if !ok {
+				return "", false
+			}
+			spelling, ok := spells(addr)
+			if !ok {
+				return "", false
+			}
+			if tag != "" {
+				spelling = tag + "+" + spelling
+			}
+			return spelling, true
+		}
+	}
+	if cfg.Invert {
+		inner := match
+		match = func(addr string) (string, bool) {
+			_, ok := inner(addr)
+			return "", !ok
+		}
+	}
+	if len(cfg.Blocklist) > 0 {
+		clean, base := BuildMatcher("", "", "", nil, nil, false, cfg.Blocklist...), match
+		match = func(addr string) (string, bool) {
+			if !clean(addr) {
+				return "", false
+			}
+			return base(addr)
+		}
+	}
+	return match
+}
+
+// sendGrace is how long a worker keeps trying to deliver a result it found
+// after the context is cancelled.
+const sendGrace = time.Second
+
 // Run starts a worker pool that searches for addresses matching cfg.
-// Results are sent to resultCh (buffered with cfg.Count capacity).
+// Results are sent to resultCh (buffered with cfg.Count capacity). With a
+// Count of 0 every match is sent until ctx is cancelled. With a Count above
+// 1 each address is sent at most once: a repeat, which only a broken or
+// deterministic cfg.Rand makes likely, is counted in stats.Duplicates and
+// does not take a slot. With cfg.Keys, Run also stops once that is
+// exhausted.
 // Stats are updated atomically throughout. resultCh is closed when all
-// workers exit (either context cancelled or count reached).
+// workers exit: the worker that delivers the last of cfg.Count results stops
+// the rest at once, and they also stop when ctx is cancelled. A result found
+// just as ctx is cancelled is still delivered if the consumer reads it
+// within sendGrace.
+//
+// A worker that panics is restarted and the panic counted in stats.Panics.
+// If key generation fails maxKeyFailures times in a row, or workers panic
+// maxPanics times, Run stops all workers and records the error in
+// stats.Err.
+//
+// The search itself runs on cfg.Accelerator, the CPU worker pool unless
+// set otherwise. The pool grows and shrinks as cfg.Scaler is set.
 func Run(ctx context.Context, cfg Config, resultCh chan<- Result, stats *Stats) {
-	var re *regexp.Regexp
-	if cfg.Regex != "" {
-		re, _ = regexp.Compile(cfg.Regex)
+	if err := ValidateKeyType(cfg.KeyType); err != nil {
+		stats.setErr(err)
+		close(resultCh)
+		return
+	}
+	if len(cfg.Patterns) > 0 && !cfg.Invert {
+		cfg.patterns = newPatternSet(cfg)
+	}
+	pattern := patternMatch(cfg.patterns, newMatcher(cfg))
+	match := func(addr string) (string, bool) {
+		tag, _, ok := pattern(addr)
+		return tag, ok
+	}
+	filter := prefixFilter(cfg)
+	if cfg.Avoid != nil {
+		avoid, base := cfg.Avoid, filter
+		filter = func(addr []byte) bool {
+			return !avoid.Has(addr) && (base == nil || base(addr))
+		}
+	}
+	if mod := modFilter(cfg); mod != nil {
+		base := filter
+		filter = func(addr []byte) bool {
+			return (base == nil || base(addr)) && mod(addr)
+		}
+	}
+	if cfg.Brainwallet != "" && cfg.salts == nil {
+		cfg.salts = new(atomic.Uint64)
+	}
+	if cfg.XPub != nil && cfg.xpubNext == nil {
+		cfg.xpubNext = new(atomic.Uint64)
+	}
+	if cfg.CountPerAlt > 0 {
+		cfg.prefixQuota = newPrefixQuota(cfg)
+		cfg.Count = cfg.CountPerAlt * len(cfg.prefixQuota.alts)
+	}
+	if cfg.Count > 1 && cfg.seen == nil {
+		cfg.seen = &seenSet{addrs: make(map[common.Address]struct{})}
+	}
+	if cfg.Fast {
+		stats.startWalks(cfg.Workers, cfg.ResumeFrom)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if cfg.Keys != nil {
+		cfg.keys = newKeyStream(ctx, cfg.Keys)
 	}
-	matcher := BuildMatcher(cfg.Prefix, cfg.Suffix, cfg.Contains, re, cfg.CaseSensitive)
 
-	var wg sync.WaitGroup
-	for i := 0; i < cfg.Workers; i++ {
-		wg.Add(1)
+	var progress sync.WaitGroup
+	if cfg.OnProgress != nil {
+		progress.Add(1)
 		go func() {
-			defer wg.Done()
-			for {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-				}
+			defer progress.Done()
+			reportProgress(ctx, cfg.OnProgress, stats)
+		}()
+	}
 
-				if int(stats.Found.Load()) >= cfg.Count {
-					return
-				}
+	acc := cfg.Accelerator
+	if acc == nil {
+		acc = CPU
+	}
+	acc.Search(ctx, cancel, cfg, Matcher{Filter: filter, Match: match, pattern: pattern}, resultCh, stats)
+	cancel()
+	progress.Wait()
+	close(resultCh)
+}
+
+// Search runs Run to completion and returns what it found, for callers
+// that have no use for results as they come in, such as tests. stats may
+// be nil; the error is stats.Err. With a Count of 0 Search returns only once
+// ctx is cancelled.
+func Search(ctx context.Context, cfg Config, stats *Stats) ([]Result, error) {
+	if stats == nil {
+		stats = &Stats{}
+	}
+	resultCh := make(chan Result, cfg.Count)
+	go Run(ctx, cfg, resultCh, stats)
+	var results []Result
+	for r := range resultCh {
+		results = append(results, r)
+	}
+	return results, stats.Err()
+}
+
+// progressStep is how many candidates pass between Config.OnProgress calls,
+// and progressPoll how often Run checks whether they have.
+const (
+	progressStep = 100_000
+	progressPoll = 50 * time.Millisecond
+)
+
+// reportProgress calls onProgress whenever stats.Total has crossed another
+// multiple of progressStep, until ctx is cancelled. Several steps crossed
+// between polls are reported once.
+func reportProgress(ctx context.Context, onProgress func(total, found int64), stats *Stats) {
+	t := time.NewTicker(progressPoll)
+	defer t.Stop()
+	var reported int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if ctx.Err() != nil {
+				return // the search is over; Run is waiting
+			}
+			total := stats.Total.Load()
+			if total/progressStep > reported/progressStep {
+				reported = total
+				onProgress(total, stats.Found.Load())
+			}
+		}
+	}
+}
+
+// newMatcher builds the match function Run uses; tests swap it out.
+var newMatcher = configMatcher
+
+// supervise runs work until it returns normally, restarting it whenever it
+// panics so one bad candidate cannot end a long search. Each panic is
+// counted in stats.Panics; after maxPanics the search is aborted with an
+// error wrapping ErrWorkerPanics.
+func supervise(ctx context.Context, cancel context.CancelFunc, stats *Stats, work func()) {
+	for recovered(stats, work) {
+		if stats.Panics.Load() >= maxPanics {
+			stats.setErr(fmt.Errorf("%w: last: %s", ErrWorkerPanics, stats.LastPanic()))
+			cancel()
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
 
-				key, err := crypto.GenerateKey()
-				if err != nil {
+// recovered calls work and reports whether it panicked.
+func recovered(stats *Stats, work func()) (panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			stats.recordPanic(r)
+			panicked = true
+		}
+	}()
+	work()
+	return false
+}
+
+// runWorker is the body of one search worker. It returns when ctx is
+// cancelled, all result slots are taken, Config.Keys runs out or fails, or
+// key generation keeps failing.
+// filter, if not nil, rejects candidates from their raw bytes before they
+// are hex-encoded for match.
+func runWorker(ctx context.Context, cancel context.CancelFunc, cfg Config, filter func([]byte) bool, match func(string) (string, int, bool), resultCh chan<- Result, stats *Stats) {
+	gen := newKeyGen(cfg.Rand, cfg.KeyType)
+	batch := new(keyBatch)
+	defer gen.wipe(batch)
+	fill := gen.filler(cfg)
+	enc := encoderFor(cfg.Chain)
+	// checked counts the candidates of the current batch already tried, so
+	// a Fast walk can record exactly where it stopped. A restarted worker
+	// picks its walk up from there.
+	checked := 0
+	if cfg.Fast {
+		if key := stats.walk(cfg.worker); key != nil {
+			gen.seedWalk(key)
+		}
+		defer func() {
+			if key := gen.walkPosition(batch.n - checked); key != nil {
+				stats.setWalk(cfg.worker, key)
+			}
+		}()
+	}
+	for {
+		if err := fill(batch); err != nil {
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if errors.Is(err, ErrKeyStream) {
+				stats.setErr(err)
+				cancel()
+				return
+			}
+			if stats.KeyFailures.Add(1) >= maxKeyFailures {
+				stats.setErr(fmt.Errorf("%w: %v", ErrEntropy, err))
+				cancel()
+				return
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		stats.KeyFailures.Store(0)
+
+		checked = 0
+		for i := 0; i < batch.n; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+
+			if cfg.MaxAttempts > 0 && stats.Total.Load() >= cfg.MaxAttempts {
+				cancel()
+				return
+			}
+			stats.Total.Add(1)
+			checked = i + 1
+			if cfg.Audit {
+				stats.Nibbles[batch.addr[i][0]>>4].Add(1)
+			}
+			if filter != nil && !filter(batch.addr[i][:]) {
+				continue
+			}
+
+			addr := enc.encode(&batch.pub[i], batch.addr[i], cfg.CaseSensitive)
+			if tag, p, ok := match(addr); ok {
+				if cfg.RejectWeak && cfg.XPub == nil && isWeakScalar(&batch.priv[i]) {
+					stats.Weak.Add(1)
+					continue
+				}
+				if cfg.seen != nil && !cfg.seen.add(batch.addr[i]) {
+					stats.Duplicates.Add(1)
 					continue
 				}
-				stats.Total.Add(1)
-
-				addr := addressFromKey(key, cfg.CaseSensitive)
-				if matcher(addr) {
-					n := stats.Found.Add(1)
-					if int(n) <= cfg.Count {
-						select {
-						case resultCh <- Result{
-							Address:    addr,
-							PrivateKey: privateKeyHex(key),
-						}:
-						case <-ctx.Done():
-							return
+				// With Patterns, slots are per pattern; once any pattern
+				// has all of its results the search is over.
+				slots, pattern := &stats.Found, ""
+				if p >= 0 {
+					slots, pattern = &cfg.patterns.found[p], cfg.patterns.ids[p]
+				}
+				// With CountPerAlt, the alternative's own quota decides;
+				// the total in Found then cannot run out first.
+				if cfg.prefixQuota != nil {
+					i := cfg.prefixQuota.claim(addr, cfg.CountPerAlt)
+					if i < 0 {
+						continue
+					}
+					pattern = cfg.prefixQuota.alts[i]
+				}
+				last, ok := claimSlot(slots, cfg.Count)
+				if !ok {
+					return
+				}
+				if slots != &stats.Found {
+					stats.Found.Add(1)
+				}
+				r := Result{
+					Address:  addr,
+					Checksum: enc.canonical(&batch.pub[i], batch.addr[i]),
+					Match:    tag,
+					Mnemonic: batch.mnemonic[i],
+					Pattern:  pattern,
+				}
+				if cfg.XPub != nil {
+					r.XPub, r.ChildIndex = cfg.XPub.String(), batch.index[i]
+				} else {
+					r.PrivateKey = PrivateKey(bytes.Clone(batch.priv[i][:]))
+				}
+				if cfg.PublicKey != "" {
+					r.PublicKey = encodePublicKey(&batch.pub[i], cfg.PublicKey)
+				}
+				if cfg.Mnemonic {
+					r.DerivationPath = derivationPath(cfg)
+				}
+				if cfg.Brainwallet != "" {
+					r.Passphrase, r.Salt = cfg.Brainwallet, batch.salt[i]
+				}
+				select {
+				case resultCh <- r:
+					if last {
+						// That was the final slot: stop the other workers
+						// now instead of letting each of them find out on
+						// its next match.
+						cancel()
+						return
+					}
+				case <-ctx.Done():
+					// Cancelled while sending: the result is already
+					// counted in Found, so give the consumer a short grace
+					// window to drain it instead of dropping it.
+					// A scaled-down worker gets here with only its own ctx
+					// cancelled, so the last slot still ends the search.
+					select {
+					case resultCh <- r:
+						if last {
+							cancel()
 						}
+					case <-time.After(sendGrace):
 					}
+					return
 				}
 			}
-		}()
+		}
 	}
+}
+
+// derivationPath returns the path mnemonic mode derives keys at for cfg,
+// normalized to ' notation.
+func derivationPath(cfg Config) string {
+	path, err := ParseDerivationPath(cfg.DerivationPath)
+	if err != nil {
+		return DefaultDerivationPath
+	}
+	return FormatDerivationPath(path)
+}
 
+// seenSet records addresses across workers.
+type seenSet struct {
+	mu    sync.Mutex
+	addrs map[common.Address]struct{}
+}
+
+// add records addr and reports whether it was new.
+func (s *seenSet) add(addr common.Address) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.addrs[addr]; ok {
+		return false
+	}
+	s.addrs[addr] = struct{}{}
+	return true
+}
+
+// claimSlot reserves one of count result slots by incrementing found, and
+// reports ok=false once all slots are taken. Unlike a plain Add, found never
+// exceeds count, so no more than count results are ever sent however many
+// workers match at the same moment. last is true for exactly one caller: the
+// one that took the final slot. A count of 0 never runs out of slots.
+func claimSlot(found *atomic.Int64, count int) (last, ok bool) {
+	if count == 0 {
+		found.Add(1)
+		return false, true
+	}
+	for {
+		n := found.Load()
+		if n >= int64(count) {
+			return false, false
+		}
+		if found.CompareAndSwap(n, n+1) {
+			return n+1 == int64(count), true
+		}
+	}
+}
+
+// MeasureRate runs cfg.Workers key-generation workers (honouring cfg.Fast)
+// for d and returns the observed attempts per second. Nothing is matched or
+// kept; it is meant for pre-flight estimates.
+func MeasureRate(cfg Config, d time.Duration) float64 {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+
+	var total atomic.Int64
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gen := newKeyGen(cfg.Rand, cfg.KeyType)
+			batch := new(keyBatch)
+			defer gen.wipe(batch)
+			fill := gen.filler(cfg)
+			for ctx.Err() == nil {
+				if err := fill(batch); err != nil {
+					return
+				}
+				total.Add(int64(batch.n))
+			}
+		}()
+	}
 	wg.Wait()
-	close(resultCh)
+	return float64(total.Load()) / time.Since(start).Seconds()
+}
+
+// Public key encodings accepted by Config.PublicKey.
+const (
+	PublicKeyUncompressed = "uncompressed" // 04, X, Y
+	PublicKeyCompressed   = "compressed"   // 02 or 03 by the parity of Y, X
+)
+
+// encodePublicKey returns the uncompressed public key pub (X then Y) as hex
+// in the SEC1 encoding format names.
+func encodePublicKey(pub *[64]byte, format string) string {
+	if format == PublicKeyCompressed {
+		var b [33]byte
+		b[0] = 0x02 | pub[63]&1
+		copy(b[1:], pub[:32])
+		return hex.EncodeToString(b[:])
+	}
+	var b [65]byte
+	b[0] = 0x04
+	copy(b[1:], pub[:])
+	return hex.EncodeToString(b[:])
 }
 
 func addressFromKey(key *ecdsa.PrivateKey, caseSensitive bool) string {
-	addr := crypto.PubkeyToAddress(key.PublicKey)
+	return formatAddress(crypto.PubkeyToAddress(key.PublicKey), caseSensitive)
+}
+
+// formatAddress renders addr in checksummed form when caseSensitive is set,
+// and in lowercase otherwise.
+func formatAddress(addr common.Address, caseSensitive bool) string {
 	if caseSensitive {
 		return addr.Hex()
 	}
 	return strings.ToLower(addr.Hex())
 }
 
+// ChecksumAddress returns the EIP-55 mixed-case form of a hex address.
+func ChecksumAddress(addr string) string {
+	return common.HexToAddress(addr).Hex()
+}
+
 func privateKeyHex(key *ecdsa.PrivateKey) string {
 	return hex.EncodeToString(crypto.FromECDSA(key))
 }
 
+// Errors returned by ValidateHexPattern, possibly wrapped with where in the
+// pattern they were found; test for them with errors.Is. An invalid
+// character is reported as an *InvalidCharError, which matches
+// ErrInvalidChar.
+var (
+	ErrEmptyPattern     = errors.New("pattern is empty")
+	ErrEmptyAlternative = errors.New("empty alternative near '|'")
+	ErrEmptyGroup       = errors.New("empty group '()'")
+	ErrUnclosedGroup    = errors.New("unclosed '('")
+	ErrUnopenedGroup    = errors.New("unexpected ')'")
+	ErrInvalidChar      = errors.New("invalid character")
+)
+
+// InvalidCharError reports a character a hex pattern cannot contain.
+type InvalidCharError struct {
+	Char byte
+	// Offset is the byte offset of Char in the pattern as given.
+	Offset int
+	// InGroup is set when Char is inside a (…) group.
+	InGroup bool
+}
+
+func (e *InvalidCharError) Error() string {
+	if e.InGroup {
+		return fmt.Sprintf("invalid character %q in group at offset %d", e.Char, e.Offset)
+	}
+	return fmt.Sprintf("invalid character %q at offset %d (allowed: 0-9, a-f, |, (, ), optional x/0x prefix)", e.Char, e.Offset)
+}
+
+func (e *InvalidCharError) Unwrap() error { return ErrInvalidChar }
+
 func compileHexPattern(pattern string) ([]string, error) {
 	s := strings.TrimSpace(pattern)
 	if s == "" {
 		return nil, nil
 	}
+	// off is where s starts in pattern, so errors can point into it.
+	off := strings.Index(pattern, s)
 	if len(s) >= 2 && (s[0] == '0') && (s[1] == 'x' || s[1] == 'X') {
-		s = s[2:]
+		s, off = s[2:], off+2
 	} else if len(s) >= 1 && (s[0] == 'x' || s[0] == 'X') {
-		s = s[1:]
+		s, off = s[1:], off+1
 	}
 	if s == "" {
-		return nil, fmt.Errorf("pattern is empty")
+		return nil, ErrEmptyPattern
 	}
 
-	branches, err := splitTopLevel(s)
+	branches, starts, err := splitTopLevel(s, off)
 	if err != nil {
 		return nil, err
 	}
 
 	seen := make(map[string]struct{}, len(branches))
 	var all []string
-	for _, branch := range branches {
-		expanded, err := expandBranch(branch)
+	for i, branch := range branches {
+		expanded, err := expandBranch(branch, starts[i])
 		if err != nil {
 			return nil, err
 		}
@@ -243,47 +1468,50 @@ func compileHexPattern(pattern string) ([]string, error) {
 		}
 	}
 	if len(all) == 0 {
-		return nil, fmt.Errorf("pattern is empty")
+		return nil, ErrEmptyPattern
 	}
 	return all, nil
 }
 
-func splitTopLevel(s string) ([]string, error) {
-	var parts []string
+// splitTopLevel splits s at the | outside any group. off is where s starts
+// in the pattern; starts holds where each part does.
+func splitTopLevel(s string, off int) (parts []string, starts []int, err error) {
 	start := 0
-	depth := 0
+	var open []int // offsets of the groups still open
 	for i := 0; i < len(s); i++ {
 		switch s[i] {
 		case '(':
-			depth++
+			open = append(open, i)
 		case ')':
-			depth--
-			if depth < 0 {
-				return nil, fmt.Errorf("unexpected ')'")
+			if len(open) == 0 {
+				return nil, nil, fmt.Errorf("%w at offset %d", ErrUnopenedGroup, off+i)
 			}
+			open = open[:len(open)-1]
 		case '|':
-			if depth == 0 {
+			if len(open) == 0 {
 				part := s[start:i]
 				if part == "" {
-					return nil, fmt.Errorf("empty alternative near '|'")
+					return nil, nil, fmt.Errorf("%w at offset %d", ErrEmptyAlternative, off+i)
 				}
-				parts = append(parts, part)
+				parts, starts = append(parts, part), append(starts, off+start)
 				start = i + 1
 			}
 		}
 	}
-	if depth != 0 {
-		return nil, fmt.Errorf("unclosed '('")
+	if len(open) > 0 {
+		return nil, nil, fmt.Errorf("%w at offset %d", ErrUnclosedGroup, off+open[len(open)-1])
 	}
 	last := s[start:]
 	if last == "" {
-		return nil, fmt.Errorf("empty alternative near '|'")
+		return nil, nil, fmt.Errorf("%w at offset %d", ErrEmptyAlternative, off+len(s)-1)
 	}
-	parts = append(parts, last)
-	return parts, nil
+	parts, starts = append(parts, last), append(starts, off+start)
+	return parts, starts, nil
 }
 
-func expandBranch(branch string) ([]string, error) {
+// expandBranch lists every string branch stands for. off is where branch
+// starts in the pattern.
+func expandBranch(branch string, off int) ([]string, error) {
 	alts := []string{""}
 	for i := 0; i < len(branch); {
 		switch c := branch[i]; {
@@ -295,39 +1523,41 @@ func expandBranch(branch string) ([]string, error) {
 			alts = appendSegment(alts, []string{branch[i:j]})
 			i = j
 		case c == '(':
-			end, err := findGroupEnd(branch, i)
+			end, err := findGroupEnd(branch, i, off)
 			if err != nil {
 				return nil, err
 			}
 			inner := branch[i+1 : end]
 			if inner == "" {
-				return nil, fmt.Errorf("empty group '()'")
+				return nil, fmt.Errorf("%w at offset %d", ErrEmptyGroup, off+i)
 			}
-			groupAlts, err := splitTopLevel(inner)
+			groupAlts, groupStarts, err := splitTopLevel(inner, off+i+1)
 			if err != nil {
 				return nil, err
 			}
-			for _, ga := range groupAlts {
+			for k, ga := range groupAlts {
 				for j := 0; j < len(ga); j++ {
 					if !isHex(ga[j]) {
-						return nil, fmt.Errorf("invalid character %q in group", ga[j])
+						return nil, &InvalidCharError{Char: ga[j], Offset: groupStarts[k] + j, InGroup: true}
 					}
 				}
 			}
 			alts = appendSegment(alts, groupAlts)
 			i = end + 1
 		case c == ')':
-			return nil, fmt.Errorf("unexpected ')'")
+			return nil, fmt.Errorf("%w at offset %d", ErrUnopenedGroup, off+i)
 		case c == '|':
-			return nil, fmt.Errorf("unexpected '|'")
+			return nil, fmt.Errorf("%w at offset %d", ErrEmptyAlternative, off+i)
 		default:
-			return nil, fmt.Errorf("invalid character %q (allowed: 0-9, a-f, |, (, ), optional x/0x prefix)", c)
+			return nil, &InvalidCharError{Char: c, Offset: off + i}
 		}
 	}
 	return alts, nil
 }
 
-func findGroupEnd(s string, start int) (int, error) {
+// findGroupEnd returns the index of the ')' closing the group that opens at
+// s[start]. off is where s starts in the pattern.
+func findGroupEnd(s string, start, off int) (int, error) {
 	depth := 0
 	for i := start; i < len(s); i++ {
 		switch s[i] {
@@ -340,7 +1570,7 @@ func findGroupEnd(s string, start int) (int, error) {
 			}
 		}
 	}
-	return -1, fmt.Errorf("unclosed '('")
+	return -1, fmt.Errorf("%w at offset %d", ErrUnclosedGroup, off+start)
 }
 
 func appendSegment(prefixes []string, segment []string) []string {
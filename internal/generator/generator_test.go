@@ -1,9 +1,33 @@
 package generator
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	cryptorand "crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"testing/iotest"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
@@ -14,8 +38,213 @@ func TestValidateHexPattern_GroupedAlternation(t *testing.T) {
 	}
 }
 
+func TestRun_BlocklistRejectsInEitherCase(t *testing.T) {
+	cfg := Config{Workers: 2, Count: 50, Contains: "a", Blocklist: []string{"a5", "B"}, CaseSensitive: true}
+	resultCh := make(chan Result, cfg.Count)
+	Run(context.Background(), cfg, resultCh, &Stats{})
+	for r := range resultCh {
+		if bare := strings.ToLower(r.Address[2:]); strings.Contains(bare, "a5") || strings.Contains(bare, "b") {
+			t.Fatalf("%s contains a blocklisted word", r.Address)
+		}
+	}
+
+	// The blocklist still applies when the rest of the search is inverted.
+	cfg = Config{Workers: 1, Count: 20, Prefix: "0", Invert: true, Blocklist: []string{"f"}}
+	resultCh = make(chan Result, cfg.Count)
+	Run(context.Background(), cfg, resultCh, &Stats{})
+	for r := range resultCh {
+		if strings.Contains(strings.ToLower(r.Address), "f") {
+			t.Fatalf("inverted search returned %s despite the blocklist", r.Address)
+		}
+	}
+	if d := Difficulty(Config{Prefix: "00", Blocklist: []string{"ff"}}); d.Int64() <= 256 {
+		t.Fatalf("Difficulty with a blocklist = %s, want above 256", d)
+	}
+}
+
+func TestRun_ModMatchesRemainder(t *testing.T) {
+	mod, rem, err := ParseMod("7", "3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{Workers: 2, Count: 20, Mod: mod, Remainder: rem}
+	resultCh := make(chan Result, cfg.Count)
+	stats := &Stats{}
+	Run(context.Background(), cfg, resultCh, stats)
+	n := 0
+	for r := range resultCh {
+		v, _ := new(big.Int).SetString(r.Address[2:], 16)
+		if v.Mod(v, mod).Int64() != 3 {
+			t.Fatalf("%s mod 7 = %s, want 3", r.Address, v)
+		}
+		n++
+	}
+	if n != cfg.Count {
+		t.Fatalf("got %d results, want %d", n, cfg.Count)
+	}
+
+	// Inverted, the raw-bytes filter must step aside: every other
+	// remainder matches.
+	cfg.Invert = true
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	inverted, err := Search(ctx, cfg, nil)
+	if err != nil || len(inverted) != cfg.Count {
+		t.Fatalf("inverted search: %d results, %v", len(inverted), err)
+	}
+	for _, r := range inverted {
+		v, _ := new(big.Int).SetString(r.Address[2:], 16)
+		if v.Mod(v, mod).Int64() == 3 {
+			t.Fatalf("inverted search found %s, which leaves 3", r.Address)
+		}
+	}
+
+	// Combined with a prefix, the odds multiply; a nil Remainder means 0.
+	if d := Difficulty(Config{Prefix: "ab", Mod: big.NewInt(4)}); d.Int64() != 1024 {
+		t.Fatalf("Difficulty = %s, want 1024", d)
+	}
+	match := ModMatcher(big.NewInt(256), big.NewInt(0x2a))
+	if !match("0x000000000000000000000000000000000000002A") || match("0x000000000000000000000000000000000000002b") {
+		t.Fatal("ModMatcher disagrees with the last byte for mod 256")
+	}
+}
+
+func TestModCheck_MatchesBigIntWithoutAllocating(t *testing.T) {
+	moduli := []*big.Int{
+		big.NewInt(1), big.NewInt(7), big.NewInt(256), big.NewInt(1_000_003),
+		new(big.Int).SetUint64(math.MaxUint64),
+		new(big.Int).Lsh(big.NewInt(1), 64),
+		new(big.Int).Sub(addressSpace, big.NewInt(1)),
+		addressSpace,
+	}
+	var addr [20]byte
+	for _, n := range moduli {
+		for i := 0; i < 200; i++ {
+			cryptorand.Read(addr[:])
+			r := new(big.Int).Mod(new(big.Int).SetBytes(addr[:]), n)
+			if !newModCheck(n, r).has(addr[:]) {
+				t.Fatalf("mod %s: %x does not leave %s", n, addr, r)
+			}
+			if other := new(big.Int).Mod(new(big.Int).Add(r, big.NewInt(1)), n); other.Cmp(r) != 0 && newModCheck(n, other).has(addr[:]) {
+				t.Fatalf("mod %s: %x also leaves %s", n, addr, other)
+			}
+			if !ModMatcher(n, r)("0x" + hex.EncodeToString(addr[:])) {
+				t.Fatalf("ModMatcher mod %s rejects %x", n, addr)
+			}
+		}
+	}
+	small, wide := newModCheck(big.NewInt(97), big.NewInt(3)), newModCheck(addressSpace, big.NewInt(3))
+	match := ModMatcher(big.NewInt(97), big.NewInt(3))
+	hexAddr := "0x" + hex.EncodeToString(addr[:])
+	if allocs := testing.AllocsPerRun(100, func() { small.has(addr[:]); wide.has(addr[:]); match(hexAddr) }); allocs > 0 {
+		t.Fatalf("mod check allocates %.1f times a call", allocs)
+	}
+}
+
+func TestParseMod_Bounds(t *testing.T) {
+	for _, tc := range []struct{ mod, rem string }{
+		{"0", "0"}, {"-3", "0"}, {"5", "5"}, {"5", "-1"}, {"x", "0"}, {"5", "y"},
+		{"0x10000000000000000000000000000000000000001", "0"},
+	} {
+		if _, _, err := ParseMod(tc.mod, tc.rem); err == nil {
+			t.Errorf("ParseMod(%q, %q) succeeded", tc.mod, tc.rem)
+		}
+	}
+	n, r, err := ParseMod("0x100", "")
+	if err != nil || n.Int64() != 256 || r.Sign() != 0 {
+		t.Fatalf("ParseMod(0x100, \"\") = %v, %v, %v", n, r, err)
+	}
+}
+
+func TestEstimate_KnownPatterns(t *testing.T) {
+	e, ok := Estimate(Config{Prefix: "dead", Count: 2}, 65536, 2*time.Second)
+	if !ok {
+		t.Fatal("Estimate reported nothing to estimate")
+	}
+	if e.Difficulty.Int64() != 65536 || e.ExpectedAttempts.Int64() != 131072 || e.ETA != 2*time.Second {
+		t.Fatalf("Estimate = %+v, want 65536, 131072 and 2s", e)
+	}
+	// λ = 2: P(X >= 2) = 1 - 3e^-2.
+	if want := 1 - 3*math.Exp(-2); math.Abs(e.ProbabilityInTimeout-want) > 1e-9 {
+		t.Fatalf("ProbabilityInTimeout = %v, want %v", e.ProbabilityInTimeout, want)
+	}
+
+	e, _ = Estimate(Config{Prefix: "dead", Suffix: "Beef", CaseSensitive: true, Count: 1}, 1e6, 0)
+	if want := new(big.Int).Lsh(big.NewInt(1), 32+8); e.Difficulty.Cmp(want) != 0 || e.ProbabilityInTimeout != 0 {
+		t.Fatalf("case-sensitive Estimate = %+v, want difficulty %s and no odds", e, want)
+	}
+	if _, ok := Estimate(Config{Regex: "^0xdead"}, 1e6, time.Hour); ok {
+		t.Fatal("Estimate of a regex reported a result")
+	}
+}
+
+func TestDifficultyWarning(t *testing.T) {
+	cases := []struct {
+		prefix        string
+		caseSensitive bool
+		warn          bool
+	}{
+		{"deadbeef", true, false},
+		{strings.Repeat("0", 31), false, false},
+		{strings.Repeat("0", 32), false, true},
+		{strings.Repeat("DeadBeef", 5), true, true},
+	}
+	for _, tc := range cases {
+		d := HexDifficulty(tc.prefix, "", "", tc.caseSensitive)
+		if got := DifficultyWarning(d) != ""; got != tc.warn {
+			t.Errorf("DifficultyWarning(%s) for %q: warned = %v, want %v", d, tc.prefix, got, tc.warn)
+		}
+	}
+	if w := DifficultyWarning(HexDifficulty(strings.Repeat("DeadBeef", 5), "", "", true)); !strings.Contains(w, "no address") {
+		t.Errorf("a pattern rarer than the address space got %q", w)
+	}
+}
+
+func TestValidateHexPattern_TypedErrors(t *testing.T) {
+	cases := []struct {
+		pattern string
+		want    error
+	}{
+		{"0x", ErrEmptyPattern},
+		{"dead||beef", ErrEmptyAlternative},
+		{"dead|", ErrEmptyAlternative},
+		{"de()ad", ErrEmptyGroup},
+		{"(de|ad", ErrUnclosedGroup},
+		{"dead)", ErrUnopenedGroup},
+		{"deag", ErrInvalidChar},
+		{"(0|g)", ErrInvalidChar},
+	}
+	for _, tc := range cases {
+		if err := ValidateHexPattern(tc.pattern); !errors.Is(err, tc.want) {
+			t.Errorf("ValidateHexPattern(%q) = %v, want %v", tc.pattern, err, tc.want)
+		}
+	}
+}
+
+func TestValidateHexPattern_InvalidCharOffset(t *testing.T) {
+	cases := []struct {
+		pattern string
+		char    byte
+		offset  int
+		inGroup bool
+	}{
+		{"deag", 'g', 3, false},
+		{" 0xdead|bzef", 'z', 9, false},
+		{"ff(00|1q)", 'q', 7, true},
+	}
+	for _, tc := range cases {
+		var bad *InvalidCharError
+		if err := ValidateHexPattern(tc.pattern); !errors.As(err, &bad) {
+			t.Fatalf("ValidateHexPattern(%q) = %v, want *InvalidCharError", tc.pattern, err)
+		}
+		if bad.Char != tc.char || bad.Offset != tc.offset || bad.InGroup != tc.inGroup {
+			t.Errorf("ValidateHexPattern(%q) = %+v, want %q at %d (in group %v)", tc.pattern, *bad, tc.char, tc.offset, tc.inGroup)
+		}
+	}
+}
+
 func TestBuildMatcher_GroupedPrefix(t *testing.T) {
-	matcher := BuildMatcher("x(a|b|c)(10|20|30|40|50)", "", "", nil, false)
+	matcher := BuildMatcher("x(a|b|c)(10|20|30|40|50)", "", "", nil, nil, false)
 
 	if !matcher("0xa10aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa") {
 		t.Fatalf("expected grouped prefix pattern to match")
@@ -25,8 +254,69 @@ func TestBuildMatcher_GroupedPrefix(t *testing.T) {
 	}
 }
 
+func TestBuildMatcher_RegexBody(t *testing.T) {
+	matcher := BuildMatcher("", "", "", nil, regexp.MustCompile("^dead.*beef$"), false)
+
+	if !matcher("0xdead00000000000000000000000000000000beef") {
+		t.Fatalf("expected anchored body regex to match")
+	}
+	if matcher("0x00dead000000000000000000000000000000beef") {
+		t.Fatalf("expected ^dead to anchor at the start of the body")
+	}
+
+	both := BuildMatcher("", "", "", regexp.MustCompile("^0x"), regexp.MustCompile("^dead"), false)
+	if !both("0xdead000000000000000000000000000000000000") {
+		t.Fatalf("expected both regexes to match")
+	}
+	both = BuildMatcher("", "", "", regexp.MustCompile("ff$"), regexp.MustCompile("^dead"), false)
+	if both("0xdead000000000000000000000000000000000000") {
+		t.Fatalf("expected a failing full-address regex to reject")
+	}
+}
+
+func TestBuildMatcher_Exclude(t *testing.T) {
+	matcher := BuildMatcher("dead", "", "", nil, nil, false, "00")
+	if !matcher("0xdead12345678901234567890123456789abcdef") {
+		t.Fatalf("expected match without excluded substring")
+	}
+	if matcher("0xdead12345678900234567890123456789abcdef") {
+		t.Fatalf("expected excluded substring to reject the address")
+	}
+	if matcher("0xDEAD12345678900234567890123456789abcdef") {
+		t.Fatalf("expected exclusion to ignore case when not case-sensitive")
+	}
+	alt := BuildMatcher("dead", "", "", nil, nil, false, "00|ff")
+	if alt("0xdead1234567890123456789012345678ffabcdef") {
+		t.Fatalf("expected any excluded alternative to reject the address")
+	}
+}
+
+func TestHexDifficulty_ExcludeIsHarder(t *testing.T) {
+	plain := HexDifficulty("dead", "", "", false)
+	excl := HexDifficulty("dead", "", "", false, "00")
+	if plain == nil || excl == nil || excl.Cmp(plain) <= 0 {
+		t.Fatalf("expected exclusion to raise difficulty: %v -> %v", plain, excl)
+	}
+	// 39 chances of 1/256 each: a little under 1 - 39/256 of addresses avoid "00".
+	if got, max := excl.Int64(), int64(float64(plain.Int64())/(1-39.0/256)); got > max {
+		t.Fatalf("difficulty %d above the expected bound %d", got, max)
+	}
+}
+
+func TestRun_ExcludeRejectsMatches(t *testing.T) {
+	resultCh := make(chan Result, 20)
+	cfg := Config{Workers: 2, Count: 20, Prefix: "de", Exclude: []string{"00", "f"}, Fast: true}
+	Run(context.Background(), cfg, resultCh, &Stats{})
+	for r := range resultCh {
+		bare := strings.TrimPrefix(r.Address, "0x")
+		if !strings.HasPrefix(bare, "de") || strings.Contains(bare, "00") || strings.Contains(bare, "f") {
+			t.Fatalf("result %s violates prefix de / exclude 00,f", r.Address)
+		}
+	}
+}
+
 func TestBuildMatcher_LegacyAlternationStillWorks(t *testing.T) {
-	matcher := BuildMatcher("e|f|ff", "", "", nil, false)
+	matcher := BuildMatcher("e|f|ff", "", "", nil, nil, false)
 
 	if !matcher("0xffaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa") {
 		t.Fatalf("expected legacy alternation to match")
@@ -36,6 +326,46 @@ func TestBuildMatcher_LegacyAlternationStillWorks(t *testing.T) {
 	}
 }
 
+func TestDecimalSuffix(t *testing.T) {
+	for in, want := range map[string]string{"1337": "0539", "255": "ff", "0": "00", "4096": "1000", "18446744073709551615": "ffffffffffffffff"} {
+		if got, err := DecimalSuffix(in); err != nil || got != want {
+			t.Errorf("DecimalSuffix(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "-1", "0x10", "12ab", "18446744073709551616"} {
+		if _, err := DecimalSuffix(in); err == nil {
+			t.Errorf("DecimalSuffix(%q): expected error", in)
+		}
+	}
+}
+
+func TestPreviewLayout(t *testing.T) {
+	cases := []struct {
+		prefix, suffix, contains string
+		want                     [5]int
+	}{
+		{"dead", "beef", "", [5]int{4, 32, 0, 0, 4}},
+		{"dead", "", "cafe", [5]int{4, 16, 4, 16, 0}},
+		{"e|f|ff", "", "(0|1)(00|11)", [5]int{1, 18, 3, 18, 0}},
+		// contains longer than the gap left by prefix and suffix is dropped.
+		{strings.Repeat("a", 18), strings.Repeat("b", 18), "cafe0", [5]int{18, 4, 0, 0, 18}},
+		// contains exactly filling the gap.
+		{strings.Repeat("a", 18), strings.Repeat("b", 18), "cafe", [5]int{18, 0, 4, 0, 18}},
+		// overlapping prefix and suffix are clamped to 40 nibbles.
+		{strings.Repeat("a", 30), strings.Repeat("b", 30), "c", [5]int{30, 0, 0, 0, 10}},
+		{"", "", "", [5]int{0, 40, 0, 0, 0}},
+	}
+	for _, c := range cases {
+		p, b, n, a, s := PreviewLayout(c.prefix, c.suffix, c.contains)
+		if got := [5]int{p, b, n, a, s}; got != c.want {
+			t.Errorf("PreviewLayout(%q, %q, %q) = %v, want %v", c.prefix, c.suffix, c.contains, got, c.want)
+		}
+		if p+b+n+a+s != 40 {
+			t.Errorf("PreviewLayout(%q, %q, %q) covers %d nibbles", c.prefix, c.suffix, c.contains, p+b+n+a+s)
+		}
+	}
+}
+
 func TestMinHexPatternLen(t *testing.T) {
 	if got := MinHexPatternLen("x(a|b|c)(10|20|30|40|50)"); got != 3 {
 		t.Fatalf("expected min length 3, got %d", got)
@@ -88,3 +418,2005 @@ func TestAddressFromKey_RespectsCaseMode(t *testing.T) {
 		t.Fatalf("case-insensitive address mismatch: got %q want %q", ci, strings.ToLower(wantCS))
 	}
 }
+
+func TestKeyGen_MatchesPubkeyToAddress(t *testing.T) {
+	gen := newKeyGen(nil, "")
+	for i := 0; i < 100; i++ {
+		if err := gen.next(); err != nil {
+			t.Fatalf("next: %v", err)
+		}
+		key, err := crypto.ToECDSA(gen.priv[:])
+		if err != nil {
+			t.Fatalf("generated key is invalid: %v", err)
+		}
+		if got, want := gen.address(), crypto.PubkeyToAddress(key.PublicKey); got != want {
+			t.Fatalf("address mismatch: got %s want %s", got.Hex(), want.Hex())
+		}
+		if got, want := hex.EncodeToString(gen.priv[:]), privateKeyHex(key); got != want {
+			t.Fatalf("private key hex mismatch: got %s want %s", got, want)
+		}
+	}
+}
+
+func TestKeyGen_RejectsOutOfRangeScalars(t *testing.T) {
+	// Zero, N and 2^256-1 are all invalid; the fourth scalar is 1.
+	var src bytes.Buffer
+	src.Write(make([]byte, 32))
+	n, _ := hex.DecodeString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141")
+	src.Write(n)
+	src.Write(bytes.Repeat([]byte{0xff}, 32))
+	one := make([]byte, 32)
+	one[31] = 1
+	src.Write(one)
+	src.Write(make([]byte, 32*(entropyBatch-4)))
+
+	gen := newKeyGen(nil, "")
+	gen.rand = &src
+	if err := gen.next(); err != nil {
+		t.Fatalf("next: %v", err)
+	}
+	if !bytes.Equal(gen.priv[:], one) {
+		t.Fatalf("expected scalar 1, got %x", gen.priv)
+	}
+	// 1*G is the generator point, whose address is well known.
+	if got, want := gen.address().Hex(), "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"; got != want {
+		t.Fatalf("address mismatch: got %s want %s", got, want)
+	}
+}
+
+func BenchmarkKeyGen_GenerateKey(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		key, err := crypto.GenerateKey()
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = crypto.PubkeyToAddress(key.PublicKey)
+	}
+}
+
+func BenchmarkKeyGen_Reused(b *testing.B) {
+	gen := newKeyGen(nil, "")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := gen.next(); err != nil {
+			b.Fatal(err)
+		}
+		_ = gen.address()
+	}
+}
+
+func TestKeyGen_FillMatchesPubkeyToAddress(t *testing.T) {
+	gen := newKeyGen(nil, "")
+	batch := new(keyBatch)
+	if err := gen.fill(batch); err != nil {
+		t.Fatalf("fill: %v", err)
+	}
+	if batch.n != keyBatchSize {
+		t.Fatalf("expected %d candidates, got %d", keyBatchSize, batch.n)
+	}
+	for i := 0; i < batch.n; i++ {
+		key, err := crypto.ToECDSA(batch.priv[i][:])
+		if err != nil {
+			t.Fatalf("generated key %d is invalid: %v", i, err)
+		}
+		if got, want := batch.addr[i], crypto.PubkeyToAddress(key.PublicKey); got != want {
+			t.Fatalf("address %d mismatch: got %s want %s", i, got.Hex(), want.Hex())
+		}
+	}
+}
+
+func BenchmarkKeyGen_Batch(b *testing.B) {
+	gen := newKeyGen(nil, "")
+	batch := new(keyBatch)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i += keyBatchSize {
+		if err := gen.fill(batch); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestWorkerRand_IndependentAndRekeyed(t *testing.T) {
+	a, b := newWorkerRand(), newWorkerRand()
+	var x, y, z [64]byte
+	a.Read(x[:])
+	b.Read(y[:])
+	a.Read(z[:])
+	if x == y {
+		t.Fatal("two workerRands produced the same output")
+	}
+	if x == z {
+		t.Fatal("a workerRand repeated its output")
+	}
+	if x == [64]byte{} {
+		t.Fatal("workerRand produced zeros")
+	}
+}
+
+// BenchmarkEntropy_Parallel compares reading one keyGen refill from the
+// shared crypto/rand.Reader with reading it from a workerRand per goroutine.
+func BenchmarkEntropy_Parallel(b *testing.B) {
+	for _, tc := range []struct {
+		name string
+		src  func() io.Reader
+	}{
+		{"shared", func() io.Reader { return cryptorand.Reader }},
+		{"worker", func() io.Reader { return newWorkerRand() }},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			b.SetBytes(32 * entropyBatch)
+			b.RunParallel(func(pb *testing.PB) {
+				r := tc.src()
+				var buf [32 * entropyBatch]byte
+				for pb.Next() {
+					if _, err := io.ReadFull(r, buf[:]); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkKeyGen_Parallel runs one keyGen per goroutine, as Run does; use
+// -cpu 32 to see how key generation scales across workers.
+func BenchmarkKeyGen_Parallel(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		gen := newKeyGen(nil, "")
+		batch := new(keyBatch)
+		for pb.Next() {
+			if err := gen.next(); err != nil {
+				b.Fatal(err)
+			}
+		}
+		gen.wipe(batch)
+	})
+}
+
+func TestKeyGen_FillIncrementalMatchesPubkeyToAddress(t *testing.T) {
+	gen := newKeyGen(nil, "")
+	batch := new(keyBatch)
+	var prev *big.Int
+	for round := 0; round < 2; round++ {
+		if err := gen.fillIncremental(batch); err != nil {
+			t.Fatalf("fillIncremental: %v", err)
+		}
+		for i := 0; i < batch.n; i++ {
+			key, err := crypto.ToECDSA(batch.priv[i][:])
+			if err != nil {
+				t.Fatalf("generated key %d is invalid: %v", i, err)
+			}
+			if got, want := batch.addr[i], crypto.PubkeyToAddress(key.PublicKey); got != want {
+				t.Fatalf("round %d address %d mismatch: got %s want %s", round, i, got.Hex(), want.Hex())
+			}
+			if prev != nil && new(big.Int).Sub(key.D, prev).Cmp(big.NewInt(1)) != 0 {
+				t.Fatalf("round %d key %d is not the successor of the previous key", round, i)
+			}
+			prev = key.D
+		}
+	}
+}
+
+func BenchmarkKeyGen_Incremental(b *testing.B) {
+	gen := newKeyGen(nil, "")
+	batch := new(keyBatch)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i += keyBatchSize {
+		if err := gen.fillIncremental(batch); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestMeasureRate_Positive(t *testing.T) {
+	if rate := MeasureRate(Config{Workers: 1}, 50*time.Millisecond); rate <= 0 {
+		t.Fatalf("expected a positive rate, got %f", rate)
+	}
+}
+
+func TestNearMatcher_HammingDistance(t *testing.T) {
+	target := "0xdeadbeef00112233445566778899aabbccddeeff"
+	matcher := NearMatcher(target, 2)
+
+	if !matcher(target) {
+		t.Fatalf("expected target to match itself")
+	}
+	if !matcher("0xDEADbeef00112233445566778899aabbccddee00") {
+		t.Fatalf("expected distance-2 address (mixed case) to match")
+	}
+	if matcher("0x0eadbeef00112233445566778899aabbccddee00") {
+		t.Fatalf("expected distance-3 address not to match")
+	}
+}
+
+func TestHexDifficulty_OverlappingPrefixAndSuffix(t *testing.T) {
+	prefix := strings.Repeat("0", 30)
+	suffix := strings.Repeat("1", 30)
+	if d := HexDifficulty(prefix, suffix, "", false); d != nil {
+		t.Fatalf("HexDifficulty = %s, want nil for a prefix and suffix that cannot both fit", d)
+	}
+	if err := ValidatePatternFit(prefix, suffix, "", false); !errors.Is(err, ErrPatternTooLong) {
+		t.Fatalf("ValidatePatternFit = %v, want ErrPatternTooLong", err)
+	}
+
+	// Agreeing where they overlap, they fix the whole body: one address in
+	// 16^40, not the 16^-60 a naive product would give.
+	if d := HexDifficulty(strings.Repeat("0", 30), strings.Repeat("0", 30), "", false); d.Cmp(addressSpace) != 0 {
+		t.Fatalf("HexDifficulty = %s, want 16^40", d)
+	}
+	// One alternative short enough to sit beside the other keeps the usual estimate.
+	if err := ValidatePatternFit(prefix+"|ab", suffix, "", false); err != nil {
+		t.Fatalf("ValidatePatternFit with a short alternative = %v", err)
+	}
+	if err := ValidatePatternFit("", "", strings.Repeat("a", 41), false); !errors.Is(err, ErrPatternTooLong) {
+		t.Fatalf("ValidatePatternFit for a 41-char contains = %v", err)
+	}
+	if err := ValidatePatternFit("dead", "beef", "", true); err != nil {
+		t.Fatalf("ValidatePatternFit(dead, beef) = %v", err)
+	}
+}
+
+func TestCheckCriteria_PrefixVersusRegexAnchor(t *testing.T) {
+	for _, tc := range []struct {
+		cfg  Config
+		fail bool
+	}{
+		{Config{Prefix: "dead", Regex: "^0xbeef"}, true},
+		{Config{Prefix: "dead", Regex: "^0xde"}, false},
+		{Config{Prefix: "de", Regex: "^0xdead[0-9]"}, false},
+		{Config{Prefix: "dead|beef", Regex: "^0xbeef"}, false},
+		{Config{Prefix: "dead", Regex: "0xbeef"}, false}, // unanchored
+		{Config{Prefix: "dead", Regex: "^0xDEAD"}, true}, // matched in lowercase
+		{Config{Prefix: "dead", Regex: "(?i)^0xDEAD"}, false},
+		{Config{Prefix: "dEad", Regex: "^0xdEad", CaseSensitive: true}, false},
+		{Config{Regex: "^1x"}, true},
+		{Config{Prefix: "dead", RegexBody: "^beef"}, true},
+		{Config{Suffix: "beef", Regex: "dead$"}, true},
+		{Config{Suffix: "beef", RegexBody: "eef$"}, false},
+		{Config{Prefix: "dead", Regex: "^0xbeef", Chain: ChainTron}, false},
+	} {
+		err := CheckCriteria(tc.cfg)
+		if (err != nil) != tc.fail {
+			t.Errorf("CheckCriteria(%+v) = %v, want failure %v", tc.cfg, err, tc.fail)
+		}
+	}
+	if err := CheckCriteria(Config{Prefix: strings.Repeat("0", 30), Suffix: strings.Repeat("1", 30)}); !errors.Is(err, ErrPatternTooLong) {
+		t.Errorf("CheckCriteria for an overflowing prefix and suffix = %v", err)
+	}
+}
+
+func TestDifficulty_Near(t *testing.T) {
+	// Distance 0 is a single exact address: 16^40 attempts.
+	want := new(big.Int).Exp(big.NewInt(16), big.NewInt(40), nil)
+	if got := Difficulty(Config{Near: "0xdeadbeef00112233445566778899aabbccddeeff"}); got.Cmp(want) != 0 {
+		t.Fatalf("distance 0: got %s want %s", got, want)
+	}
+	// Distance 1 adds 40*15 neighbours.
+	want.Quo(want, big.NewInt(1+40*15))
+	if got := Difficulty(Config{Near: "0xdeadbeef00112233445566778899aabbccddeeff", MaxDistance: 1}); got.Cmp(want) != 0 {
+		t.Fatalf("distance 1: got %s want %s", got, want)
+	}
+}
+
+func TestWordMatcher_ReportsWord(t *testing.T) {
+	matcher := WordMatcher([]string{"dead", "beef"}, false)
+
+	word, ok := matcher("0x0000000000BEEF00000000000000000000000000")
+	if !ok || word != "beef" {
+		t.Fatalf("expected match on beef, got %q, %v", word, ok)
+	}
+	if _, ok := matcher("0x0000000000000000000000000000000000000000"); ok {
+		t.Fatalf("expected no match")
+	}
+}
+
+func TestParseWordList(t *testing.T) {
+	words, err := ParseWordList(strings.NewReader("# comment\ndead\n\n0xbeef\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(words, ",") != "dead,beef" {
+		t.Fatalf("unexpected words: %v", words)
+	}
+	if _, err := ParseWordList(strings.NewReader("dead\nhello\n")); err == nil {
+		t.Fatalf("expected error for non-hex word")
+	}
+}
+
+func TestDifficulty_WordsIsUnion(t *testing.T) {
+	one := Difficulty(Config{Words: []string{"dead"}})
+	two := Difficulty(Config{Words: []string{"dead", "beef"}})
+	if one == nil || two == nil {
+		t.Fatalf("difficulty should not be nil")
+	}
+	if got, want := one.String(), "65536"; got != want {
+		t.Fatalf("single word: got %s want %s", got, want)
+	}
+	if two.Cmp(one) >= 0 {
+		t.Fatalf("expected two words to be easier than one: one=%s two=%s", one, two)
+	}
+}
+
+func TestPrefixMatcher_ReportsShortestPrefix(t *testing.T) {
+	matcher := PrefixMatcher([]string{"dead", "DE", "beef"}, false)
+
+	prefix, ok := matcher("0xDEAD000000000000000000000000000000000000")
+	if !ok || prefix != "de" {
+		t.Fatalf("expected match on de, got %q, %v", prefix, ok)
+	}
+	if prefix, ok := matcher("0xbeef000000000000000000000000000000000000"); !ok || prefix != "beef" {
+		t.Fatalf("expected match on beef, got %q, %v", prefix, ok)
+	}
+	if _, ok := matcher("0x00beef0000000000000000000000000000000000"); ok {
+		t.Fatalf("expected no match for beef past the start")
+	}
+}
+
+func TestBuildMatcher_PrefixAlternationUsesTrie(t *testing.T) {
+	var alts []string
+	for i := 0; i < 4096; i++ {
+		alts = append(alts, fmt.Sprintf("%03x", i)+"f")
+	}
+	match := BuildMatcher(strings.Join(alts, "|"), "", "", nil, nil, false)
+	if !match("0xabcf000000000000000000000000000000000000") {
+		t.Fatalf("expected a match on abcf")
+	}
+	if match("0xabce000000000000000000000000000000000000") {
+		t.Fatalf("expected no match on abce")
+	}
+}
+
+func TestDifficulty_PrefixesIsUnion(t *testing.T) {
+	cases := []struct {
+		prefixes []string
+		want     string
+	}{
+		{[]string{"dead"}, "65536"},
+		{[]string{"dead", "beef"}, "32768"},
+		// dead is covered by de, so only de counts.
+		{[]string{"de", "dead"}, "256"},
+	}
+	for _, tc := range cases {
+		got := Difficulty(Config{Prefixes: tc.prefixes})
+		if got == nil || got.String() != tc.want {
+			t.Errorf("%v: got %v want %s", tc.prefixes, got, tc.want)
+		}
+	}
+}
+
+func TestDifficulty_PatternsIsUnion(t *testing.T) {
+	cases := []struct {
+		patterns []Pattern
+		want     string
+	}{
+		{[]Pattern{{Prefix: "dead"}}, "65536"},
+		// 1 - (1 - 2^-16)^2 is a hair under 2^-15.
+		{[]Pattern{{Prefix: "dead"}, {Suffix: "beef"}}, "32768"},
+		{[]Pattern{{Prefix: "dead"}, {}}, "1"},
+	}
+	for _, tc := range cases {
+		got := Difficulty(Config{Patterns: tc.patterns})
+		if got == nil || got.String() != tc.want {
+			t.Errorf("%v: got %v want %s", tc.patterns, got, tc.want)
+		}
+	}
+}
+
+func TestRun_PatternsStopAtFirstCompleted(t *testing.T) {
+	const count = 3
+	patterns := []Pattern{{ID: "zero", Prefix: "0"}, {ID: "f", Suffix: "f"}, {ID: "cafe", Contains: "cafe"}}
+	resultCh := make(chan Result, 3*count)
+	stats := &Stats{}
+	Run(context.Background(), Config{Patterns: patterns, Count: count, Workers: 4}, resultCh, stats)
+
+	perPattern := map[string]int{}
+	for r := range resultCh {
+		i := slices.IndexFunc(patterns, func(p Pattern) bool { return p.ID == r.Pattern })
+		if i < 0 || r.Match != r.Pattern {
+			t.Fatalf("result %s tagged %q, match %q", r.Address, r.Pattern, r.Match)
+		}
+		p := patterns[i]
+		if !BuildMatcher(p.Prefix, p.Suffix, p.Contains, nil, nil, false)(r.Address) {
+			t.Fatalf("result %s does not match pattern %q", r.Address, p.ID)
+		}
+		perPattern[r.Pattern]++
+	}
+	completed := 0
+	for id, n := range perPattern {
+		if n > count {
+			t.Fatalf("pattern %q got %d results, want at most %d", id, n, count)
+		}
+		if n == count {
+			completed++
+		}
+	}
+	if completed == 0 {
+		t.Fatalf("no pattern reached its count: %v", perPattern)
+	}
+	if total := stats.Found.Load(); total != int64(perPattern["zero"]+perPattern["f"]+perPattern["cafe"]) {
+		t.Fatalf("Found = %d, results %v", total, perPattern)
+	}
+}
+
+// A hit is matched against the patterns once: the index that picks its
+// counter is the one whose ID leads the tag, ahead of the word matched.
+func TestPatternMatch_ReusesFirstMatch(t *testing.T) {
+	cfg := Config{Patterns: []Pattern{{ID: "a", Prefix: "a"}, {ID: "0", Prefix: "0"}}}
+	set := newPatternSet(cfg)
+	var calls int
+	for i, m := range set.match {
+		set.match[i] = func(addr string) bool { calls++; return m(addr) }
+	}
+	cfg.patterns, cfg.Words = set, []string{"beef"}
+	match := patternMatch(set, configMatcher(cfg))
+
+	tag, i, ok := match("0x00beef0000000000000000000000000000000000")
+	if !ok || i != 1 || tag != "0+beef" {
+		t.Fatalf("match = %q, %d, %v; want \"0+beef\", 1, true", tag, i, ok)
+	}
+	if calls != 2 {
+		t.Errorf("patterns tried %d times for one hit, want 2 (a, then 0)", calls)
+	}
+	if _, i, ok := match("0xff00000000000000000000000000000000beef00"); ok || i != -1 {
+		t.Errorf("address matching no pattern: index %d, ok %v", i, ok)
+	}
+}
+
+func TestRun_ReportsMatchedPrefix(t *testing.T) {
+	resultCh := make(chan Result, 3)
+	cfg := Config{Prefixes: []string{"0", "1", "2"}, Count: 3, Workers: 2}
+	Run(context.Background(), cfg, resultCh, &Stats{})
+	for r := range resultCh {
+		if r.Match == "" || !strings.HasPrefix(strings.TrimPrefix(strings.ToLower(r.Address), "0x"), r.Match) {
+			t.Fatalf("result %s reports prefix %q", r.Address, r.Match)
+		}
+	}
+}
+
+func BenchmarkPrefixMatcher_10000(b *testing.B) {
+	prefixes := make([]string, 10000)
+	for i := range prefixes {
+		prefixes[i] = fmt.Sprintf("%05x", i*97)
+	}
+	match := PrefixMatcher(prefixes, false)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		match("0xfffff00000000000000000000000000000000000")
+	}
+}
+
+func TestRun_DeliversResultFoundAtCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Unbuffered, so the worker is blocked sending its first match when we
+	// cancel. An empty pattern matches every address.
+	resultCh := make(chan Result)
+	stats := &Stats{}
+	go Run(ctx, Config{Workers: 1, Count: 1}, resultCh, stats)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for stats.Found.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("no match found")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	r, ok := <-resultCh
+	if !ok {
+		t.Fatalf("result found before cancellation was dropped")
+	}
+	if r.Address == "" || len(r.PrivateKey) == 0 {
+		t.Fatalf("incomplete result: %+v", r)
+	}
+	if _, ok := <-resultCh; ok {
+		t.Fatalf("expected channel to be closed after the single result")
+	}
+}
+
+func TestRunWorker_LastResultInGraceEndsSearch(t *testing.T) {
+	// A worker stopped by the Scaler has only its own context cancelled;
+	// if it still hands over the final result, the search must end.
+	var ended atomic.Bool
+	endSearch := func() { ended.Store(true) }
+	wctx, stop := context.WithCancel(context.Background())
+	resultCh := make(chan Result)
+	stats := &Stats{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		match := func(string) (string, int, bool) { return "", -1, true }
+		runWorker(wctx, endSearch, Config{Workers: 1, Count: 1}, nil, match, resultCh, stats)
+	}()
+	for stats.Found.Load() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	stop()
+	time.Sleep(10 * time.Millisecond) // let the worker reach the grace window
+	r := <-resultCh
+	r.PrivateKey.Zero()
+	<-done
+	if !ended.Load() {
+		t.Fatal("final result sent in the grace window did not end the search")
+	}
+}
+
+func TestRun_CancelUnblocksWorkersWithoutReader(t *testing.T) {
+	before := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Every address matches and nobody reads, so both workers end up
+	// blocked sending; as when a UI stops reading the moment it cancels.
+	resultCh := make(chan Result)
+	stats := &Stats{}
+	done := make(chan struct{})
+	go func() {
+		Run(ctx, Config{Workers: 2}, resultCh, stats)
+		close(done)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for stats.Found.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("workers never blocked on a send")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(sendGrace + 2*time.Second):
+		t.Fatal("Run still blocked after the send grace period")
+	}
+	if _, ok := <-resultCh; ok {
+		t.Fatal("resultCh not closed after Run returned")
+	}
+	for time.Now().Before(deadline) && runtime.NumGoroutine() > before {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Fatalf("%d goroutines still running after Run, want at most %d", n, before)
+	}
+}
+
+func TestRun_CountPerAltFillsEveryAlternative(t *testing.T) {
+	cfg := Config{Workers: 2, Count: 1, Prefix: "(a|b)|c|A", CountPerAlt: 2, Fast: true}
+	if alts := PrefixAlternatives(cfg.Prefix, false); !slices.Equal(alts, []string{"a", "b", "c"}) {
+		t.Fatalf("PrefixAlternatives = %q, want a, b, c", alts)
+	}
+	resultCh := make(chan Result, 6)
+	Run(context.Background(), cfg, resultCh, &Stats{})
+	got := make(map[string]int)
+	for r := range resultCh {
+		if !strings.HasPrefix(strings.ToLower(r.Address[2:]), r.Pattern) {
+			t.Fatalf("%s reported under alternative %q", r.Address, r.Pattern)
+		}
+		got[r.Pattern]++
+	}
+	if got["a"] != 2 || got["b"] != 2 || got["c"] != 2 || len(got) != 3 {
+		t.Fatalf("results per alternative = %v, want 2 each of a, b and c", got)
+	}
+}
+
+func TestRun_NeverExceedsCount(t *testing.T) {
+	for round := 0; round < 5; round++ {
+		resultCh := make(chan Result, 64)
+		stats := &Stats{}
+		// Every address matches, so all 64 workers race for the one slot.
+		// Fast mode keeps the batch fills cheap.
+		Run(context.Background(), Config{Workers: 64, Count: 1, Fast: true}, resultCh, stats)
+
+		n := 0
+		for range resultCh {
+			n++
+		}
+		if n != 1 {
+			t.Fatalf("round %d: expected exactly 1 result, got %d", round, n)
+		}
+		if got := stats.Found.Load(); got != 1 {
+			t.Fatalf("round %d: expected Found=1, got %d", round, got)
+		}
+	}
+}
+
+func BenchmarkRun_CountOne(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		resultCh := make(chan Result, 1)
+		Run(context.Background(), Config{Prefix: "000", Workers: 4, Count: 1, Fast: true}, resultCh, &Stats{})
+		if n := len(resultCh); n != 1 {
+			b.Fatalf("expected 1 result, got %d", n)
+		}
+	}
+}
+
+func TestPrefixFilter_AgreesWithMatcher(t *testing.T) {
+	cases := []Config{
+		{Prefix: "de"},
+		{Prefix: "x(a|b)(1|2)"},
+		{Prefix: "DeAd", CaseSensitive: true},
+		{Prefixes: []string{"0", "ab", "fff"}},
+		{Prefix: "d", Prefixes: []string{"de", "f"}},
+	}
+	for _, cfg := range cases {
+		filter, match := prefixFilter(cfg), configMatcher(cfg)
+		for i := 0; i < 20000; i++ {
+			var addr common.Address
+			if _, err := cryptorand.Read(addr[:]); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := match(formatAddress(addr, cfg.CaseSensitive)); ok && !filter(addr[:]) {
+				t.Fatalf("%+v: filter rejected matching address %s", cfg, addr.Hex())
+			}
+		}
+	}
+	if prefixFilter(Config{Suffix: "de"}) != nil || prefixFilter(Config{Prefix: "de", Invert: true}) != nil {
+		t.Fatalf("expected no filter without a prefix or with Invert")
+	}
+}
+
+// BenchmarkCandidate_Prefix4 measures the per-candidate check for a 4-char
+// prefix, with and without rejecting from the raw bytes first.
+func BenchmarkCandidate_Prefix4(b *testing.B) {
+	cfg := Config{Prefix: "dead"}
+	addrs := make([]common.Address, 1024)
+	for i := range addrs {
+		if _, err := cryptorand.Read(addrs[i][:]); err != nil {
+			b.Fatal(err)
+		}
+	}
+	match := configMatcher(cfg)
+	b.Run("hex", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			match(formatAddress(addrs[i%len(addrs)], false))
+		}
+	})
+	b.Run("filter", func(b *testing.B) {
+		filter := prefixFilter(cfg)
+		for i := 0; i < b.N; i++ {
+			addr := &addrs[i%len(addrs)]
+			if filter(addr[:]) {
+				match(formatAddress(*addr, false))
+			}
+		}
+	})
+}
+
+func TestRun_MinScoreUsesScorer(t *testing.T) {
+	resultCh := make(chan Result, 2)
+	cfg := Config{
+		MinScore: 2, Count: 2, Workers: 2, Fast: true,
+		// Leading zero nibbles, so about 1 in 256 addresses qualifies.
+		Scorer: func(addr string) int {
+			body := strings.TrimPrefix(addr, "0x")
+			return len(body) - len(strings.TrimLeft(body, "0"))
+		},
+	}
+	Run(context.Background(), cfg, resultCh, &Stats{})
+	n := 0
+	for r := range resultCh {
+		n++
+		if !strings.HasPrefix(r.Address, "0x00") || !strings.HasPrefix(r.Match, "score=") {
+			t.Fatalf("result %s (match %q) does not meet the score", r.Address, r.Match)
+		}
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 results, got %d", n)
+	}
+	if Difficulty(cfg) != nil {
+		t.Fatalf("expected no difficulty for a score threshold")
+	}
+}
+
+func TestChecksumAddress(t *testing.T) {
+	if got, want := ChecksumAddress("0x7e5f4552091a69125d5dfcb7b8c2659029395bdf"), "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"; got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+}
+
+func TestRun_ResultCarriesChecksum(t *testing.T) {
+	resultCh := make(chan Result, 1)
+	Run(context.Background(), Config{Workers: 1, Count: 1}, resultCh, &Stats{})
+	r := <-resultCh
+	if r.Address != strings.ToLower(r.Address) {
+		t.Fatalf("case-insensitive search returned %s", r.Address)
+	}
+	if want := ChecksumAddress(r.Address); r.Checksum != want {
+		t.Fatalf("Checksum = %s, want %s", r.Checksum, want)
+	}
+}
+
+func TestSearch_EndToEnd(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	cfg := Config{Workers: 2, Count: 8, Prefix: "a"}
+	stats := &Stats{}
+	results, err := Search(ctx, cfg, stats)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("search for a one-nibble prefix timed out")
+	}
+	if len(results) != cfg.Count || stats.Found.Load() != int64(cfg.Count) {
+		t.Fatalf("got %d results, Found = %d; want %d of each", len(results), stats.Found.Load(), cfg.Count)
+	}
+	seen := make(map[string]bool)
+	for _, r := range results {
+		if !strings.HasPrefix(r.Address, "0xa") {
+			t.Errorf("%s does not match the prefix", r.Address)
+		}
+		if seen[r.Address] {
+			t.Errorf("%s sent twice", r.Address)
+		}
+		seen[r.Address] = true
+		key, err := crypto.ToECDSA(r.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := crypto.PubkeyToAddress(key.PublicKey).Hex(); got != r.Checksum {
+			t.Errorf("key of %s derives %s", r.Checksum, got)
+		}
+	}
+	if stats.Total.Load() < int64(cfg.Count) {
+		t.Fatalf("Total = %d, below the %d results", stats.Total.Load(), cfg.Count)
+	}
+}
+
+func TestASCIIPattern_MatchesOnByteBoundaryOnly(t *testing.T) {
+	contains, body, err := ASCIIPattern("cafe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contains != "63616665" {
+		t.Fatalf("contains = %q, want 63616665", contains)
+	}
+	match := BuildMatcher("", "", contains, nil, regexp.MustCompile(body), false)
+	aligned := "0x00" + contains + "000000000000000000000000000000"
+	if !match(aligned) {
+		t.Errorf("%s rejected", aligned)
+	}
+	if got := ASCIIText(aligned); got != ".cafe..............." {
+		t.Errorf("ASCIIText(%s) = %q", aligned, got)
+	}
+	if odd := "0x0" + contains + "0000000000000000000000000000000"; match(odd) {
+		t.Errorf("%s accepted, though cafe straddles its bytes", odd)
+	}
+	for _, bad := range []string{"", "caf\x00", "twenty-one characters"} {
+		if _, _, err := ASCIIPattern(bad); err == nil {
+			t.Errorf("ASCIIPattern(%q) accepted", bad)
+		}
+	}
+}
+
+// Held to 17 byte boundaries of the 33 offsets its 8 nibbles fit at,
+// "cafe" is 33/17 times as hard as a free-floating contains.
+func TestDifficulty_ASCIICountsByteBoundaries(t *testing.T) {
+	contains, body, err := ASCIIPattern("cafe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := ASCIIOffsets("cafe"); n != 17 {
+		t.Fatalf("ASCIIOffsets(cafe) = %d, want 17", n)
+	}
+	free := Difficulty(Config{Contains: contains})
+	aligned := Difficulty(Config{Contains: contains, RegexBody: body, ContainsOffsets: 17})
+	want := new(big.Int).Quo(new(big.Int).Mul(free, big.NewInt(33)), big.NewInt(17))
+	if aligned.Cmp(want) != 0 {
+		t.Errorf("aligned difficulty = %s, want %s (free %s)", aligned, want, free)
+	}
+}
+
+func TestResult_PublicDropsSecrets(t *testing.T) {
+	r := Result{
+		Address:        "0xdead",
+		Checksum:       "0xDEAD",
+		Match:          "dead",
+		PrivateKey:     PrivateKey{1, 2, 3},
+		Mnemonic:       "abandon about",
+		DerivationPath: "m/44'/60'/0'/0/0",
+		Passphrase:     "hunter2",
+		Salt:           7,
+		PublicKey:      "04ab",
+		Pattern:        "dead",
+	}
+	got := r.Public()
+	want := Result{Address: "0xdead", Checksum: "0xDEAD", Match: "dead", PublicKey: "04ab", Pattern: "dead"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Public() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRecent_StaysBoundedOverLongRun(t *testing.T) {
+	const max, n = 10, 100_000
+	k := Recent{Max: max}
+	var dropped []PrivateKey
+	for i := 0; i < n; i++ {
+		key := make(PrivateKey, 32)
+		key[31] = 1
+		r := Result{Address: fmt.Sprintf("0x%040x", i), PrivateKey: key}
+		if old, ok := k.Add(r); ok && len(dropped) < 3 {
+			dropped = append(dropped, old.PrivateKey)
+		}
+	}
+	if k.Len() != max || cap(k.buf) > max || k.Dropped() != n-max {
+		t.Fatalf("kept %d (capacity %d), dropped %d; want %d, at most %d, %d", k.Len(), cap(k.buf), k.Dropped(), max, max, n-max)
+	}
+	for i, r := range k.Results() {
+		if want := fmt.Sprintf("0x%040x", n-max+i); r.Address != want {
+			t.Fatalf("result %d = %s, want %s", i, r.Address, want)
+		}
+	}
+	for _, key := range dropped {
+		if key[31] != 0 {
+			t.Fatal("a dropped result's key was not wiped")
+		}
+	}
+}
+
+// TestSeenAndRecent_LongRunStaysBounded drives Seen and Recent the way the
+// results loops of the CLI and the TUI do, over a run that keeps finding
+// new addresses and sometimes repeats an evicted one: repeats are still
+// dropped, and the heap grows by the 20-byte seen keys, not the results.
+func TestSeenAndRecent_LongRunStaysBounded(t *testing.T) {
+	const max, n = 16, 200_000
+	var seen Seen
+	kept := Recent{Max: max}
+	found, dups := 0, 0
+	add := func(i int) {
+		key := make(PrivateKey, 32)
+		key[31] = 1
+		addr := fmt.Sprintf("0x%040x", i)
+		if !seen.Add(addr) {
+			dups++
+			key.Zero()
+			return
+		}
+		found++
+		kept.Add(Result{Address: addr, Checksum: addr, PrivateKey: key})
+	}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	for i := 0; i < n; i++ {
+		add(i)
+		if i%1000 == 999 {
+			add(i - 500) // long since evicted
+		}
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	if found != n || dups != n/1000 || seen.Len() != n {
+		t.Fatalf("found %d, dropped %d repeats, seen %d; want %d, %d, %d", found, dups, seen.Len(), n, n/1000, n)
+	}
+	if kept.Len() != max || kept.Dropped() != n-max {
+		t.Fatalf("kept %d, dropped %d; want %d, %d", kept.Len(), kept.Dropped(), max, n-max)
+	}
+	// A kept Result with its strings and key takes over 300 bytes; a seen
+	// key takes 20 plus map overhead.
+	if grew := int64(after.HeapAlloc) - int64(before.HeapAlloc); grew > 128*n {
+		t.Fatalf("heap grew %d bytes over %d results, %d a result", grew, n, grew/n)
+	}
+	runtime.KeepAlive(&seen)
+	runtime.KeepAlive(&kept)
+}
+
+func TestSeen_KeysBase58ByPayload(t *testing.T) {
+	addr := common.HexToAddress("0x7e5f4552091a69125d5dfcb7b8c2659029395bdf")
+	var seen Seen
+	if !seen.Add(TronAddress(addr)) || seen.Add(TronAddress(addr)) {
+		t.Fatal("a Tron address was not recorded once")
+	}
+	if seenKey(TronAddress(addr)) != addr {
+		t.Fatalf("Tron key = %x, want %x", seenKey(TronAddress(addr)), addr)
+	}
+	if !seen.Add("0x0000000000000000000000000000000000000001") {
+		t.Fatal("a new hex address counted as seen")
+	}
+}
+
+func TestKeyScheme_Secp256k1MatchesGoEthereum(t *testing.T) {
+	scheme := newKeyScheme(KeyTypeSecp256k1)
+	var priv [32]byte
+	var pub [64]byte
+	for i := 0; i < 20; i++ {
+		cryptorand.Read(priv[:])
+		key, err := crypto.ToECDSA(priv[:])
+		if err != nil {
+			continue // out of range; as unlikely as 2^-128
+		}
+		if !scheme.publicKey(&priv, &pub) {
+			t.Fatalf("valid key %x rejected", priv)
+		}
+		if got, want := common.BytesToAddress(crypto.Keccak256(pub[:])[12:]), crypto.PubkeyToAddress(key.PublicKey); got != want {
+			t.Fatalf("key %x gives address %s, want %s", priv, got, want)
+		}
+	}
+	var zero [32]byte
+	order := [32]byte(crypto.S256().Params().N.FillBytes(make([]byte, 32)))
+	for _, bad := range [][32]byte{zero, order} {
+		if scheme.publicKey(&bad, &pub) {
+			t.Errorf("invalid key %x accepted", bad)
+		}
+	}
+}
+
+func TestRun_RejectsUnknownKeyType(t *testing.T) {
+	results, err := Search(context.Background(), Config{Workers: 1, Count: 1, KeyType: "ed25519"}, nil)
+	if err == nil || len(results) != 0 {
+		t.Fatalf("got %d results and error %v, want an error alone", len(results), err)
+	}
+	if err := ValidateKeyType(""); err != nil {
+		t.Fatalf("default key type rejected: %v", err)
+	}
+}
+
+func TestConfigMatcher_Invert(t *testing.T) {
+	match := configMatcher(Config{Prefix: "00", Invert: true})
+
+	if _, ok := match("0x00aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"); ok {
+		t.Fatalf("expected inverted matcher to reject a matching address")
+	}
+	if _, ok := match("0x0aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"); !ok {
+		t.Fatalf("expected inverted matcher to accept a non-matching address")
+	}
+}
+
+func TestDifficulty_InvertIsTrivial(t *testing.T) {
+	d := Difficulty(Config{Prefix: "00", Invert: true})
+	if d == nil || d.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("expected difficulty 1, got %v", d)
+	}
+}
+
+func TestRun_AuditCountsEveryCandidate(t *testing.T) {
+	resultCh := make(chan Result, 1)
+	stats := &Stats{}
+	Run(context.Background(), Config{Prefix: "000", Workers: 2, Count: 1, Audit: true, Fast: true}, resultCh, stats)
+
+	var sum int64
+	for i := range stats.Nibbles {
+		sum += stats.Nibbles[i].Load()
+	}
+	if total := stats.Total.Load(); sum != total {
+		t.Fatalf("histogram sums to %d, Total is %d", sum, total)
+	}
+	if stats.Nibbles[0].Load() == 0 {
+		t.Fatalf("expected the matching 0-prefixed address to be counted")
+	}
+}
+
+func TestRun_AbortsWhenEntropyFails(t *testing.T) {
+	resultCh := make(chan Result, 1)
+	stats := &Stats{}
+	cfg := Config{Prefix: "00", Workers: 4, Count: 1, Rand: iotest.ErrReader(errors.New("device gone"))}
+
+	done := make(chan struct{})
+	go func() {
+		Run(context.Background(), cfg, resultCh, stats)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Run did not abort on a failing entropy source")
+	}
+
+	if err := stats.Err(); !errors.Is(err, ErrEntropy) {
+		t.Fatalf("expected ErrEntropy, got %v", err)
+	}
+	if _, ok := <-resultCh; ok {
+		t.Fatalf("expected no results")
+	}
+}
+
+func TestSpellings_ExpandsSubstitutions(t *testing.T) {
+	got, err := Spellings("dose", DefaultLeet)
+	if err != nil {
+		t.Fatalf("Spellings: %v", err)
+	}
+	want := []string{"d05e", "d053"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if _, err := Spellings("hex", DefaultLeet); err == nil {
+		t.Fatalf("expected an error for a letter with no substitution")
+	}
+
+	table, err := ParseLeetTable("h=4")
+	if err != nil {
+		t.Fatalf("ParseLeetTable: %v", err)
+	}
+	if _, err := Spellings("hex", table); err == nil {
+		t.Fatalf("expected an error for x")
+	}
+	if got, _ := Spellings("hi", table); len(got) != 1 || got[0] != "41" {
+		t.Fatalf("got %v want [41]", got)
+	}
+}
+
+func TestConfigMatcher_ReportsSpelling(t *testing.T) {
+	spellings, _ := Spellings("dose", DefaultLeet)
+	match := configMatcher(Config{Spellings: spellings})
+
+	tag, ok := match("0xaaaaaaaaaaaad053aaaaaaaaaaaaaaaaaaaaaaaa")
+	if !ok || tag != "d053" {
+		t.Fatalf("got %q, %v; want d053", tag, ok)
+	}
+}
+
+func TestDefaultWorkers_CgroupQuota(t *testing.T) {
+	write := func(t *testing.T, dir, name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	v2 := t.TempDir()
+	write(t, v2, "cpu.max", "150000 100000\n")
+	if got := defaultWorkers(16, v2); got != 2 {
+		t.Fatalf("v2 quota 1.5: got %d want 2", got)
+	}
+
+	unlimited := t.TempDir()
+	write(t, unlimited, "cpu.max", "max 100000\n")
+	if got := defaultWorkers(16, unlimited); got != 16 {
+		t.Fatalf("v2 unlimited: got %d want 16", got)
+	}
+
+	v1 := t.TempDir()
+	write(t, v1, "cpu/cpu.cfs_quota_us", "400000\n")
+	write(t, v1, "cpu/cpu.cfs_period_us", "100000\n")
+	if got := defaultWorkers(16, v1); got != 4 {
+		t.Fatalf("v1 quota 4: got %d want 4", got)
+	}
+	if got := defaultWorkers(2, v1); got != 2 {
+		t.Fatalf("quota above GOMAXPROCS: got %d want 2", got)
+	}
+
+	if got := defaultWorkers(8, t.TempDir()); got != 8 {
+		t.Fatalf("no cgroup files: got %d want 8", got)
+	}
+}
+
+func TestTuneCandidates(t *testing.T) {
+	for n, want := range map[int][]int{1: {1, 2}, 2: {1, 2, 4}, 8: {4, 8, 16}} {
+		if got := tuneCandidates(n); !slices.Equal(got, want) {
+			t.Errorf("tuneCandidates(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
+
+func TestSynthesizeMatch_SatisfiesPattern(t *testing.T) {
+	cfg := Config{Prefix: "(de|ca)", Suffix: "beef", Contains: "c0ffee", Words: []string{"f00d"}}
+	match := configMatcher(cfg)
+	for i := 0; i < 100; i++ {
+		addr, err := SynthesizeMatch(cfg)
+		if err != nil {
+			t.Fatalf("SynthesizeMatch: %v", err)
+		}
+		if len(addr) != 42 {
+			t.Fatalf("bad length %d: %s", len(addr), addr)
+		}
+		if _, ok := match(addr); !ok {
+			t.Fatalf("sample %s does not match", addr)
+		}
+	}
+
+	if _, err := SynthesizeMatch(Config{Regex: "^0xdead"}); err == nil {
+		t.Fatalf("expected an error for a regex pattern")
+	}
+	if _, err := SynthesizeMatch(Config{Prefix: strings.Repeat("a", 30), Suffix: strings.Repeat("b", 11)}); err == nil {
+		t.Fatalf("expected an error for a pattern longer than an address")
+	}
+}
+
+func TestPrivateKey_MaskedKeepsEnds(t *testing.T) {
+	key := make(PrivateKey, 32)
+	key[0], key[31] = 0x1a, 0x9f
+	if got, want := key.Masked(), "1a00****...****009f"; got != want {
+		t.Fatalf("Masked() = %q, want %q", got, want)
+	}
+	if got := MaskSecret("short"); got != "*****" {
+		t.Fatalf("MaskSecret(short) = %q", got)
+	}
+}
+
+func TestZeroKeys_WipesResultKeys(t *testing.T) {
+	resultCh := make(chan Result, 1)
+	Run(context.Background(), Config{Prefix: "0", Workers: 1, Count: 1}, resultCh, &Stats{})
+	r := <-resultCh
+	if len(r.PrivateKey) != 32 || len(r.PrivateKey.String()) != 64 {
+		t.Fatalf("unexpected key %q", r.PrivateKey)
+	}
+
+	r.Mnemonic = "abandon about"
+	rs := []Result{r}
+	ZeroKeys(rs)
+	if !bytes.Equal(r.PrivateKey, make([]byte, 32)) {
+		t.Fatalf("expected zeroed key, got %x", []byte(r.PrivateKey))
+	}
+	if rs[0].Mnemonic != "" {
+		t.Fatalf("mnemonic %q kept after its key was wiped", rs[0].Mnemonic)
+	}
+}
+
+func TestMnemonicKey_KnownVector(t *testing.T) {
+	// The all-"abandon" test phrase; its first Ethereum account is well known.
+	phrase := strings.Repeat("abandon ", 11) + "about"
+	priv, err := mnemonicKey(phrase, defaultDerivationPath)
+	if err != nil {
+		t.Fatalf("mnemonicKey: %v", err)
+	}
+	key, err := crypto.ToECDSA(priv[:])
+	if err != nil {
+		t.Fatalf("ToECDSA: %v", err)
+	}
+	if got, want := crypto.PubkeyToAddress(key.PublicKey).Hex(), "0x9858EfFD232B4033E47d90003D41EC34EcaEda94"; got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+}
+
+// BIP-32 test vector 1: the seed and extended keys at m/0' and m/0'/1.
+var (
+	bip32Seed, _ = hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	bip32XPub0H  = "xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw"
+	bip32XPub0H1 = "xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ"
+)
+
+func TestParseXPub(t *testing.T) {
+	x, err := ParseXPub(bip32XPub0H)
+	if err != nil {
+		t.Fatalf("ParseXPub: %v", err)
+	}
+	if x.Depth() != 1 || x.String() != bip32XPub0H {
+		t.Fatalf("depth %d, string %q", x.Depth(), x.String())
+	}
+	for _, bad := range []string{
+		"xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+		bip32XPub0H[:len(bip32XPub0H)-1] + "x", // checksum
+		"xpub0OIl",
+	} {
+		if _, err := ParseXPub(bad); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
+
+func TestXPub_ChildMatchesPrivateDerivation(t *testing.T) {
+	x, _ := ParseXPub(bip32XPub0H)
+	want, _ := ParseXPub(bip32XPub0H1)
+	mac := hmac.New(sha512.New, x.chain[:])
+	var pub [64]byte
+	if !x.child(mac, 1, &pub) {
+		t.Fatalf("child 1 skipped")
+	}
+	var wantPub [64]byte
+	want.point.ToAffine()
+	want.point.X.PutBytesUnchecked(wantPub[:32])
+	want.point.Y.PutBytesUnchecked(wantPub[32:])
+	if pub != wantPub {
+		t.Fatalf("child 1 of m/0' is not the key of m/0'/1")
+	}
+
+	for _, index := range []uint32{0, 7, 1000} {
+		priv, err := deriveHD(bip32Seed, []uint32{hardenedOffset, index})
+		if err != nil {
+			t.Fatalf("deriveHD: %v", err)
+		}
+		key, _ := crypto.ToECDSA(priv[:])
+		x.child(mac, index, &pub)
+		if got, want := crypto.Keccak256(pub[:])[12:], crypto.PubkeyToAddress(key.PublicKey).Bytes(); !bytes.Equal(got, want) {
+			t.Fatalf("index %d: address %x, want %x", index, got, want)
+		}
+	}
+}
+
+func TestRun_XPubReportsIndexWithoutKey(t *testing.T) {
+	x, _ := ParseXPub(bip32XPub0H)
+	resultCh := make(chan Result, 2)
+	Run(context.Background(), Config{Prefix: "0", Count: 2, Workers: 2, XPub: x}, resultCh, &Stats{})
+	n := 0
+	for r := range resultCh {
+		n++
+		if len(r.PrivateKey) != 0 || r.XPub != bip32XPub0H {
+			t.Fatalf("result %s has key %q and xpub %q", r.Address, r.PrivateKey, r.XPub)
+		}
+		priv, _ := deriveHD(bip32Seed, []uint32{hardenedOffset, r.ChildIndex})
+		key, _ := crypto.ToECDSA(priv[:])
+		if got := crypto.PubkeyToAddress(key.PublicKey).Hex(); got != r.Checksum {
+			t.Fatalf("index %d derives %s, result is %s", r.ChildIndex, got, r.Checksum)
+		}
+	}
+	if n != 2 {
+		t.Fatalf("got %d results, want 2", n)
+	}
+}
+
+func TestMnemonicEntropyBits(t *testing.T) {
+	for words, bits := range map[int]int{12: 128, 15: 160, 18: 192, 21: 224, 24: 256} {
+		if got, err := MnemonicEntropyBits(words); err != nil || got != bits {
+			t.Fatalf("%d words: got %d, %v; want %d", words, got, err, bits)
+		}
+	}
+	if _, err := MnemonicEntropyBits(13); err == nil {
+		t.Fatalf("expected an error for 13 words")
+	}
+}
+
+func TestRun_MnemonicMode(t *testing.T) {
+	resultCh := make(chan Result, 1)
+	Run(context.Background(), Config{Prefix: "0", Workers: 2, Count: 1, Mnemonic: true, MnemonicWords: 24}, resultCh, &Stats{})
+	r := <-resultCh
+
+	if n := len(strings.Fields(r.Mnemonic)); n != 24 {
+		t.Fatalf("expected a 24-word phrase, got %d words", n)
+	}
+	priv, err := mnemonicKey(r.Mnemonic, defaultDerivationPath)
+	if err != nil {
+		t.Fatalf("mnemonicKey: %v", err)
+	}
+	if !bytes.Equal(priv[:], r.PrivateKey) {
+		t.Fatalf("result key does not derive from its phrase")
+	}
+	key, _ := crypto.ToECDSA(priv[:])
+	if got := addressFromKey(key, false); got != r.Address {
+		t.Fatalf("address mismatch: got %s want %s", r.Address, got)
+	}
+}
+
+func TestParseDerivationPath(t *testing.T) {
+	path, err := ParseDerivationPath("m/44h/60'/0'/0/7")
+	if err != nil {
+		t.Fatalf("ParseDerivationPath: %v", err)
+	}
+	if got, want := FormatDerivationPath(path), "m/44'/60'/0'/0/7"; got != want {
+		t.Fatalf("got %s want %s", got, want)
+	}
+	if got := FormatDerivationPath(defaultDerivationPath); got != DefaultDerivationPath {
+		t.Fatalf("default path formats as %s", got)
+	}
+	for _, bad := range []string{"", "44'/60'", "m", "m/", "m/-1", "m/2147483648", "m/1''", "m/a"} {
+		if _, err := ParseDerivationPath(bad); err == nil {
+			t.Fatalf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestRun_MnemonicRecordsDerivationPath(t *testing.T) {
+	resultCh := make(chan Result, 1)
+	cfg := Config{Prefix: "0", Workers: 1, Count: 1, Mnemonic: true, DerivationPath: "m/44h/60h/0h/0"}
+	Run(context.Background(), cfg, resultCh, &Stats{})
+	r := <-resultCh
+
+	if r.DerivationPath != "m/44'/60'/0'/0" {
+		t.Fatalf("got path %q", r.DerivationPath)
+	}
+	path, _ := ParseDerivationPath(r.DerivationPath)
+	priv, err := mnemonicKey(r.Mnemonic, path)
+	if err != nil {
+		t.Fatalf("mnemonicKey: %v", err)
+	}
+	if !bytes.Equal(priv[:], r.PrivateKey) {
+		t.Fatalf("result key was not derived at %s", r.DerivationPath)
+	}
+}
+
+func TestRun_CountZeroStreamsUntilCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	resultCh := make(chan Result)
+	go Run(ctx, Config{Workers: 2, Count: 0, Fast: true}, resultCh, &Stats{})
+
+	// An empty pattern matches everything: far more than any fixed count.
+	for i := 0; i < 1000; i++ {
+		if _, ok := <-resultCh; !ok {
+			t.Fatalf("channel closed after %d results", i)
+		}
+	}
+	cancel()
+	for range resultCh {
+	}
+}
+
+func TestRun_StopsAtMaxAttempts(t *testing.T) {
+	resultCh := make(chan Result, 1)
+	stats := &Stats{}
+	cfg := Config{Prefix: "ffffffffff", Workers: 4, Count: 1, MaxAttempts: 5000, Fast: true}
+	Run(context.Background(), cfg, resultCh, stats)
+	if got := stats.Total.Load(); got < cfg.MaxAttempts || got > cfg.MaxAttempts+int64(cfg.Workers) {
+		t.Fatalf("Total = %d, want %d..%d", got, cfg.MaxAttempts, cfg.MaxAttempts+int64(cfg.Workers))
+	}
+}
+
+func TestRun_FastResumesWhereItStopped(t *testing.T) {
+	start, err := ParsePrivateKey("0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := &Stats{}
+	cfg := Config{Prefix: "ffffffffff", Workers: 1, Count: 1, MaxAttempts: 1000, Fast: true, ResumeFrom: []PrivateKey{start}}
+	Run(context.Background(), cfg, make(chan Result, 1), stats)
+	walks := stats.Walks()
+	want := new(big.Int).Add(new(big.Int).SetBytes(start), big.NewInt(1000))
+	if len(walks) != 1 || new(big.Int).SetBytes(walks[0]).Cmp(want) != 0 {
+		t.Fatalf("Walks = %x, want [%x]", walks, want)
+	}
+
+	// Resuming tries the very next key first.
+	resultCh := make(chan Result, 1)
+	Run(context.Background(), Config{Workers: 1, Count: 1, Fast: true, ResumeFrom: walks}, resultCh, &Stats{})
+	r := <-resultCh
+	if got := new(big.Int).SetBytes(r.PrivateKey); got.Cmp(want.Add(want, big.NewInt(1))) != 0 {
+		t.Fatalf("first key after resuming = %x, want %x", got, want)
+	}
+}
+
+func TestRun_PublicKeyDerivesAddress(t *testing.T) {
+	for _, format := range []string{PublicKeyUncompressed, PublicKeyCompressed} {
+		resultCh := make(chan Result, 2)
+		Run(context.Background(), Config{Workers: 1, Count: 2, PublicKey: format}, resultCh, &Stats{})
+		for r := range resultCh {
+			raw, err := hex.DecodeString(r.PublicKey)
+			if err != nil {
+				t.Fatalf("%s: PublicKey %q: %v", format, r.PublicKey, err)
+			}
+			var pub *ecdsa.PublicKey
+			if format == PublicKeyCompressed {
+				pub, err = crypto.DecompressPubkey(raw)
+			} else {
+				pub, err = crypto.UnmarshalPubkey(raw)
+			}
+			if err != nil {
+				t.Fatalf("%s: parse %x: %v", format, raw, err)
+			}
+			if got := crypto.PubkeyToAddress(*pub).Hex(); got != r.Checksum {
+				t.Fatalf("%s: public key derives %s, result is %s", format, got, r.Checksum)
+			}
+			key, _ := crypto.ToECDSA(r.PrivateKey)
+			if !key.PublicKey.Equal(pub) {
+				t.Fatalf("%s: public key does not belong to the private key", format)
+			}
+		}
+	}
+}
+
+func TestRateSmoother_SettlesAndFollowsThrottling(t *testing.T) {
+	var s RateSmoother
+	// A slow first tick, then a steady 1000/s.
+	total, elapsed := int64(50), 250*time.Millisecond
+	s.Update(total, elapsed)
+	for i := 0; i < 12; i++ {
+		total, elapsed = total+250, elapsed+250*time.Millisecond
+		s.Update(total, elapsed)
+	}
+	if r := s.Rate(); r < 900 || r > 1000 {
+		t.Fatalf("rate after 3s at 1000/s = %.0f, want close to 1000", r)
+	}
+	// Throttled to 500/s: the average follows within a few seconds, where
+	// total/elapsed would still be far off.
+	for i := 0; i < 20; i++ {
+		total, elapsed = total+125, elapsed+250*time.Millisecond
+		s.Update(total, elapsed)
+	}
+	if r := s.Rate(); r < 500 || r > 550 {
+		t.Fatalf("rate after 5s at 500/s = %.0f, want close to 500", r)
+	}
+	if r := s.Update(total, elapsed); r != s.Rate() {
+		t.Fatalf("a sample without elapsed time changed the rate to %.0f", r)
+	}
+}
+
+func TestRun_PinCPU(t *testing.T) {
+	resultCh := make(chan Result, 2)
+	Run(context.Background(), Config{Prefix: "0", Workers: 3, Count: 2, PinCPU: true}, resultCh, &Stats{})
+	if n := len(resultCh); n != 2 {
+		t.Fatalf("got %d results with PinCPU, want 2", n)
+	}
+}
+
+func TestRun_BrainwalletIsReproducible(t *testing.T) {
+	resultCh := make(chan Result, 3)
+	cfg := Config{Workers: 2, Count: 3, Brainwallet: "correct horse battery staple"}
+	Run(context.Background(), cfg, resultCh, &Stats{})
+
+	seen := map[uint64]bool{}
+	for r := range resultCh {
+		if r.Passphrase != cfg.Brainwallet || seen[r.Salt] {
+			t.Fatalf("bad passphrase or repeated salt: %q %d", r.Passphrase, r.Salt)
+		}
+		seen[r.Salt] = true
+		priv, err := BrainwalletKey(r.Passphrase, r.Salt)
+		if err != nil {
+			t.Fatalf("BrainwalletKey: %v", err)
+		}
+		if !bytes.Equal(priv[:], r.PrivateKey) {
+			t.Fatalf("salt %d does not reproduce the result key", r.Salt)
+		}
+	}
+	if len(seen) != 3 {
+		t.Fatalf("got %d results, want 3", len(seen))
+	}
+	a, _ := BrainwalletKey("pass", 0)
+	b, _ := BrainwalletKey("pass", 1)
+	if a == b {
+		t.Fatalf("different salts gave the same key")
+	}
+}
+
+func TestRun_RecoversFromWorkerPanics(t *testing.T) {
+	var calls atomic.Int64
+	newMatcher = func(cfg Config) func(string) (string, bool) {
+		match := configMatcher(cfg)
+		return func(addr string) (string, bool) {
+			if calls.Add(1)%50 == 0 {
+				panic("matcher blew up")
+			}
+			return match(addr)
+		}
+	}
+	t.Cleanup(func() { newMatcher = configMatcher })
+
+	resultCh := make(chan Result, 3)
+	stats := &Stats{}
+	// A suffix, so every candidate reaches the matcher instead of being
+	// rejected by the prefix filter first.
+	Run(context.Background(), Config{Suffix: "00", Workers: 2, Count: 3, Fast: true}, resultCh, stats)
+
+	n := 0
+	for range resultCh {
+		n++
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 results despite panics, got %d", n)
+	}
+	if stats.Panics.Load() == 0 || stats.LastPanic() != "matcher blew up" {
+		t.Fatalf("expected recorded panics, got %d (%q)", stats.Panics.Load(), stats.LastPanic())
+	}
+	if err := stats.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestIsWeakScalar_Boundaries(t *testing.T) {
+	n := crypto.S256().Params().N
+	limit := new(big.Int).Lsh(big.NewInt(1), weakKeyBits)
+	cases := []struct {
+		name string
+		d    *big.Int
+		weak bool
+	}{
+		{"one", big.NewInt(1), true},
+		{"2^128-1", new(big.Int).Sub(limit, big.NewInt(1)), true},
+		{"2^128", limit, false},
+		{"N-2^128", new(big.Int).Sub(n, limit), false},
+		{"N-2^128+1", new(big.Int).Add(new(big.Int).Sub(n, limit), big.NewInt(1)), true},
+		{"N-1", new(big.Int).Sub(n, big.NewInt(1)), true},
+		{"keccak256 of empty", new(big.Int).SetBytes(crypto.Keccak256(nil)), true},
+		{"ordinary", new(big.Int).Lsh(big.NewInt(0x1234567), 200), false},
+	}
+	for _, tc := range cases {
+		var priv [32]byte
+		tc.d.FillBytes(priv[:])
+		if got := isWeakScalar(&priv); got != tc.weak {
+			t.Errorf("%s: isWeakScalar = %v, want %v", tc.name, got, tc.weak)
+		}
+	}
+}
+
+// lowEntropyReader yields keys whose top 16 bytes are always zero.
+type lowEntropyReader struct{ n byte }
+
+func (r *lowEntropyReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 0
+		if i%32 >= 16 {
+			r.n++
+			p[i] = r.n | 1
+		}
+	}
+	return len(p), nil
+}
+
+func TestRun_RejectWeakDiscardsMatches(t *testing.T) {
+	resultCh := make(chan Result, 1)
+	stats := &Stats{}
+	cfg := Config{Contains: "0", Workers: 1, Count: 1, MaxAttempts: 512, RejectWeak: true, Rand: &lowEntropyReader{}}
+	Run(context.Background(), cfg, resultCh, stats)
+	if r, ok := <-resultCh; ok {
+		t.Fatalf("expected no results, got %s", r.Address)
+	}
+	if stats.Weak.Load() == 0 {
+		t.Fatalf("expected weak matches to be counted")
+	}
+}
+
+// cyclingReader yields the keys 1 to n over and over.
+type cyclingReader struct{ i, n int }
+
+func (r *cyclingReader) Read(p []byte) (int, error) {
+	clear(p)
+	for i := 31; i < len(p); i += 32 {
+		p[i] = byte(r.i%r.n + 1)
+		r.i++
+	}
+	return len(p), nil
+}
+
+func TestRun_NeverSendsAnAddressTwice(t *testing.T) {
+	// Far more slots than there are distinct keys: once they are used up
+	// the search only sees repeats until MaxAttempts.
+	const keys, count = 64, 1000
+	resultCh := make(chan Result, count)
+	stats := &Stats{}
+	cfg := Config{Contains: "0", Workers: 1, Count: count, MaxAttempts: 8 * keyBatchSize, Rand: &cyclingReader{n: keys}}
+	Run(context.Background(), cfg, resultCh, stats)
+
+	seen := make(map[string]bool)
+	for r := range resultCh {
+		if seen[r.Address] {
+			t.Fatalf("address %s sent twice", r.Address)
+		}
+		seen[r.Address] = true
+	}
+	if len(seen) == 0 || len(seen) > keys {
+		t.Fatalf("expected between 1 and %d distinct results, got %d", keys, len(seen))
+	}
+	if stats.Found.Load() != int64(len(seen)) {
+		t.Fatalf("Found = %d, want %d: duplicates must not take slots", stats.Found.Load(), len(seen))
+	}
+	if stats.Duplicates.Load() == 0 {
+		t.Fatalf("expected repeated keys to be counted as duplicates")
+	}
+}
+
+func TestRun_KeysStream(t *testing.T) {
+	// Keys 1 to 300, more than one chunk, with a comment and a blank line.
+	var in strings.Builder
+	in.WriteString("# keys\n\n")
+	for k := 1; k <= 300; k++ {
+		fmt.Fprintf(&in, "0x%064x\n", k)
+	}
+	resultCh := make(chan Result, 4)
+	stats := &Stats{}
+	cfg := Config{Prefix: "7e5f4552", Workers: 4, Keys: strings.NewReader(in.String())}
+	Run(context.Background(), cfg, resultCh, stats)
+
+	var got []Result
+	for r := range resultCh {
+		got = append(got, r)
+	}
+	if len(got) != 1 || got[0].Checksum != "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf" {
+		t.Fatalf("expected only the address of key 1, got %v", got)
+	}
+	if n := stats.Total.Load(); n != 300 {
+		t.Fatalf("Total = %d, want 300", n)
+	}
+	if err := stats.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestRun_KeysStreamRejectsBadLines(t *testing.T) {
+	for _, line := range []string{"xyz", "0x01", strings.Repeat("0", 64), strings.Repeat("f", 64)} {
+		in := fmt.Sprintf("%064x\n%s\n", 1, line)
+		resultCh := make(chan Result, 1)
+		stats := &Stats{}
+		Run(context.Background(), Config{Prefix: "00", Workers: 2, Count: 1, Keys: strings.NewReader(in)}, resultCh, stats)
+		for range resultCh {
+		}
+		if err := stats.Err(); !errors.Is(err, ErrKeyStream) || !strings.Contains(err.Error(), "line 2") {
+			t.Fatalf("%q: expected a key stream error on line 2, got %v", line, err)
+		}
+	}
+}
+
+func TestBase58Encode_BitcoinVectors(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"", ""},
+		{"48656c6c6f20576f726c6421", "2NEpo7TZRRrLZSi2U"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+		{"0000", "11"},
+	}
+	for _, tc := range cases {
+		in, _ := hex.DecodeString(tc.in)
+		if got := base58Encode(in); got != tc.want {
+			t.Errorf("base58Encode(%s) = %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestTronAddress_RoundTrips(t *testing.T) {
+	addr := common.HexToAddress("0x7e5f4552091a69125d5dfcb7b8c2659029395bdf")
+	got := TronAddress(addr)
+	if len(got) != 34 || got[0] != 'T' {
+		t.Fatalf("TronAddress = %s, want 34 characters starting with T", got)
+	}
+	n := new(big.Int)
+	for i := 0; i < len(got); i++ {
+		n.Mul(n, big.NewInt(58))
+		n.Add(n, big.NewInt(int64(strings.IndexByte(base58Alphabet, got[i]))))
+	}
+	payload := n.FillBytes(make([]byte, 25))
+	if payload[0] != tronVersion || !bytes.Equal(payload[1:21], addr[:]) {
+		t.Fatalf("decoded payload %x does not hold 41 + %x", payload, addr)
+	}
+	first := sha256.Sum256(payload[:21])
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(payload[21:], second[:4]) {
+		t.Fatalf("checksum %x, want the double SHA-256 prefix %x", payload[21:], second[:4])
+	}
+}
+
+func TestTronAddress_KeyOne(t *testing.T) {
+	k, err := crypto.ToECDSA(common.LeftPadBytes([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The address TronWeb gives private key 1, and the example in the
+	// Tron documentation of the hex and base58check forms of an address.
+	if got, want := TronAddress(crypto.PubkeyToAddress(k.PublicKey)), "TMVQGm1qAQYVdetCeGRRkTWYYrLXuHK2HC"; got != want {
+		t.Errorf("TronAddress(key 1) = %s, want %s", got, want)
+	}
+	if got, want := TronAddress(common.HexToAddress("0x8840E6C55B9ADA326D211D818C34A994AECED808")), "TNPeeaaFB7K9cmo4uQpcU32zGK8G1NYqeL"; got != want {
+		t.Errorf("TronAddress = %s, want %s", got, want)
+	}
+}
+
+func TestBase58PrefixProbability_Tron(t *testing.T) {
+	tron := base58Formats[ChainTron]
+	if p := base58PrefixProbability(tron, "T", true); p.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Fatalf("every address starts with T, got %s", p)
+	}
+	if p := base58PrefixProbability(tron, "TA", true); p.Sign() == 0 || p.Cmp(big.NewRat(1, 58)) <= 0 {
+		t.Fatalf("TA should be more likely than 1/58, got %s", p)
+	}
+	if base58PrefixProbability(tron, "T1", true).Sign() != 0 || base58PrefixProbability(tron, "A", true).Sign() != 0 {
+		t.Fatalf("expected T1 and A to be impossible")
+	}
+	if err := ValidateBase58Pattern(ChainTron, "T1", true, true); err == nil {
+		t.Fatalf("expected an error for an impossible prefix")
+	}
+	if err := ValidateBase58Pattern(ChainTron, "T0", true, true); err == nil {
+		t.Fatalf("expected an error for a non-base58 character")
+	}
+	if err := ValidateBase58Pattern(ChainTron, "tron", true, false); err != nil {
+		t.Fatalf("unexpected error for a case-insensitive prefix: %v", err)
+	}
+}
+
+func TestRun_TronMatchesEncodedAddress(t *testing.T) {
+	resultCh := make(chan Result, 2)
+	cfg := Config{Chain: ChainTron, Prefix: "TT", Suffix: "a", Count: 2, Workers: 2, Fast: true}
+	Run(context.Background(), cfg, resultCh, &Stats{})
+	n := 0
+	for r := range resultCh {
+		n++
+		key, err := crypto.ToECDSA(r.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := TronAddress(crypto.PubkeyToAddress(key.PublicKey))
+		if r.Address != want || r.Checksum != want {
+			t.Fatalf("result %s / %s, key gives %s", r.Address, r.Checksum, want)
+		}
+		if !strings.HasPrefix(r.Address, "TT") || !strings.HasSuffix(strings.ToLower(r.Address), "a") {
+			t.Fatalf("result %s does not match TT…a", r.Address)
+		}
+	}
+	if n != 2 {
+		t.Fatalf("expected 2 results, got %d", n)
+	}
+	if Difficulty(cfg) == nil {
+		t.Fatalf("expected a difficulty for a Tron pattern")
+	}
+}
+
+func TestBitcoinAddress_KeyOne(t *testing.T) {
+	key := PrivateKey(common.LeftPadBytes([]byte{1}, 32))
+	k, err := crypto.ToECDSA(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pub [64]byte
+	copy(pub[:], crypto.FromECDSAPub(&k.PublicKey)[1:])
+	if got, want := BitcoinAddress(&pub), "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"; got != want {
+		t.Errorf("BitcoinAddress = %s, want %s", got, want)
+	}
+	if got, want := BitcoinWIF(key), "KwDiBf89QgGbjEhKnhXJuH7LrciVrZi3qYjgd9M7rFU73sVHnoWn"; got != want {
+		t.Errorf("BitcoinWIF = %s, want %s", got, want)
+	}
+}
+
+func TestBase58PrefixProbability_Bitcoin(t *testing.T) {
+	btc := base58Formats[ChainBitcoin]
+	if p := base58PrefixProbability(btc, "1", true); p.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Fatalf("every P2PKH address starts with 1, got %s", p)
+	}
+	// A second 1 is a leading zero byte in the hash.
+	if p := base58PrefixProbability(btc, "11", true); p.Cmp(big.NewRat(1, 256)) != 0 {
+		t.Fatalf("11 should be exactly 1/256, got %s", p)
+	}
+	if base58PrefixProbability(btc, "3", true).Sign() != 0 {
+		t.Fatalf("P2PKH addresses never start with 3")
+	}
+	sum := new(big.Rat)
+	for _, c := range base58Alphabet {
+		sum.Add(sum, base58PrefixProbability(btc, "1"+string(c), true))
+	}
+	if sum.Cmp(big.NewRat(1, 1)) != 0 {
+		t.Fatalf("second characters should cover every address, got %s", sum)
+	}
+}
+
+func TestRun_BitcoinMatchesP2PKH(t *testing.T) {
+	resultCh := make(chan Result, 1)
+	cfg := Config{Chain: ChainBitcoin, Prefix: "1a", Count: 1, Workers: 2}
+	Run(context.Background(), cfg, resultCh, &Stats{})
+	r, ok := <-resultCh
+	if !ok {
+		t.Fatal("expected a result")
+	}
+	k, err := crypto.ToECDSA(r.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pub [64]byte
+	copy(pub[:], crypto.FromECDSAPub(&k.PublicKey)[1:])
+	if want := BitcoinAddress(&pub); r.Address != want || !strings.HasPrefix(strings.ToLower(r.Address), "1a") {
+		t.Fatalf("result %s, key gives %s", r.Address, want)
+	}
+}
+
+func TestRun_OnProgressDoesNotStallSearch(t *testing.T) {
+	var calls atomic.Int64
+	block := make(chan struct{})
+	resultCh := make(chan Result, 1)
+	stats := &Stats{}
+	cfg := Config{
+		Prefix: "ffffffff", Workers: 2, Count: 1, Fast: true, MaxAttempts: 3 * progressStep,
+		OnProgress: func(total, found int64) {
+			if total < progressStep {
+				t.Errorf("called after only %d attempts", total)
+			}
+			calls.Add(1)
+			<-block // returns only once the workers are done
+		},
+	}
+	returned := make(chan struct{})
+	go func() {
+		Run(context.Background(), cfg, resultCh, stats)
+		close(returned)
+	}()
+	for stats.Total.Load() < 3*progressStep || stats.Workers.Load() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	select {
+	case <-returned:
+		t.Fatal("Run returned while a progress call was running")
+	case <-time.After(2 * progressPoll):
+	}
+	close(block)
+	<-returned
+	if calls.Load() != 1 {
+		t.Fatalf("expected exactly one (blocked) progress call, got %d", calls.Load())
+	}
+}
+
+// fakeAccelerator reports a fixed list of addresses as its candidates.
+type fakeAccelerator struct{ addrs []string }
+
+func (fakeAccelerator) Name() string { return "fake" }
+
+func (f fakeAccelerator) Search(ctx context.Context, cancel context.CancelFunc, cfg Config, m Matcher, resultCh chan<- Result, stats *Stats) {
+	for _, addr := range f.addrs {
+		stats.Total.Add(1)
+		if tag, ok := m.Match(addr); ok {
+			stats.Found.Add(1)
+			resultCh <- Result{Address: addr, Match: tag}
+		}
+	}
+}
+
+func TestRun_DelegatesToAccelerator(t *testing.T) {
+	acc := fakeAccelerator{addrs: []string{
+		"0xdead000000000000000000000000000000000001",
+		"0xbeef000000000000000000000000000000000002",
+		"0xdead000000000000000000000000000000000003",
+	}}
+	cfg := Config{Workers: 1, Prefix: "dead", Accelerator: acc}
+	resultCh := make(chan Result, 3)
+	stats := &Stats{}
+	Run(context.Background(), cfg, resultCh, stats)
+
+	var got []string
+	for r := range resultCh {
+		got = append(got, r.Address)
+	}
+	if want := []string{acc.addrs[0], acc.addrs[2]}; !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if stats.Total.Load() != 3 {
+		t.Fatalf("Total = %d, want 3", stats.Total.Load())
+	}
+}
+
+func TestLookupAccelerator(t *testing.T) {
+	if a, err := LookupAccelerator("cpu"); err != nil || a != CPU {
+		t.Fatalf("LookupAccelerator(cpu) = %v, %v; want CPU", a, err)
+	}
+	if _, err := LookupAccelerator("no-such"); err == nil {
+		t.Fatal("unknown accelerator accepted")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("registering cpu twice did not panic")
+		}
+	}()
+	RegisterAccelerator(CPU)
+}
+
+func TestRun_ScalerResizesPool(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	scaler := NewScaler(1)
+	cfg := Config{Workers: 1, Prefix: "ffffffffff", Fast: true, Scaler: scaler}
+	stats := &Stats{}
+	done := make(chan struct{})
+	go func() {
+		Run(ctx, cfg, make(chan Result, 1), stats)
+		close(done)
+	}()
+
+	waitWorkers := func(want int64) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for stats.Workers.Load() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("Workers = %d, want %d", stats.Workers.Load(), want)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitWorkers(1)
+	scaler.Set(3)
+	waitWorkers(3)
+	scaler.Set(0) // clamped to one
+	waitWorkers(1)
+	cancel()
+	<-done
+
+	if n := stats.Workers.Load(); n != 0 {
+		t.Fatalf("Workers after Run = %d, want 0", n)
+	}
+	if walks := stats.Walks(); len(walks) != 3 {
+		t.Fatalf("got %d walks, want one per worker ever started (3)", len(walks))
+	}
+}
+
+func TestBloom_NoFalseNegativesAndFewFalsePositives(t *testing.T) {
+	const n = 10_000
+	b := NewBloom(n, AvoidFalsePositives)
+	addr := make([]byte, 20)
+	for i := 0; i < n; i++ {
+		cryptorand.Read(addr)
+		b.Add(addr)
+		if !b.Has(addr) {
+			t.Fatalf("added address %x not found", addr)
+		}
+	}
+	hits := 0
+	for i := 0; i < 100_000; i++ {
+		cryptorand.Read(addr)
+		if b.Has(addr) {
+			hits++
+		}
+	}
+	if rate := float64(hits) / 100_000; rate > 3*AvoidFalsePositives {
+		t.Fatalf("false-positive rate %.4f, want about %.4f", rate, AvoidFalsePositives)
+	}
+}
+
+func TestBloom_FewFalsePositivesWithSharedBytes(t *testing.T) {
+	// A list of vanity addresses shares the bytes its searches pinned
+	// down, and so do the candidates checked against it.
+	const n = 10_000
+	vanity := func(addr []byte) {
+		cryptorand.Read(addr)
+		copy(addr, []byte{0xde, 0xad, 0xbe, 0xef})
+		copy(addr[17:], []byte{0xc0, 0xff, 0xee})
+	}
+	b := NewBloom(n, AvoidFalsePositives)
+	addr := make([]byte, 20)
+	for i := 0; i < n; i++ {
+		vanity(addr)
+		b.Add(addr)
+	}
+	hits := 0
+	for i := 0; i < 100_000; i++ {
+		vanity(addr)
+		if b.Has(addr) {
+			hits++
+		}
+	}
+	if rate := float64(hits) / 100_000; rate > 3*AvoidFalsePositives {
+		t.Fatalf("false-positive rate %.4f, want about %.4f", rate, AvoidFalsePositives)
+	}
+}
+
+func TestRun_AvoidSkipsListedAddresses(t *testing.T) {
+	avoid, err := ParseAvoidList(strings.NewReader("# taken\n0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resultCh := make(chan Result, 2)
+	cfg := Config{Workers: 1, Keys: strings.NewReader(fmt.Sprintf("%064x\n%064x\n", 1, 2)), Avoid: avoid}
+	Run(context.Background(), cfg, resultCh, &Stats{})
+
+	var got []string
+	for r := range resultCh {
+		got = append(got, r.Checksum)
+	}
+	if len(got) != 1 || got[0] != "0x2B5AD5c4795c026514f8317c7a215E218DcCD6cF" {
+		t.Fatalf("got %v, want only the address of key 2", got)
+	}
+	if _, err := ParseAvoidList(strings.NewReader("0xdead\n")); err == nil {
+		t.Fatal("short address accepted")
+	}
+}
+
+func TestAttemptsForConfidence(t *testing.T) {
+	cases := []struct {
+		d    int64
+		p    float64
+		want int64
+	}{
+		{16, 0.99, 74},            // 16·ln(100) = 73.7
+		{1_000_000, 0.5, 693_148}, // 1e6·ln(2) = 693147.2
+	}
+	for _, c := range cases {
+		got := AttemptsForConfidence(big.NewInt(c.d), c.p)
+		if got.Int64() != c.want {
+			t.Errorf("AttemptsForConfidence(%d, %v) = %s, want %d", c.d, c.p, got, c.want)
+		}
+		// The budget gives back the confidence it was made for.
+		if p := ProbabilityWithin(big.NewInt(c.d), 1, float64(got.Int64()), time.Second); math.Abs(p-c.p) > 0.01 {
+			t.Errorf("ProbabilityWithin over the budget = %v, want %v", p, c.p)
+		}
+	}
+}
+
+func TestConfig_JSONLeavesOutSecrets(t *testing.T) {
+	xpub, err := ParseXPub(bip32XPub0H)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{
+		Prefix:      "dead",
+		Workers:     4,
+		Brainwallet: "correct horse",
+		ResumeFrom:  []PrivateKey{make(PrivateKey, 32)},
+		XPub:        xpub,
+		Keys:        strings.NewReader("0x01"),
+	}
+	b, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, want := range []string{`"prefix":"dead"`, `"workers":4`, `"count":0`, `"xpub":"` + bip32XPub0H + `"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%s lacks %s", got, want)
+		}
+	}
+	if strings.Contains(got, "horse") || strings.Contains(got, "resume") || strings.Contains(got, "keys") {
+		t.Errorf("%s contains a secret or reader", got)
+	}
+}
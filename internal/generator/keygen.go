@@ -0,0 +1,308 @@
+package generator
+
+import (
+	"crypto/rand"
+	"io"
+	"sync/atomic"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"golang.org/x/crypto/chacha20"
+)
+
+// entropyBatch is how many private keys worth of randomness a keyGen reads
+// from its source in one call.
+const entropyBatch = 64
+
+// keyBatchSize is how many candidates a worker derives before hashing them
+// into addresses in one tight loop. Keccak is cheap next to the scalar
+// multiplication, so most of the gain comes from reusing one hasher instead
+// of the two allocations crypto.Keccak256 makes per address; see
+// BenchmarkKeyGen_Batch against BenchmarkKeyGen_GenerateKey.
+const keyBatchSize = 256
+
+// keyGen produces candidate keys for a single worker. It keeps its entropy
+// buffer, scalar and point between calls so that the hot loop in Run does not
+// allocate a fresh *ecdsa.PrivateKey and big.Int pair for every attempt the
+// way crypto.GenerateKey does.
+//
+// A keyGen is not safe for concurrent use; each worker owns one.
+type keyGen struct {
+	rand    io.Reader
+	entropy [32 * entropyBatch]byte
+	off     int
+
+	priv   [32]byte
+	pub    [64]byte
+	scheme keyScheme
+
+	hash   crypto.KeccakState
+	digest [32]byte
+
+	walk *walk
+}
+
+// keyBatch holds a run of candidates derived by keyGen.fill. Slots [0, n)
+// are valid. mnemonic is only filled in mnemonic mode, salt only in
+// brainwallet mode and index only in xpub mode, where priv stays zero.
+type keyBatch struct {
+	priv     [keyBatchSize][32]byte
+	pub      [keyBatchSize][64]byte
+	addr     [keyBatchSize]common.Address
+	mnemonic [keyBatchSize]string
+	salt     [keyBatchSize]uint64
+	index    [keyBatchSize]uint32
+	n        int
+}
+
+// newKeyGen returns a keyGen for keys of keyType (see Config.KeyType)
+// drawing entropy from r, or from a workerRand of its own if r is nil.
+func newKeyGen(r io.Reader, keyType string) *keyGen {
+	if r == nil {
+		r = newWorkerRand()
+	}
+	return &keyGen{
+		rand:   r,
+		off:    32 * entropyBatch,
+		scheme: newKeyScheme(keyType),
+		hash:   crypto.NewKeccakState(),
+	}
+}
+
+// next draws a new private key and computes its uncompressed public key.
+// Keys the scheme rejects, such as secp256k1 scalars of zero or at least
+// the group order, are redrawn, so every key is uniform over the valid
+// ones.
+func (g *keyGen) next() error {
+	for {
+		if g.off == len(g.entropy) {
+			if _, err := io.ReadFull(g.rand, g.entropy[:]); err != nil {
+				return err
+			}
+			g.off = 0
+		}
+		copy(g.priv[:], g.entropy[g.off:g.off+32])
+		clear(g.entropy[g.off : g.off+32])
+		g.off += 32
+
+		if g.scheme.publicKey(&g.priv, &g.pub) {
+			return nil
+		}
+	}
+}
+
+// address returns the Ethereum address of the current key. It is identical
+// to crypto.PubkeyToAddress for the same key.
+func (g *keyGen) address() common.Address {
+	return g.hashAddress(&g.pub)
+}
+
+// fill derives a full batch of keys and then hashes every public key with
+// the same reused keccak state. On error the batch is left empty.
+func (g *keyGen) fill(b *keyBatch) error {
+	b.n = 0
+	for i := range b.priv {
+		if err := g.next(); err != nil {
+			return err
+		}
+		b.priv[i] = g.priv
+		b.pub[i] = g.pub
+	}
+	for i := range b.pub {
+		b.addr[i] = g.hashAddress(&b.pub[i])
+	}
+	b.n = len(b.priv)
+	return nil
+}
+
+// filler returns the batch fill function matching cfg's key mode.
+func (g *keyGen) filler(cfg Config) func(*keyBatch) error {
+	switch {
+	case cfg.keys != nil:
+		return func(b *keyBatch) error { return g.fillStream(b, cfg.keys) }
+	case cfg.XPub != nil:
+		return g.xpubFiller(cfg)
+	case cfg.Brainwallet != "":
+		salts := cfg.salts
+		if salts == nil {
+			salts = new(atomic.Uint64)
+		}
+		return func(b *keyBatch) error { return g.fillBrainwallet(b, cfg.Brainwallet, salts) }
+	case cfg.Mnemonic:
+		words := cfg.MnemonicWords
+		if words == 0 {
+			words = DefaultMnemonicWords
+		}
+		path, err := ParseDerivationPath(cfg.DerivationPath)
+		if err != nil {
+			path = defaultDerivationPath
+		}
+		return func(b *keyBatch) error { return g.fillMnemonic(b, words, path) }
+	case cfg.Fast:
+		return g.fillIncremental
+	}
+	return g.fill
+}
+
+// wipe zeroes the key material held by g and b: the entropy buffer, the
+// workerRand key, the current scalar, the walk position and every private
+// key in the batch.
+func (g *keyGen) wipe(b *keyBatch) {
+	clear(g.entropy[:])
+	if r, ok := g.rand.(*workerRand); ok {
+		clear(r.key[:])
+	}
+	clear(g.priv[:])
+	g.scheme.wipe()
+	if g.walk != nil {
+		g.walk.scalar.Zero()
+	}
+	clear(b.priv[:])
+	clear(b.mnemonic[:])
+}
+
+// workerRand is a ChaCha20 keystream seeded from crypto/rand, so that each
+// worker draws keys without contending for the shared crypto/rand.Reader.
+// Every Read rekeys it from its own keystream before producing output (fast
+// key erasure), so the state it holds cannot reproduce earlier output, and
+// no key and nonce pair is ever used twice.
+//
+// A workerRand is not safe for concurrent use; each keyGen owns one.
+type workerRand struct {
+	key [chacha20.KeySize]byte
+}
+
+// newWorkerRand returns a workerRand seeded with 256 bits from crypto/rand,
+// which does not fail since Go 1.24.
+func newWorkerRand() *workerRand {
+	r := new(workerRand)
+	rand.Read(r.key[:])
+	return r
+}
+
+var workerNonce [chacha20.NonceSize]byte
+
+// Read fills p with keystream and moves to a new key. It never fails.
+func (r *workerRand) Read(p []byte) (int, error) {
+	c, err := chacha20.NewUnauthenticatedCipher(r.key[:], workerNonce[:])
+	if err != nil {
+		panic(err) // key and nonce have fixed, valid sizes
+	}
+	clear(r.key[:])
+	c.XORKeyStream(r.key[:], r.key[:])
+	clear(p)
+	c.XORKeyStream(p, p)
+	return len(p), nil
+}
+
+func (g *keyGen) hashAddress(pub *[64]byte) common.Address {
+	var addr common.Address
+	g.hash.Reset()
+	g.hash.Write(pub[:])
+	g.hash.Read(g.digest[:])
+	copy(addr[:], g.digest[12:])
+	return addr
+}
+
+// walk is the state of an incremental key walk (Config.Fast). Instead of a
+// full scalar multiplication per candidate, the walk adds the generator point
+// to the previous public key, so consecutive candidates have private keys
+// base, base+1, base+2, ... The scalar is tracked alongside the point and is
+// what ends up in Result.PrivateKey.
+type walk struct {
+	scalar secp256k1.ModNScalar
+	point  secp256k1.JacobianPoint // current public key, always affine
+
+	pts [keyBatchSize]secp256k1.JacobianPoint
+	acc [keyBatchSize]secp256k1.FieldVal
+}
+
+// basePoint is G in affine Jacobian form.
+var basePoint = func() secp256k1.JacobianPoint {
+	var one secp256k1.ModNScalar
+	var g secp256k1.JacobianPoint
+	one.SetInt(1)
+	secp256k1.ScalarBaseMultNonConst(&one, &g)
+	g.ToAffine()
+	return g
+}()
+
+// seedWalk starts g's walk at key instead of a random one, so its first
+// candidate is key+1. key must be a valid scalar (see ParsePrivateKey).
+func (g *keyGen) seedWalk(key PrivateKey) {
+	w := new(walk)
+	w.scalar.SetByteSlice(key)
+	secp256k1.ScalarBaseMultNonConst(&w.scalar, &w.point)
+	w.point.ToAffine()
+	g.walk = w
+}
+
+// walkPosition returns the key behind steps before the walk's current one,
+// or nil if the walk has not started.
+func (g *keyGen) walkPosition(behind int) PrivateKey {
+	if g.walk == nil {
+		return nil
+	}
+	var pos secp256k1.ModNScalar
+	pos.SetInt(uint32(behind)).Negate().Add(&g.walk.scalar)
+	key := make(PrivateKey, 32)
+	pos.PutBytesUnchecked(key)
+	pos.Zero()
+	return key
+}
+
+// fillIncremental derives a batch by stepping the walk forward one key per
+// slot. The first call seeds the walk from a fresh random key unless
+// seedWalk already has. All points of the batch are converted to affine form
+// with a single field inversion (Montgomery's trick) before hashing.
+func (g *keyGen) fillIncremental(b *keyBatch) error {
+	b.n = 0
+	if g.walk == nil {
+		if err := g.next(); err != nil {
+			return err
+		}
+		g.seedWalk(g.priv[:])
+	}
+	w := g.walk
+
+	var one secp256k1.ModNScalar
+	one.SetInt(1)
+	for i := range w.pts {
+		secp256k1.AddNonConst(&w.point, &basePoint, &w.pts[i])
+		w.point = w.pts[i]
+		w.scalar.Add(&one)
+		w.scalar.PutBytes(&b.priv[i])
+	}
+
+	// acc[i] = Z0 * Z1 * ... * Zi
+	w.acc[0].Set(&w.pts[0].Z)
+	for i := 1; i < len(w.pts); i++ {
+		w.acc[i].Mul2(&w.acc[i-1], &w.pts[i].Z).Normalize()
+	}
+	var inv, zInv, zInv2 secp256k1.FieldVal
+	inv.Set(&w.acc[len(w.acc)-1]).Inverse()
+	for i := len(w.pts) - 1; i >= 0; i-- {
+		if i > 0 {
+			zInv.Mul2(&inv, &w.acc[i-1]).Normalize()
+			inv.Mul(&w.pts[i].Z).Normalize()
+		} else {
+			zInv.Set(&inv)
+		}
+		p := &w.pts[i]
+		zInv2.SquareVal(&zInv)
+		p.X.Mul(&zInv2).Normalize()
+		p.Y.Mul(zInv2.Mul(&zInv)).Normalize()
+		p.Z.SetInt(1)
+		p.X.PutBytesUnchecked(b.pub[i][:32])
+		p.Y.PutBytesUnchecked(b.pub[i][32:])
+	}
+	// The last point becomes the affine base for the next batch.
+	w.point = w.pts[len(w.pts)-1]
+
+	for i := range b.pub {
+		b.addr[i] = g.hashAddress(&b.pub[i])
+	}
+	b.n = len(b.priv)
+	return nil
+}
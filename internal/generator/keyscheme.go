@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// KeyTypeSecp256k1 is the key type of Ethereum, and the default.
+const KeyTypeSecp256k1 = "secp256k1"
+
+// keyScheme derives public keys for one key type. A keyGen owns one, so it
+// may keep scratch state between calls. Adding a key type means adding a
+// keyScheme to keySchemes; the Fast walk and xpub search do curve
+// arithmetic of their own and stay secp256k1-only.
+type keyScheme interface {
+	// publicKey derives the public key of priv into pub, reporting false if
+	// priv is not a valid private key, to be redrawn or rejected.
+	publicKey(priv *[32]byte, pub *[64]byte) bool
+
+	// wipe zeroes any secret kept from the last call.
+	wipe()
+}
+
+// keySchemes makes a keyScheme for each key type Config.KeyType accepts.
+var keySchemes = map[string]func() keyScheme{
+	KeyTypeSecp256k1: func() keyScheme { return new(secp256k1Scheme) },
+}
+
+// KeyTypes returns the names of the supported key types, sorted.
+func KeyTypes() []string {
+	names := make([]string, 0, len(keySchemes))
+	for name := range keySchemes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ValidateKeyType reports whether name is a supported key type; empty
+// means the default.
+func ValidateKeyType(name string) error {
+	if _, ok := keySchemes[name]; name != "" && !ok {
+		return fmt.Errorf("unknown key type %q (supported: %s)", name, strings.Join(KeyTypes(), ", "))
+	}
+	return nil
+}
+
+// newKeyScheme returns a keyScheme for the key type name, which must be
+// valid; empty means the default.
+func newKeyScheme(name string) keyScheme {
+	if newScheme, ok := keySchemes[name]; ok {
+		return newScheme()
+	}
+	return keySchemes[KeyTypeSecp256k1]()
+}
+
+// secp256k1Scheme keeps its scalar and point between calls so that
+// deriving a key allocates nothing.
+type secp256k1Scheme struct {
+	scalar secp256k1.ModNScalar
+	point  secp256k1.JacobianPoint
+}
+
+// publicKey rejects scalars of zero or at least the group order, and
+// writes the uncompressed point without its 04 prefix.
+func (s *secp256k1Scheme) publicKey(priv *[32]byte, pub *[64]byte) bool {
+	if s.scalar.SetBytes(priv) != 0 || s.scalar.IsZero() {
+		return false
+	}
+	secp256k1.ScalarBaseMultNonConst(&s.scalar, &s.point)
+	s.point.ToAffine()
+	s.point.X.PutBytesUnchecked(pub[:32])
+	s.point.Y.PutBytesUnchecked(pub[32:])
+	return true
+}
+
+func (s *secp256k1Scheme) wipe() { s.scalar.Zero() }
@@ -0,0 +1,138 @@
+package generator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// ErrKeyStream is reported by Stats.Err when Run stops because Config.Keys
+// could not be read or held a line that is not a private key.
+var ErrKeyStream = errors.New("key stream")
+
+// keyChunk is a run of keys read from Config.Keys; keys [0, n) are valid.
+type keyChunk struct {
+	keys [keyBatchSize][32]byte
+	n    int
+}
+
+// keyStream hands the keys of Config.Keys to workers a batch at a time. A
+// single goroutine reads and parses them, so workers never contend for the
+// reader itself.
+type keyStream struct {
+	chunks chan *keyChunk
+	done   <-chan struct{}
+	// err is set before chunks is closed, so a worker that sees the close
+	// also sees it.
+	err error
+}
+
+// newKeyStream starts reading keys from r until it is exhausted or ctx is
+// cancelled.
+func newKeyStream(ctx context.Context, r io.Reader) *keyStream {
+	s := &keyStream{chunks: make(chan *keyChunk, 1), done: ctx.Done()}
+	go s.read(r)
+	return s
+}
+
+// read parses r one key per line: 64 hex digits, 0x optional. Blank lines
+// and lines starting with # are skipped. A read blocked on r, such as on a
+// quiet stdin, can outlive Run; the goroutine exits once it returns.
+func (s *keyStream) read(r io.Reader) {
+	defer close(s.chunks)
+	c := new(keyChunk)
+	defer func() { clear(c.keys[:]) }()
+	send := func() bool {
+		select {
+		case s.chunks <- c:
+			c = new(keyChunk)
+			return true
+		case <-s.done:
+			return false
+		}
+	}
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := bytes.TrimSpace(sc.Bytes())
+		if len(text) == 0 || text[0] == '#' {
+			continue
+		}
+		if err := parseKeyLine(text, &c.keys[c.n]); err != nil {
+			s.err = fmt.Errorf("%w: line %d: %v", ErrKeyStream, line, err)
+			return
+		}
+		c.n++
+		if c.n == len(c.keys) && !send() {
+			return
+		}
+	}
+	if err := sc.Err(); err != nil {
+		s.err = fmt.Errorf("%w: %v", ErrKeyStream, err)
+		return
+	}
+	if c.n > 0 {
+		send()
+	}
+}
+
+// parseKeyLine decodes a hex private key into priv and checks that it is a
+// valid secp256k1 scalar.
+func parseKeyLine(text []byte, priv *[32]byte) error {
+	if len(text) >= 2 && text[0] == '0' && (text[1] == 'x' || text[1] == 'X') {
+		text = text[2:]
+	}
+	if len(text) != 64 {
+		return fmt.Errorf("want 64 hex digits, got %d characters", len(text))
+	}
+	if _, err := hex.Decode(priv[:], text); err != nil {
+		clear(priv[:])
+		return errors.New("not a hex private key")
+	}
+	var k secp256k1.ModNScalar
+	overflow := k.SetBytes(priv) != 0
+	defer k.Zero()
+	if overflow || k.IsZero() {
+		clear(priv[:])
+		return errors.New("private key out of range")
+	}
+	return nil
+}
+
+// fillStream fills b with the next chunk of keys from s. It returns io.EOF
+// once s is exhausted or the search is over, and s's error if reading it
+// failed.
+func (g *keyGen) fillStream(b *keyBatch, s *keyStream) error {
+	b.n = 0
+	var c *keyChunk
+	select {
+	case next, ok := <-s.chunks:
+		if !ok {
+			if s.err != nil {
+				return s.err
+			}
+			return io.EOF
+		}
+		c = next
+	case <-s.done:
+		return io.EOF
+	}
+	defer clear(c.keys[:])
+
+	for i := 0; i < c.n; i++ {
+		// Keys were range-checked as they were read.
+		g.scheme.publicKey(&c.keys[i], &b.pub[i])
+		b.priv[i] = c.keys[i]
+	}
+	for i := 0; i < c.n; i++ {
+		b.addr[i] = g.hashAddress(&b.pub[i])
+	}
+	b.n = c.n
+	return nil
+}
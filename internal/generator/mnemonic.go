@@ -0,0 +1,188 @@
+package generator
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// DefaultMnemonicWords is the BIP-39 phrase length used when
+// Config.MnemonicWords is zero.
+const DefaultMnemonicWords = 12
+
+// mnemonicBatch is how many phrases a worker derives per batch. Each one
+// costs a 2048-round PBKDF2, so batches are kept small to stay responsive
+// to cancellation.
+const mnemonicBatch = 8
+
+// hardenedOffset marks a hardened BIP-32 child index.
+const hardenedOffset = 0x80000000
+
+// DefaultDerivationPath is the first account of most Ethereum wallets.
+const DefaultDerivationPath = "m/44'/60'/0'/0/0"
+
+// defaultDerivationPath is DefaultDerivationPath parsed.
+var defaultDerivationPath = []uint32{
+	44 + hardenedOffset, 60 + hardenedOffset, 0 + hardenedOffset, 0, 0,
+}
+
+// ParseDerivationPath parses a BIP-32 path such as "m/44'/60'/0'/0/0".
+// Hardened segments are marked with ' or h; each index must be below 2^31.
+func ParseDerivationPath(path string) ([]uint32, error) {
+	segments := strings.Split(strings.TrimSpace(path), "/")
+	if segments[0] != "m" {
+		return nil, fmt.Errorf("path must start with m/, got %q", path)
+	}
+	if len(segments) == 1 {
+		return nil, fmt.Errorf("path %q has no segments", path)
+	}
+	indices := make([]uint32, 0, len(segments)-1)
+	for _, seg := range segments[1:] {
+		num, hardened := seg, false
+		if s, ok := strings.CutSuffix(seg, "'"); ok {
+			num, hardened = s, true
+		} else if s, ok := strings.CutSuffix(seg, "h"); ok {
+			num, hardened = s, true
+		} else if s, ok := strings.CutSuffix(seg, "H"); ok {
+			num, hardened = s, true
+		}
+		n, err := strconv.ParseUint(num, 10, 32)
+		if err != nil || n >= hardenedOffset {
+			return nil, fmt.Errorf("invalid path segment %q", seg)
+		}
+		if hardened {
+			n += hardenedOffset
+		}
+		indices = append(indices, uint32(n))
+	}
+	return indices, nil
+}
+
+// FormatDerivationPath is the inverse of ParseDerivationPath, using ' for
+// hardened segments.
+func FormatDerivationPath(path []uint32) string {
+	var b strings.Builder
+	b.WriteByte('m')
+	for _, index := range path {
+		b.WriteByte('/')
+		if index >= hardenedOffset {
+			b.WriteString(strconv.FormatUint(uint64(index-hardenedOffset), 10))
+			b.WriteByte('\'')
+		} else {
+			b.WriteString(strconv.FormatUint(uint64(index), 10))
+		}
+	}
+	return b.String()
+}
+
+// MnemonicEntropyBits returns the BIP-39 entropy size for a phrase of words
+// words: 12, 15, 18, 21 or 24 words map to 128–256 bits.
+func MnemonicEntropyBits(words int) (int, error) {
+	switch words {
+	case 12, 15, 18, 21, 24:
+		return words / 3 * 32, nil
+	}
+	return 0, fmt.Errorf("mnemonic length must be 12, 15, 18, 21 or 24 words, got %d", words)
+}
+
+// newMnemonic draws entropy from r and encodes it as a BIP-39 phrase.
+func newMnemonic(r io.Reader, words int) (string, error) {
+	bits, err := MnemonicEntropyBits(words)
+	if err != nil {
+		return "", err
+	}
+	entropy := make([]byte, bits/8)
+	defer clear(entropy)
+	if _, err := io.ReadFull(r, entropy); err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// mnemonicKey derives the private key at path from a BIP-39 phrase with an
+// empty passphrase, as wallets do on import.
+func mnemonicKey(mnemonic string, path []uint32) ([32]byte, error) {
+	seed := bip39.NewSeed(mnemonic, "")
+	defer clear(seed)
+	return deriveHD(seed, path)
+}
+
+// deriveHD walks BIP-32 private derivation from seed along path.
+func deriveHD(seed []byte, path []uint32) ([32]byte, error) {
+	var key [32]byte
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	defer clear(sum)
+
+	var k secp256k1.ModNScalar
+	defer k.Zero()
+	if overflow := k.SetByteSlice(sum[:32]); overflow || k.IsZero() {
+		return key, fmt.Errorf("invalid master key")
+	}
+	chain := sum[32:]
+
+	data := make([]byte, 0, 37)
+	defer clear(data[:cap(data)])
+	for _, index := range path {
+		data = data[:0]
+		if index >= hardenedOffset {
+			data = append(data, 0)
+			k.PutBytes(&key)
+			data = append(data, key[:]...)
+		} else {
+			data = append(data, secp256k1.NewPrivateKey(&k).PubKey().SerializeCompressed()...)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac = hmac.New(sha512.New, chain)
+		mac.Write(data)
+		sum = mac.Sum(sum[:0])
+
+		var tweak secp256k1.ModNScalar
+		if overflow := tweak.SetByteSlice(sum[:32]); overflow {
+			return key, fmt.Errorf("invalid child key at index %d", index)
+		}
+		k.Add(&tweak)
+		tweak.Zero()
+		if k.IsZero() {
+			return key, fmt.Errorf("invalid child key at index %d", index)
+		}
+		chain = sum[32:]
+	}
+	k.PutBytes(&key)
+	return key, nil
+}
+
+// fillMnemonic derives a short batch of candidates from fresh BIP-39
+// phrases of the given length at path, recording each phrase alongside its
+// key.
+func (g *keyGen) fillMnemonic(b *keyBatch, words int, path []uint32) error {
+	b.n = 0
+	for i := 0; i < mnemonicBatch; i++ {
+		phrase, err := newMnemonic(g.rand, words)
+		if err != nil {
+			return err
+		}
+		priv, err := mnemonicKey(phrase, path)
+		if err != nil {
+			return err
+		}
+		if !g.scheme.publicKey(&priv, &b.pub[i]) {
+			return fmt.Errorf("derived key out of range")
+		}
+		b.priv[i] = priv
+		clear(priv[:])
+		b.mnemonic[i] = phrase
+		b.addr[i] = g.hashAddress(&b.pub[i])
+	}
+	b.n = mnemonicBatch
+	return nil
+}
@@ -0,0 +1,112 @@
+package generator
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ParseMod parses a modulus and remainder for Config.Mod and
+// Config.Remainder, in decimal or with a 0x prefix in hex. The modulus must
+// be between 1 and 2^160, the number of addresses there are, and the
+// remainder below it. An empty remainder means 0.
+func ParseMod(mod, remainder string) (*big.Int, *big.Int, error) {
+	n, ok := new(big.Int).SetString(strings.TrimSpace(mod), 0)
+	if !ok {
+		return nil, nil, fmt.Errorf("%q is not a whole number", mod)
+	}
+	if n.Sign() <= 0 || n.Cmp(addressSpace) > 0 {
+		return nil, nil, fmt.Errorf("modulus must be between 1 and 2^160, got %s", n)
+	}
+	r := new(big.Int)
+	if strings.TrimSpace(remainder) != "" {
+		if _, ok := r.SetString(strings.TrimSpace(remainder), 0); !ok {
+			return nil, nil, fmt.Errorf("%q is not a whole number", remainder)
+		}
+	}
+	if r.Sign() < 0 || r.Cmp(n) >= 0 {
+		return nil, nil, fmt.Errorf("remainder must be between 0 and %s", new(big.Int).Sub(n, big.NewInt(1)))
+	}
+	return n, r, nil
+}
+
+// ModMatcher returns a match function accepting hex addresses whose 20
+// bytes, read as a big-endian integer, leave remainder r when divided by n.
+func ModMatcher(n, r *big.Int) func(string) bool {
+	check := newModCheck(n, r)
+	return func(addr string) bool {
+		var b [common.AddressLength]byte
+		var digits [2 * common.AddressLength]byte
+		s := strings.TrimPrefix(addr, "0x")
+		if len(s) != len(digits) {
+			return false
+		}
+		copy(digits[:], s)
+		if _, err := hex.Decode(b[:], digits[:]); err != nil {
+			return false
+		}
+		return check.has(b[:])
+	}
+}
+
+// modFilter returns the Mod criterion of cfg as a check on the raw address
+// bytes, so that workers reject candidates before encoding them. It returns
+// nil when cfg has no Mod, when Invert turns rejects into matches, and for
+// base58 chains, whose encoded addresses ModMatcher never accepts.
+func modFilter(cfg Config) func(addr []byte) bool {
+	if cfg.Mod == nil || cfg.Invert || isBase58Chain(cfg.Chain) {
+		return nil
+	}
+	r := cfg.Remainder
+	if r == nil {
+		r = new(big.Int)
+	}
+	return newModCheck(cfg.Mod, r).has
+}
+
+// modCheck tests that 20 address bytes leave a remainder r when divided by
+// n. It runs for every candidate, so it does not allocate: a modulus that
+// fits in 64 bits is reduced a word at a time, and a larger one reuses
+// big.Ints from a pool.
+type modCheck struct {
+	n, r     *big.Int
+	n64, r64 uint64 // n and r when n fits in 64 bits, else n64 is 0
+	scratch  *sync.Pool
+}
+
+func newModCheck(n, r *big.Int) *modCheck {
+	m := &modCheck{n: n, r: r}
+	if n.IsUint64() {
+		m.n64, m.r64 = n.Uint64(), r.Uint64()
+	} else {
+		m.scratch = &sync.Pool{New: func() any { return new([2]big.Int) }}
+	}
+	return m
+}
+
+func (m *modCheck) has(addr []byte) bool {
+	if m.n64 != 0 {
+		rem := uint64(binary.BigEndian.Uint32(addr[:4])) % m.n64
+		rem = bits.Rem64(rem, binary.BigEndian.Uint64(addr[4:12]), m.n64)
+		rem = bits.Rem64(rem, binary.BigEndian.Uint64(addr[12:20]), m.n64)
+		return rem == m.r64
+	}
+	s := m.scratch.Get().(*[2]big.Int)
+	defer m.scratch.Put(s)
+	v, q := &s[0], &s[1]
+	v.SetBytes(addr)
+	q.QuoRem(v, m.n, v)
+	return v.Cmp(m.r) == 0
+}
+
+// modProbability returns the chance that a random address leaves a given
+// remainder modulo n: 1/n, which is exact to within 1 part in 2^160/n.
+func modProbability(n *big.Int) *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(1), n)
+}
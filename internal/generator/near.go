@@ -0,0 +1,70 @@
+package generator
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// addressNibbles is the number of hex characters in an address body.
+const addressNibbles = 40
+
+// ValidateNearTarget checks that target is a full 40-nibble hex address,
+// with or without the 0x prefix.
+func ValidateNearTarget(target string) error {
+	bare := strings.TrimPrefix(strings.TrimPrefix(target, "0x"), "0X")
+	if len(bare) != addressNibbles {
+		return fmt.Errorf("address must be %d hex characters, got %d", addressNibbles, len(bare))
+	}
+	for i := 0; i < len(bare); i++ {
+		if !isHex(bare[i]) {
+			return fmt.Errorf("invalid character %q in address", bare[i])
+		}
+	}
+	return nil
+}
+
+// NearMatcher returns a match function accepting addresses whose body differs
+// from target in at most maxDistance nibble positions (Hamming distance).
+// Comparison ignores letter case.
+func NearMatcher(target string, maxDistance int) func(string) bool {
+	want := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(target, "0x"), "0X"))
+	return func(addr string) bool {
+		bare := strings.TrimPrefix(addr, "0x")
+		if len(bare) != len(want) {
+			return false
+		}
+		dist := 0
+		for i := 0; i < len(bare); i++ {
+			c := bare[i]
+			if c >= 'A' && c <= 'F' {
+				c += 'a' - 'A'
+			}
+			if c != want[i] {
+				dist++
+				if dist > maxDistance {
+					return false
+				}
+			}
+		}
+		return true
+	}
+}
+
+// nearProbability returns the chance that a random address lies within
+// maxDistance nibbles of a fixed target:
+//
+//	Σ_{k=0..maxDistance} C(40, k) · 15^k / 16^40
+func nearProbability(maxDistance int) *big.Rat {
+	if maxDistance > addressNibbles {
+		maxDistance = addressNibbles
+	}
+	count := new(big.Int)
+	for k := 0; k <= maxDistance; k++ {
+		term := new(big.Int).Binomial(addressNibbles, int64(k))
+		term.Mul(term, new(big.Int).Exp(big.NewInt(15), big.NewInt(int64(k)), nil))
+		count.Add(count, term)
+	}
+	space := new(big.Int).Exp(big.NewInt(16), big.NewInt(addressNibbles), nil)
+	return new(big.Rat).SetFrac(count, space)
+}
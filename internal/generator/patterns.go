@@ -0,0 +1,139 @@
+package generator
+
+import (
+	"math/big"
+	"strings"
+	"sync/atomic"
+)
+
+// Pattern is one of several alternative searches in Config.Patterns. ID
+// names it in Result.Pattern; Prefix, Suffix and Contains are hex patterns
+// as in Config. Patterns search Ethereum addresses only.
+type Pattern struct {
+	ID       string `json:"id"`
+	Prefix   string `json:"prefix,omitempty"`
+	Suffix   string `json:"suffix,omitempty"`
+	Contains string `json:"contains,omitempty"`
+}
+
+// patternSet is Config.Patterns compiled for matching, with one result
+// counter per pattern.
+type patternSet struct {
+	ids   []string
+	match []func(string) bool
+	found []atomic.Int64
+}
+
+func newPatternSet(cfg Config) *patternSet {
+	s := &patternSet{
+		ids:   make([]string, len(cfg.Patterns)),
+		match: make([]func(string) bool, len(cfg.Patterns)),
+		found: make([]atomic.Int64, len(cfg.Patterns)),
+	}
+	for i, p := range cfg.Patterns {
+		s.ids[i] = p.ID
+		s.match[i] = BuildMatcher(p.Prefix, p.Suffix, p.Contains, nil, nil, cfg.CaseSensitive)
+	}
+	return s
+}
+
+// patternMatch returns match followed by set, if not nil: an address must
+// also match one of its patterns, whose index is returned and whose ID leads
+// the tag. Without set the index is always -1.
+func patternMatch(set *patternSet, match func(string) (string, bool)) func(string) (string, int, bool) {
+	if set == nil {
+		return func(addr string) (string, int, bool) {
+			tag, ok := match(addr)
+			return tag, -1, ok
+		}
+	}
+	return func(addr string) (string, int, bool) {
+		tag, ok := match(addr)
+		if !ok {
+			return "", -1, false
+		}
+		i := set.first(addr)
+		if i < 0 {
+			return "", -1, false
+		}
+		if tag != "" {
+			return set.ids[i] + "+" + tag, i, true
+		}
+		return set.ids[i], i, true
+	}
+}
+
+// first returns the index of the first pattern addr matches, or -1.
+func (s *patternSet) first(addr string) int {
+	for i, m := range s.match {
+		if m(addr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// PrefixAlternatives returns the alternatives prefix expands to, in order
+// and without duplicates, lowercased unless caseSensitive: the groups
+// Config.CountPerAlt counts results in. It returns nil for an empty or
+// invalid prefix.
+func PrefixAlternatives(prefix string, caseSensitive bool) []string {
+	if !caseSensitive {
+		prefix = strings.ToLower(prefix)
+	}
+	alts, err := compileHexPattern(prefix)
+	if err != nil {
+		return nil
+	}
+	return alts
+}
+
+// prefixQuota is Config.Prefix split into its alternatives, with one result
+// counter each, for Config.CountPerAlt.
+type prefixQuota struct {
+	alts          []string
+	found         []atomic.Int64
+	caseSensitive bool
+}
+
+func newPrefixQuota(cfg Config) *prefixQuota {
+	alts := PrefixAlternatives(cfg.Prefix, cfg.CaseSensitive)
+	if len(alts) == 0 {
+		alts = []string{""} // one group, matching everything
+	}
+	return &prefixQuota{alts: alts, found: make([]atomic.Int64, len(alts)), caseSensitive: cfg.CaseSensitive}
+}
+
+// claim takes one of count slots for the first alternative addr starts with
+// that still has one, and returns its index, or -1 if every alternative addr
+// starts with is full.
+func (q *prefixQuota) claim(addr string, count int) int {
+	body := strings.TrimPrefix(addr, "0x")
+	if !q.caseSensitive {
+		body = strings.ToLower(body)
+	}
+	for i, alt := range q.alts {
+		if strings.HasPrefix(body, alt) {
+			if _, ok := claimSlot(&q.found[i], count); ok {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// patternsProbability returns the chance that a random address matches at
+// least one of cfg.Patterns, treating them as independent: 1 - Π(1 - p).
+// A pattern with no criteria matches everything.
+func patternsProbability(cfg Config) *big.Rat {
+	one := big.NewRat(1, 1)
+	none := big.NewRat(1, 1)
+	for _, pat := range cfg.Patterns {
+		p := hexProbability(pat.Prefix, pat.Suffix, pat.Contains, cfg.CaseSensitive)
+		if p == nil {
+			return one
+		}
+		none.Mul(none, new(big.Rat).Sub(one, p))
+	}
+	return new(big.Rat).Sub(one, none)
+}
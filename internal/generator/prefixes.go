@@ -0,0 +1,196 @@
+package generator
+
+import (
+	"io"
+	"math/big"
+	"strings"
+)
+
+// ParsePrefixList reads one hex prefix per line, in the same format as
+// ParseWordList.
+func ParsePrefixList(r io.Reader) ([]string, error) {
+	return parseHexLines(r, "prefix list")
+}
+
+// trieFanout is the number of distinct characters a hex pattern can hold
+// when case matters: 0-9, a-f and A-F.
+const trieFanout = 22
+
+// prefixTrie matches an address body against many prefixes at once, in
+// time proportional to the longest prefix rather than to their number.
+type prefixTrie struct {
+	root trieNode
+}
+
+type trieNode struct {
+	next [trieFanout]*trieNode
+	// end is the prefix spelled by the path to this node, if one does.
+	end string
+}
+
+// trieIndex maps a hex character to its child slot, or -1.
+func trieIndex(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'F':
+		return int(c-'A') + 16
+	}
+	return -1
+}
+
+// newPrefixTrie builds a trie of prefixes, which must already be normalized
+// to the case they will be matched in. It returns nil for no prefixes, and
+// ignores empty ones and any with non-hex characters.
+func newPrefixTrie(prefixes []string) *prefixTrie {
+	var t *prefixTrie
+	for _, p := range prefixes {
+		if p == "" || strings.IndexFunc(p, func(r rune) bool { return r > 0x7f || trieIndex(byte(r)) < 0 }) >= 0 {
+			continue
+		}
+		if t == nil {
+			t = &prefixTrie{}
+		}
+		n := &t.root
+		for i := 0; i < len(p); i++ {
+			c := trieIndex(p[i])
+			if n.next[c] == nil {
+				n.next[c] = &trieNode{}
+			}
+			n = n.next[c]
+		}
+		if n.end == "" {
+			n.end = p
+		}
+	}
+	return t
+}
+
+// match returns the shortest prefix of bare in the trie.
+func (t *prefixTrie) match(bare string) (string, bool) {
+	n := &t.root
+	for i := 0; i < len(bare); i++ {
+		c := trieIndex(bare[i])
+		if c < 0 || n.next[c] == nil {
+			return "", false
+		}
+		n = n.next[c]
+		if n.end != "" {
+			return n.end, true
+		}
+	}
+	return "", false
+}
+
+// matchBytes reports whether the address whose raw bytes are addr starts
+// with one of the prefixes, reading nibbles straight from the bytes. The
+// trie must hold lowercase prefixes.
+func (t *prefixTrie) matchBytes(addr []byte) bool {
+	n := &t.root
+	for i := 0; i < 2*len(addr); i++ {
+		c := addr[i/2] >> 4
+		if i%2 == 1 {
+			c = addr[i/2] & 0x0f
+		}
+		if n = n.next[c]; n == nil {
+			return false
+		}
+		if n.end != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// probability returns the chance that a random address starts with one of
+// the prefixes. Prefixes that extend a shorter one are already covered by
+// it and are skipped, so the rest are disjoint and simply add up.
+func (t *prefixTrie) probability(caseSensitive bool) *big.Rat {
+	p := new(big.Rat)
+	var walk func(n *trieNode)
+	walk = func(n *trieNode) {
+		if n.end != "" {
+			if q := edgePatternProbability(n.end, true, caseSensitive); q != nil {
+				p.Add(p, q)
+			}
+			return
+		}
+		for _, child := range n.next {
+			if child != nil {
+				walk(child)
+			}
+		}
+	}
+	walk(&t.root)
+	return p
+}
+
+// PrefixMatcher returns a function reporting which of prefixes the address
+// body starts with, checking all of them in a single pass over the address.
+func PrefixMatcher(prefixes []string, caseSensitive bool) func(string) (string, bool) {
+	t := newPrefixTrie(normalizePrefixes(prefixes, caseSensitive))
+	return func(addr string) (string, bool) {
+		if t == nil {
+			return "", false
+		}
+		bare := strings.TrimPrefix(addr, "0x")
+		if !caseSensitive {
+			bare = strings.ToLower(bare)
+		}
+		return t.match(bare)
+	}
+}
+
+// prefixesProbability returns the chance that a random address starts with
+// any of prefixes.
+func prefixesProbability(prefixes []string, caseSensitive bool) *big.Rat {
+	t := newPrefixTrie(normalizePrefixes(prefixes, caseSensitive))
+	if t == nil {
+		return new(big.Rat)
+	}
+	return t.probability(caseSensitive)
+}
+
+func normalizePrefixes(prefixes []string, caseSensitive bool) []string {
+	if caseSensitive {
+		return prefixes
+	}
+	normalized := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		normalized[i] = strings.ToLower(p)
+	}
+	return normalized
+}
+
+// prefixFilter returns a check on the raw address bytes that rejects the
+// candidates the prefix criteria in cfg would, without hex-encoding them.
+// It ignores letter case, so what it passes must still go through the full
+// matcher. It returns nil when cfg has no hex prefix to check, or when
+// Invert turns rejects into matches.
+func prefixFilter(cfg Config) func(addr []byte) bool {
+	if cfg.Invert || isBase58Chain(cfg.Chain) {
+		return nil
+	}
+	var tries []*prefixTrie
+	if alts, _ := compileHexPattern(strings.ToLower(cfg.Prefix)); len(alts) > 0 {
+		tries = append(tries, newPrefixTrie(alts))
+	}
+	if len(cfg.Prefixes) > 0 {
+		if t := newPrefixTrie(normalizePrefixes(cfg.Prefixes, false)); t != nil {
+			tries = append(tries, t)
+		}
+	}
+	if len(tries) == 0 {
+		return nil
+	}
+	return func(addr []byte) bool {
+		for _, t := range tries {
+			if !t.matchBytes(addr) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,23 @@
+package generator
+
+// PreviewLayout places prefix, suffix and contains patterns in a 40-nibble
+// address skeleton for display. Each pattern takes the length of its
+// shortest alternative. The prefix is placed first and the suffix gets what
+// is left, so the lengths never add up to more than 40. The contains
+// pattern is centred in the gap between them. If it does not fit, it is
+// left out: containsLen is 0 and the whole gap is reported in
+// beforeContains. Callers can compare containsLen with MinHexPatternLen to
+// tell.
+func PreviewLayout(prefix, suffix, contains string) (prefixLen, beforeContains, containsLen, afterContains, suffixLen int) {
+	prefixLen = min(MinHexPatternLen(prefix), addressNibbles)
+	suffixLen = min(MinHexPatternLen(suffix), addressNibbles-prefixLen)
+	middle := addressNibbles - prefixLen - suffixLen
+
+	if n := MinHexPatternLen(contains); n > 0 && n <= middle {
+		containsLen = n
+		beforeContains = (middle - n) / 2
+		afterContains = middle - beforeContains - n
+		return
+	}
+	return prefixLen, middle, 0, 0, suffixLen
+}
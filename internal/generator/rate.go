@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"math"
+	"time"
+)
+
+// rateSmoothing is the time constant of RateSmoother: a change in speed is
+// about two thirds reflected after this long, a few ticks of either the TUI
+// or the CLI progress line.
+const rateSmoothing = time.Second
+
+// RateSmoother turns samples of a growing attempt count into an
+// exponentially weighted moving average of the rate. total/elapsed swings
+// wildly in the first second of a search and barely moves after an hour;
+// the average settles quickly and still follows throttling. The zero value
+// is ready to use.
+type RateSmoother struct {
+	rate      float64
+	lastTotal int64
+	last      time.Duration
+	primed    bool
+}
+
+// Update records that total attempts had been made after elapsed and returns
+// the smoothed rate per second. The first sample seeds the average with
+// total/elapsed; samples that do not move the clock forward are ignored.
+func (s *RateSmoother) Update(total int64, elapsed time.Duration) float64 {
+	if !s.primed {
+		if elapsed > 0 {
+			s.rate = float64(total) / elapsed.Seconds()
+			s.lastTotal, s.last, s.primed = total, elapsed, true
+		}
+		return s.rate
+	}
+	dt := elapsed - s.last
+	if dt <= 0 {
+		return s.rate
+	}
+	sample := float64(total-s.lastTotal) / dt.Seconds()
+	// Weighting by dt keeps the time constant the same whatever the tick.
+	alpha := 1 - math.Exp(-float64(dt)/float64(rateSmoothing))
+	s.rate += alpha * (sample - s.rate)
+	s.lastTotal, s.last = total, elapsed
+	return s.rate
+}
+
+// Rate returns the smoothed rate per second, 0 before the first Update.
+func (s *RateSmoother) Rate() float64 { return s.rate }
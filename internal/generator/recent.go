@@ -0,0 +1,87 @@
+package generator
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Recent keeps the last Max results added to it in a ring, so that a
+// search with no end holds a bounded number in memory; Max 0 keeps them
+// all. The private key of each result it drops is wiped, so drop nothing
+// still in use elsewhere. The zero value keeps everything.
+type Recent struct {
+	Max int
+
+	buf     []Result
+	next    int // where the next result goes once buf is full
+	dropped int
+}
+
+// Add keeps r, dropping and returning the oldest result once Max are kept.
+func (k *Recent) Add(r Result) (dropped Result, ok bool) {
+	if k.buf == nil && k.Max > 0 {
+		// Room for a modest Max at once; a larger one grows as needed.
+		k.buf = make([]Result, 0, min(k.Max, 1024))
+	}
+	if k.Max <= 0 || len(k.buf) < k.Max {
+		k.buf = append(k.buf, r)
+		return Result{}, false
+	}
+	dropped = k.buf[k.next]
+	dropped.PrivateKey.Zero()
+	k.buf[k.next] = r
+	k.next = (k.next + 1) % k.Max
+	k.dropped++
+	return dropped, true
+}
+
+// Results returns the kept results, oldest first, in a new slice.
+func (k *Recent) Results() []Result {
+	out := make([]Result, 0, len(k.buf))
+	out = append(out, k.buf[k.next:]...)
+	return append(out, k.buf[:k.next]...)
+}
+
+// Len returns how many results are kept.
+func (k *Recent) Len() int { return len(k.buf) }
+
+// Dropped returns how many results have been dropped to make room.
+func (k *Recent) Dropped() int { return k.dropped }
+
+// Seen is the set of addresses a results loop has already reported. It is
+// kept apart from Recent so that a repeat is still caught after Recent has
+// dropped the first one: it holds only each address's 20 bytes, not the
+// result, and is never trimmed. The zero value is ready to use.
+type Seen struct {
+	addrs map[common.Address]struct{}
+}
+
+// Add records addr, a hex or base58check address (the Checksum of a
+// Result), and reports whether it was new.
+func (s *Seen) Add(addr string) bool {
+	if s.addrs == nil {
+		s.addrs = make(map[common.Address]struct{})
+	}
+	key := seenKey(addr)
+	if _, ok := s.addrs[key]; ok {
+		return false
+	}
+	s.addrs[key] = struct{}{}
+	return true
+}
+
+// Len returns how many addresses have been recorded.
+func (s *Seen) Len() int { return len(s.addrs) }
+
+// seenKey returns the 20 bytes addr encodes: the hex address itself, or
+// the payload after the version byte of a Tron or Bitcoin address. Anything
+// else is keyed by a hash of its text.
+func seenKey(addr string) common.Address {
+	if common.IsHexAddress(addr) {
+		return common.HexToAddress(addr)
+	}
+	if b, err := base58CheckDecode(addr); err == nil && len(b) == 1+common.AddressLength {
+		return common.BytesToAddress(b[1:])
+	}
+	return common.BytesToAddress(crypto.Keccak256([]byte(addr)))
+}
@@ -0,0 +1,120 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// SynthesizeMatch returns a random 0x address that satisfies cfg's prefix,
+// suffix, contains, word and spelling criteria by construction: the required
+// nibbles are fixed and the rest are random. It has no private key and is
+// meant only to show what a match looks like. Exclude patterns and the
+// Blocklist are honored by drawing again. Regex, near, mod and inverted
+// searches cannot be synthesized this way and return an error.
+func SynthesizeMatch(cfg Config) (string, error) {
+	if len(cfg.Exclude) == 0 && len(cfg.Blocklist) == 0 {
+		return synthesize(cfg)
+	}
+	match := BuildMatcher("", "", "", nil, nil, cfg.CaseSensitive, cfg.Exclude...)
+	clean := BuildMatcher("", "", "", nil, nil, false, cfg.Blocklist...)
+	for i := 0; i < maxSynthesizeDraws; i++ {
+		addr, err := synthesize(cfg)
+		if err != nil || match(addr) && clean(addr) {
+			return addr, err
+		}
+	}
+	return "", fmt.Errorf("cannot synthesize a sample that avoids the excluded patterns")
+}
+
+// maxSynthesizeDraws bounds the redraws SynthesizeMatch makes to avoid
+// excluded patterns.
+const maxSynthesizeDraws = 10000
+
+func synthesize(cfg Config) (string, error) {
+	switch {
+	case cfg.Regex != "" || cfg.RegexBody != "":
+		return "", fmt.Errorf("cannot synthesize a sample for a regex pattern")
+	case cfg.Near != "":
+		return "", fmt.Errorf("cannot synthesize a sample for --near")
+	case cfg.Mod != nil:
+		return "", fmt.Errorf("cannot synthesize a sample for --mod")
+	case cfg.Invert:
+		return "", fmt.Errorf("cannot synthesize a sample for an inverted pattern")
+	case isBase58Chain(cfg.Chain):
+		return "", fmt.Errorf("cannot synthesize a sample for a base58 chain")
+	case cfg.MinScore > 0:
+		return "", fmt.Errorf("cannot synthesize a sample for a score threshold")
+	case cfg.Prefix != "" && len(cfg.Prefixes) > 0:
+		return "", fmt.Errorf("cannot synthesize a sample for both a prefix and a prefix list")
+	case len(cfg.Patterns) > 0 && (cfg.Prefix != "" || cfg.Suffix != "" || cfg.Contains != ""):
+		return "", fmt.Errorf("cannot synthesize a sample for both a pattern and a pattern set")
+	}
+	if len(cfg.Patterns) > 0 {
+		p := cfg.Patterns[rand.IntN(len(cfg.Patterns))]
+		cfg.Prefix, cfg.Suffix, cfg.Contains = p.Prefix, p.Suffix, p.Contains
+	}
+
+	pick := func(pattern string) (string, error) {
+		alts, err := compileHexPattern(pattern)
+		if err != nil || len(alts) == 0 {
+			return "", err
+		}
+		return alts[rand.IntN(len(alts))], nil
+	}
+	prefix, err := pick(cfg.Prefix)
+	if err != nil {
+		return "", fmt.Errorf("prefix: %v", err)
+	}
+	if len(cfg.Prefixes) > 0 {
+		prefix = cfg.Prefixes[rand.IntN(len(cfg.Prefixes))]
+	}
+	suffix, err := pick(cfg.Suffix)
+	if err != nil {
+		return "", fmt.Errorf("suffix: %v", err)
+	}
+	var middle []string
+	contains, err := pick(cfg.Contains)
+	if err != nil {
+		return "", fmt.Errorf("contains: %v", err)
+	}
+	if contains != "" {
+		middle = append(middle, contains)
+	}
+	if len(cfg.Words) > 0 {
+		middle = append(middle, cfg.Words[rand.IntN(len(cfg.Words))])
+	}
+	if len(cfg.Spellings) > 0 {
+		middle = append(middle, cfg.Spellings[rand.IntN(len(cfg.Spellings))])
+	}
+
+	free := addressNibbles - len(prefix) - len(suffix)
+	for _, m := range middle {
+		free -= len(m)
+	}
+	if free < 0 {
+		return "", fmt.Errorf("pattern needs more than %d hex characters", addressNibbles)
+	}
+
+	const digits = "0123456789abcdef"
+	var b strings.Builder
+	b.WriteString("0x")
+	b.WriteString(prefix)
+	randomHex := func(n int) {
+		for i := 0; i < n; i++ {
+			b.WriteByte(digits[rand.IntN(16)])
+		}
+	}
+	// Spread the free nibbles randomly over the gaps around each middle
+	// segment so contains patterns don't always sit in the same place.
+	rand.Shuffle(len(middle), func(i, j int) { middle[i], middle[j] = middle[j], middle[i] })
+	for _, m := range middle {
+		gap := rand.IntN(free + 1)
+		randomHex(gap)
+		free -= gap
+		b.WriteString(m)
+	}
+	randomHex(free)
+	b.WriteString(suffix)
+	return b.String(), nil
+}
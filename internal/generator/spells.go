@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LeetTable maps a letter to the hex digits that may stand in for it when
+// spelling a word, e.g. 'o' → "0" or 'e' → "e3".
+type LeetTable map[rune]string
+
+// DefaultLeet covers a-f as themselves plus the usual digit look-alikes.
+var DefaultLeet = LeetTable{
+	'a': "a4", 'b': "b8", 'c': "c", 'd': "d", 'e': "e3", 'f': "f",
+	'g': "9", 'i': "1", 'l': "1", 'o': "0", 's': "5", 't': "7", 'z': "2",
+}
+
+// maxSpellings caps how many hex spellings one word may expand to.
+const maxSpellings = 4096
+
+// ParseLeetTable parses comma-separated letter=digits entries, e.g.
+// "o=0,e=e3", on top of DefaultLeet. An entry replaces the default for that
+// letter.
+func ParseLeetTable(s string) (LeetTable, error) {
+	table := make(LeetTable, len(DefaultLeet))
+	for k, v := range DefaultLeet {
+		table[k] = v
+	}
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		letter, digits, ok := strings.Cut(entry, "=")
+		letter = strings.ToLower(strings.TrimSpace(letter))
+		digits = strings.ToLower(strings.TrimSpace(digits))
+		if !ok || len(letter) != 1 || digits == "" {
+			return nil, fmt.Errorf("invalid substitution %q (want letter=hexdigits)", entry)
+		}
+		for i := 0; i < len(digits); i++ {
+			if !isHex(digits[i]) {
+				return nil, fmt.Errorf("invalid substitution %q: %q is not hex", entry, digits[i])
+			}
+		}
+		table[rune(letter[0])] = digits
+	}
+	return table, nil
+}
+
+// SpellPattern turns word into a grouped hex pattern under table, e.g.
+// "dose" → "(d)(0)(5)(e|3)". Every letter must have a substitution.
+func SpellPattern(word string, table LeetTable) (string, error) {
+	word = strings.ToLower(strings.TrimSpace(word))
+	if word == "" {
+		return "", fmt.Errorf("word is empty")
+	}
+	var b strings.Builder
+	for _, r := range word {
+		digits, ok := table[r]
+		if !ok || digits == "" {
+			return "", fmt.Errorf("%q cannot be spelled in hex", r)
+		}
+		b.WriteByte('(')
+		for i := 0; i < len(digits); i++ {
+			if i > 0 {
+				b.WriteByte('|')
+			}
+			b.WriteByte(digits[i])
+		}
+		b.WriteByte(')')
+	}
+	return b.String(), nil
+}
+
+// Spellings returns every hex string that spells word under table, expanded
+// through the same pattern compiler as --prefix and --contains.
+func Spellings(word string, table LeetTable) ([]string, error) {
+	pattern, err := SpellPattern(word, table)
+	if err != nil {
+		return nil, err
+	}
+	n := 1
+	for _, r := range strings.ToLower(strings.TrimSpace(word)) {
+		if n *= len(table[r]); n > maxSpellings {
+			return nil, fmt.Errorf("%q has more than %d spellings", word, maxSpellings)
+		}
+	}
+	return compileHexPattern(pattern)
+}
@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"bytes"
+	"crypto/sha256"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// weakKeyBits bounds the scalars isWeakScalar rejects: a key below 2^128, or
+// within 2^128 of the group order (whose negation is below 2^128), can be
+// found by a baby-step giant-step or kangaroo search in about 2^64 steps
+// instead of the ~2^128 a uniform key takes.
+const weakKeyBits = 128
+
+// knownWeakKeys are keys that are well known for reasons other than their
+// size: hashes of the empty string, the first thing brainwallet sweepers
+// try.
+var knownWeakKeys = [][]byte{
+	crypto.Keccak256(nil),
+	func() []byte { h := sha256.Sum256(nil); return h[:] }(),
+}
+
+// isWeakScalar reports whether the big-endian 32-byte private key priv is
+// trivially small or large (see weakKeyBits) or one of knownWeakKeys.
+func isWeakScalar(priv *[32]byte) bool {
+	const high = 32 - weakKeyBits/8
+	if isZero(priv[:high]) {
+		return true
+	}
+	var k secp256k1.ModNScalar
+	k.SetBytes(priv)
+	k.Negate()
+	neg := k.Bytes()
+	k.Zero()
+	defer clear(neg[:])
+	if isZero(neg[:high]) {
+		return true
+	}
+	for _, w := range knownWeakKeys {
+		if bytes.Equal(priv[:], w) {
+			return true
+		}
+	}
+	return false
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
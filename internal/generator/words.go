@@ -0,0 +1,94 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+)
+
+// DefaultWords is the built-in word list used by --wordlist builtin: English
+// words spelled with hex digits, plus a few common 0→o, 1→i/l, 5→s
+// substitutions.
+var DefaultWords = []string{
+	"dead", "beef", "cafe", "face", "babe", "bead", "fade", "feed", "deaf",
+	"bade", "cede", "abba", "added", "decade", "facade", "accede", "decaf",
+	"c0ffee", "c0de", "f00d", "0ff1ce", "ba5e", "5afe", "5eed", "10ad", "d1ce",
+}
+
+// DefaultBlocklist is the built-in list for Config.Blocklist: a few
+// offensive or unfortunate words spelled with hex digits and the usual
+// 0→o, 1→i/l, 5→s, 6→g, 7→t substitutions. It is short and catches only
+// the obvious; no list can rule out every reading of 40 hex digits.
+var DefaultBlocklist = []string{
+	"a55", "b00b", "fa6", "d1ld0", "1d107", "feca1", "5ad157", "666",
+}
+
+// ParseWordList reads one hex word per line. Blank lines and lines starting
+// with # are skipped; an optional 0x prefix is stripped.
+func ParseWordList(r io.Reader) ([]string, error) {
+	return parseHexLines(r, "word list")
+}
+
+// parseHexLines implements ParseWordList and ParsePrefixList; what names the
+// list in the error for an empty one.
+func parseHexLines(r io.Reader, what string) ([]string, error) {
+	var words []string
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		w := strings.TrimSpace(sc.Text())
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		w = strings.TrimPrefix(strings.TrimPrefix(w, "0x"), "0X")
+		for i := 0; i < len(w); i++ {
+			if !isHex(w[i]) {
+				return nil, fmt.Errorf("line %d: invalid character %q in %q", line, w[i], w)
+			}
+		}
+		words = append(words, w)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("%s is empty", what)
+	}
+	return words, nil
+}
+
+// WordMatcher returns a function reporting the first word from words that
+// the address body contains.
+func WordMatcher(words []string, caseSensitive bool) func(string) (string, bool) {
+	normalized := make([]string, len(words))
+	for i, w := range words {
+		if caseSensitive {
+			normalized[i] = w
+		} else {
+			normalized[i] = strings.ToLower(w)
+		}
+	}
+	return func(addr string) (string, bool) {
+		bare := strings.TrimPrefix(addr, "0x")
+		if !caseSensitive {
+			bare = strings.ToLower(bare)
+		}
+		return firstAlt(bare, normalized, strings.Contains)
+	}
+}
+
+// wordsProbability approximates the chance that an address contains at
+// least one of words as the union 1 - Π(1 - p_w), with each p_w estimated
+// the same way as a single --contains pattern.
+func wordsProbability(words []string, caseSensitive bool) *big.Rat {
+	miss := big.NewRat(1, 1)
+	for _, w := range words {
+		p := containsPatternProbabilityApprox(w, caseSensitive)
+		if p == nil {
+			continue
+		}
+		miss.Mul(miss, new(big.Rat).Sub(big.NewRat(1, 1), p))
+	}
+	return new(big.Rat).Sub(big.NewRat(1, 1), miss)
+}
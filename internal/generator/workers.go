@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cgroupRoot is where the cgroup filesystem is mounted.
+const cgroupRoot = "/sys/fs/cgroup"
+
+// DefaultWorkers returns the default number of search workers: GOMAXPROCS,
+// lowered to the container's CPU quota when a cgroup limit is set. On a host
+// with a quota, runtime.NumCPU reports every core and more workers than the
+// quota allows only add scheduling overhead.
+func DefaultWorkers() int {
+	return defaultWorkers(runtime.GOMAXPROCS(0), cgroupRoot)
+}
+
+func defaultWorkers(procs int, root string) int {
+	if quota, ok := cgroupCPUQuota(root); ok {
+		if n := int(math.Ceil(quota)); n < procs {
+			procs = n
+		}
+	}
+	return max(procs, 1)
+}
+
+// WorkerRate is one measurement taken by TuneWorkers.
+type WorkerRate struct {
+	Workers int
+	Rate    float64 // addresses per second
+}
+
+// TuneWorkers measures cfg's search rate for d at half, one and two times
+// DefaultWorkers and returns the fastest worker count along with every
+// measurement. Hyperthreaded and throttled CPUs make the best count hard to
+// guess; a second or two of measuring settles it.
+func TuneWorkers(cfg Config, d time.Duration) (int, []WorkerRate) {
+	var rates []WorkerRate
+	best := WorkerRate{Workers: DefaultWorkers()}
+	for _, n := range tuneCandidates(DefaultWorkers()) {
+		cfg.Workers = n
+		r := WorkerRate{Workers: n, Rate: MeasureRate(cfg, d)}
+		rates = append(rates, r)
+		if r.Rate > best.Rate {
+			best = r
+		}
+	}
+	return best.Workers, rates
+}
+
+// tuneCandidates returns the distinct worker counts TuneWorkers tries
+// around n, in increasing order.
+func tuneCandidates(n int) []int {
+	var out []int
+	for _, c := range []int{n / 2, n, n * 2} {
+		if c >= 1 && (len(out) == 0 || c > out[len(out)-1]) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// cgroupCPUQuota returns the CPU quota in cores from cgroup v2 (cpu.max) or
+// v1 (cpu.cfs_quota_us / cpu.cfs_period_us) under root. ok is false when no
+// quota is set or the files cannot be read.
+func cgroupCPUQuota(root string) (cores float64, ok bool) {
+	if data, err := os.ReadFile(filepath.Join(root, "cpu.max")); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) != 2 || fields[0] == "max" {
+			return 0, false
+		}
+		return quotaRatio(fields[0], fields[1])
+	}
+	quota, err := os.ReadFile(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		return 0, false
+	}
+	period, err := os.ReadFile(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+	if err != nil {
+		return 0, false
+	}
+	return quotaRatio(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+}
+
+func quotaRatio(quota, period string) (float64, bool) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 { // v1 uses -1 for "no limit"
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0, false
+	}
+	return q / p, true
+}
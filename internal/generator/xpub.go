@@ -0,0 +1,134 @@
+package generator
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+	"sync/atomic"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+)
+
+// BIP-32 extended key version bytes.
+const (
+	xpubVersion = 0x0488B21E // mainnet public
+	tpubVersion = 0x043587CF // testnet public
+	xprvVersion = 0x0488ADE4 // mainnet private
+	tprvVersion = 0x04358394 // testnet private
+)
+
+// XPub is a BIP-32 extended public key. Its non-hardened children can be
+// derived from it alone, so a search over them never sees a private key.
+type XPub struct {
+	text  string
+	depth uint8
+	chain [32]byte
+	key   [33]byte // compressed public key
+	point secp256k1.JacobianPoint
+}
+
+// ParseXPub parses a serialized extended public key (xpub… or tpub…).
+// Extended private keys are refused: they have no place on a machine
+// searching public keys.
+func ParseXPub(s string) (*XPub, error) {
+	s = strings.TrimSpace(s)
+	data, err := base58CheckDecode(s)
+	if err != nil {
+		return nil, fmt.Errorf("not an extended key: %v", err)
+	}
+	if len(data) != 78 {
+		return nil, fmt.Errorf("not an extended key: %d bytes, want 78", len(data))
+	}
+	switch binary.BigEndian.Uint32(data[:4]) {
+	case xpubVersion, tpubVersion:
+	case xprvVersion, tprvVersion:
+		return nil, errors.New("that is an extended private key; give the extended public key (xpub) instead")
+	default:
+		return nil, errors.New("unknown extended key version (want xpub or tpub)")
+	}
+	x := &XPub{text: s, depth: data[4]}
+	copy(x.chain[:], data[13:45])
+	copy(x.key[:], data[45:78])
+	pub, err := secp256k1.ParsePubKey(x.key[:])
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	pub.AsJacobian(&x.point)
+	return x, nil
+}
+
+// String returns x as it was parsed.
+func (x *XPub) String() string { return x.text }
+
+// MarshalText returns x as String does, so a Config marshals it as a
+// string.
+func (x *XPub) MarshalText() ([]byte, error) { return []byte(x.text), nil }
+
+// Depth returns how many derivation steps below a master key x is.
+func (x *XPub) Depth() int { return int(x.depth) }
+
+// child derives the uncompressed public key (X then Y) of x's non-hardened
+// child at index using mac, an HMAC-SHA512 keyed with x's chain code. It
+// reports false for the rare index BIP-32 says to skip.
+func (x *XPub) child(mac hash.Hash, index uint32, pub *[64]byte) bool {
+	var data [37]byte
+	copy(data[:], x.key[:])
+	binary.BigEndian.PutUint32(data[33:], index)
+	mac.Reset()
+	mac.Write(data[:])
+	var sum [64]byte
+	mac.Sum(sum[:0])
+
+	var tweak secp256k1.ModNScalar
+	if overflow := tweak.SetByteSlice(sum[:32]); overflow {
+		return false
+	}
+	var t, p secp256k1.JacobianPoint
+	secp256k1.ScalarBaseMultNonConst(&tweak, &t)
+	secp256k1.AddNonConst(&t, &x.point, &p)
+	if (p.X.IsZero() && p.Y.IsZero()) || p.Z.IsZero() {
+		return false
+	}
+	p.ToAffine()
+	p.X.PutBytesUnchecked(pub[:32])
+	p.Y.PutBytesUnchecked(pub[32:])
+	return true
+}
+
+// fillXPub fills b with x's children at the next indices taken from next,
+// which all workers share so no index is tried twice. It returns io.EOF
+// once the 2^31 non-hardened indices are used up.
+func (g *keyGen) fillXPub(b *keyBatch, x *XPub, mac hash.Hash, next *atomic.Uint64) error {
+	b.n = 0
+	start := next.Add(keyBatchSize) - keyBatchSize
+	for index := start; index < start+keyBatchSize && index < hardenedOffset; index++ {
+		if !x.child(mac, uint32(index), &b.pub[b.n]) {
+			continue
+		}
+		clear(b.priv[b.n][:])
+		b.index[b.n] = uint32(index)
+		b.n++
+	}
+	if b.n == 0 && start >= hardenedOffset {
+		return io.EOF
+	}
+	for i := 0; i < b.n; i++ {
+		b.addr[i] = g.hashAddress(&b.pub[i])
+	}
+	return nil
+}
+
+// xpubFiller returns the fill function for searching cfg.XPub.
+func (g *keyGen) xpubFiller(cfg Config) func(*keyBatch) error {
+	next := cfg.xpubNext
+	if next == nil {
+		next = new(atomic.Uint64)
+	}
+	mac := hmac.New(sha512.New, cfg.XPub.chain[:])
+	return func(b *keyBatch) error { return g.fillXPub(b, cfg.XPub, mac, next) }
+}
@@ -0,0 +1,29 @@
+// Package humanize formats numbers for people reading a terminal rather
+// than programs parsing output.
+package humanize
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// BigInt formats a large difficulty number (e.g. 16^8) compactly: 4.29B
+// rather than 4294967296. Past the trillions it switches to scientific
+// notation, which stays short however long the pattern.
+func BigInt(n *big.Int) string {
+	f, _ := new(big.Float).SetInt(n).Float64()
+	switch {
+	case f < 1_000:
+		return fmt.Sprintf("%.0f", f)
+	case f < 1_000_000:
+		return fmt.Sprintf("%.1fK", f/1e3)
+	case f < 1_000_000_000:
+		return fmt.Sprintf("%.2fM", f/1e6)
+	case f < 1_000_000_000_000:
+		return fmt.Sprintf("%.2fB", f/1e9)
+	case f < 1_000_000_000_000_000:
+		return fmt.Sprintf("%.2fT", f/1e12)
+	default:
+		return fmt.Sprintf("%.2e", f)
+	}
+}
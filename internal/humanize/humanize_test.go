@@ -0,0 +1,25 @@
+package humanize
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBigInt(t *testing.T) {
+	cases := []struct {
+		n    *big.Int
+		want string
+	}{
+		{big.NewInt(256), "256"},
+		{big.NewInt(65536), "65.5K"},
+		{big.NewInt(16777216), "16.78M"},
+		{big.NewInt(4581298449), "4.58B"},
+		{new(big.Int).Lsh(big.NewInt(1), 48), "281.47T"},
+		{new(big.Int).Lsh(big.NewInt(1), 160), "1.46e+48"},
+	}
+	for _, tc := range cases {
+		if got := BigInt(tc.n); got != tc.want {
+			t.Errorf("BigInt(%s) = %q, want %q", tc.n, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Package identicon reproduces the Ethereum "blockies" identicon: the 8x8
+// mirrored pattern and three HSL colors wallets draw for an address.
+package identicon
+
+import (
+	"math"
+	"strings"
+)
+
+// Size is the width and height of a blockie in cells.
+const Size = 8
+
+// Color is an HSL color as blockies produces it: hue in degrees [0, 360),
+// saturation and lightness in percent.
+type Color struct {
+	H, S, L float64
+}
+
+// Cell values in Identicon.Cells.
+const (
+	Background = 0
+	Foreground = 1
+	Spot       = 2
+)
+
+// Identicon is the blockie for one address.
+type Identicon struct {
+	Color, BgColor, SpotColor Color
+	Cells                     [Size * Size]uint8 // row-major; Background, Foreground or Spot
+}
+
+// rng is blockies' xorshift generator, with JavaScript's int32 semantics.
+type rng [4]int32
+
+func newRNG(seed string) *rng {
+	var r rng
+	for i := 0; i < len(seed); i++ {
+		r[i%4] = (r[i%4] << 5) - r[i%4] + int32(seed[i])
+	}
+	return &r
+}
+
+// next returns a float in [0, 2), exactly as blockies' rand() does.
+func (r *rng) next() float64 {
+	t := r[0] ^ (r[0] << 11)
+	r[0], r[1], r[2] = r[1], r[2], r[3]
+	r[3] = r[3] ^ (r[3] >> 19) ^ t ^ (t >> 8)
+	return float64(uint32(r[3])) / float64(uint32(1)<<31)
+}
+
+func (r *rng) color() Color {
+	h := math.Floor(r.next() * 360)
+	s := r.next()*60 + 40
+	l := (r.next() + r.next() + r.next() + r.next()) * 25
+	return Color{H: h, S: s, L: l}
+}
+
+// New computes the blockie for a 0x address. Wallets seed it with the
+// lowercase address, so the input's case does not matter.
+func New(address string) Identicon {
+	r := newRNG(strings.ToLower(address))
+	var id Identicon
+	id.Color = r.color()
+	id.BgColor = r.color()
+	id.SpotColor = r.color()
+
+	const half = Size / 2
+	for y := 0; y < Size; y++ {
+		for x := 0; x < half; x++ {
+			v := uint8(math.Floor(r.next() * 2.3))
+			id.Cells[y*Size+x] = v
+			id.Cells[y*Size+Size-1-x] = v
+		}
+	}
+	return id
+}
+
+// Similarity scores how alike two blockies look, from 0 to 1. Half of the
+// score is the share of cells with the same role, half the closeness of the
+// three colors.
+func Similarity(a, b Identicon) float64 {
+	same := 0
+	for i := range a.Cells {
+		if a.Cells[i] == b.Cells[i] {
+			same++
+		}
+	}
+	cells := float64(same) / float64(len(a.Cells))
+	colors := (colorSimilarity(a.Color, b.Color) +
+		colorSimilarity(a.BgColor, b.BgColor) +
+		colorSimilarity(a.SpotColor, b.SpotColor)) / 3
+	return (cells + colors) / 2
+}
+
+// colorSimilarity compares hue on the color wheel and saturation and
+// lightness on their ranges, weighting hue most since it dominates what the
+// eye notices.
+func colorSimilarity(a, b Color) float64 {
+	dh := math.Abs(a.H - b.H)
+	if dh > 180 {
+		dh = 360 - dh
+	}
+	hue := 1 - dh/180
+	sat := 1 - math.Min(math.Abs(a.S-b.S)/60, 1)
+	light := 1 - math.Min(math.Abs(a.L-b.L)/200, 1)
+	return 0.6*hue + 0.2*sat + 0.2*light
+}
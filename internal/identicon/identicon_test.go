@@ -0,0 +1,58 @@
+package identicon
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestNew_MatchesBlockies(t *testing.T) {
+	// Reference values from the ethereum-blockies JavaScript implementation.
+	cases := []struct {
+		addr            string
+		color, bg, spot Color
+		cells           string
+	}{
+		{
+			addr:  "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf",
+			color: Color{196, 66.63490621373057, 64.6822780254297},
+			bg:    Color{256, 40.03100843168795, 45.40765224955976},
+			spot:  Color{201, 82.2716146055609, 47.386629797983915},
+			cells: "1010010100011000111111110101101000111100001001002001100201111110",
+		},
+		{
+			addr:  "0xdeadbeef00112233445566778899aabbccddeeff",
+			color: Color{154, 75.18084633164108, 20.284305955283344},
+			bg:    Color{284, 43.88374011032283, 36.30386664299294},
+			spot:  Color{173, 50.14808293431997, 62.55730326520279},
+			cells: "1000000101111110121111210101101002100120111001110110011011100111",
+		},
+	}
+	for _, c := range cases {
+		id := New(c.addr)
+		for _, pair := range [][2]Color{{id.Color, c.color}, {id.BgColor, c.bg}, {id.SpotColor, c.spot}} {
+			got, want := pair[0], pair[1]
+			if got.H != want.H || math.Abs(got.S-want.S) > 1e-9 || math.Abs(got.L-want.L) > 1e-9 {
+				t.Fatalf("%s: color %+v, want %+v", c.addr, got, want)
+			}
+		}
+		var cells strings.Builder
+		for _, v := range id.Cells {
+			cells.WriteByte('0' + v)
+		}
+		if cells.String() != c.cells {
+			t.Fatalf("%s: cells %s, want %s", c.addr, cells.String(), c.cells)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	a := New("0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf")
+	if s := Similarity(a, New("0x7e5f4552091a69125d5dfcb7b8c2659029395bdf")); s != 1 {
+		t.Fatalf("same address (any case) should score 1, got %v", s)
+	}
+	b := New("0xdeadbeef00112233445566778899aabbccddeeff")
+	if s := Similarity(a, b); s <= 0 || s >= 1 {
+		t.Fatalf("different addresses should score in (0, 1), got %v", s)
+	}
+}
@@ -0,0 +1,84 @@
+package ledger
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"vanity-eth/internal/generator"
+)
+
+// JSONResult is the serialized form of a generator.Result shared by the
+// CLI JSON output, the --output file, the status server and the TUI save.
+type JSONResult struct {
+	Address    string `json:"address"`
+	PrivateKey string `json:"privateKey,omitempty"`
+	PublicKey  string `json:"publicKey,omitempty"`
+
+	// ReverseNode is the ENS namehash of <address>.addr.reverse.
+	ReverseNode string `json:"ensReverseNode,omitempty"`
+
+	// Proof is the key's signature of "vanity-eth:<address>"; see
+	// package proof.
+	Proof string `json:"proof,omitempty"`
+
+	Match   string `json:"match,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+
+	Mnemonic       string `json:"mnemonic,omitempty"`
+	MnemonicWords  int    `json:"mnemonicWords,omitempty"`
+	DerivationPath string `json:"derivationPath,omitempty"`
+
+	// BrainwalletSalt is set in brainwallet mode; the passphrase itself is
+	// never written out.
+	BrainwalletSalt *uint64 `json:"brainwalletSalt,omitempty"`
+
+	// XPub and XPubIndex are set in xpub mode, which has no private key.
+	XPub      string  `json:"xpub,omitempty"`
+	XPubIndex *uint32 `json:"xpubIndex,omitempty"`
+}
+
+// ToJSON is a display boundary: the key becomes an immutable hex string
+// here and can no longer be wiped.
+func ToJSON(r generator.Result) JSONResult {
+	j := JSONResult{Address: r.Address, Match: r.Match, Pattern: r.Pattern, Mnemonic: r.Mnemonic, DerivationPath: r.DerivationPath}
+	if r.Mnemonic != "" {
+		j.MnemonicWords = len(strings.Fields(r.Mnemonic))
+	}
+	if r.Passphrase != "" {
+		salt := r.Salt
+		j.BrainwalletSalt = &salt
+	}
+	if r.XPub != "" {
+		index := r.ChildIndex
+		j.XPub, j.XPubIndex = r.XPub, &index
+	}
+	if len(r.PrivateKey) > 0 {
+		j.PrivateKey = "0x" + r.PrivateKey.String()
+	}
+	if r.PublicKey != "" {
+		j.PublicKey = "0x" + r.PublicKey
+	}
+	if r.ReverseNode != "" {
+		j.ReverseNode = "0x" + r.ReverseNode
+	}
+	if r.Proof != "" {
+		j.Proof = "0x" + r.Proof
+	}
+	return j
+}
+
+// WriteJSON writes results as one indented JSON array.
+func WriteJSON(w io.Writer, results []generator.Result) error {
+	out := make([]JSONResult, len(results))
+	for i, r := range results {
+		out[i] = ToJSON(r)
+	}
+	return encodeJSON(w, out)
+}
+
+func encodeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,56 @@
+package ledger
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"vanity-eth/internal/generator"
+)
+
+// Scrypt cost of written keystores; tests lower it to keep them fast.
+var scryptN, scryptP = keystore.StandardScryptN, keystore.StandardScryptP
+
+// WriteKeystore encrypts each result's key with passphrase into its own
+// Web3 Secret Storage file in dir, named the way geth names them so the
+// directory can be used as a keystore as-is. It returns the written paths.
+func WriteKeystore(dir string, results []generator.Result, passphrase string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, r := range results {
+		priv, err := crypto.ToECDSA(r.PrivateKey)
+		if err != nil {
+			return paths, fmt.Errorf("%s: %v", r.Address, err)
+		}
+		id, err := uuid.NewRandom()
+		if err != nil {
+			return paths, err
+		}
+		key := &keystore.Key{Id: id, Address: crypto.PubkeyToAddress(priv.PublicKey), PrivateKey: priv}
+		data, err := keystore.EncryptKey(key, passphrase, scryptN, scryptP)
+		priv.D.SetInt64(0)
+		if err != nil {
+			return paths, err
+		}
+		path := filepath.Join(dir, keyFileName(key.Address))
+		if err := os.WriteFile(path, data, 0o600); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// keyFileName matches geth's UTC--<timestamp>--<address> naming.
+func keyFileName(addr common.Address) string {
+	ts := time.Now().UTC().Format("2006-01-02T15-04-05.000000000Z")
+	return "UTC--" + ts + "--" + hex.EncodeToString(addr[:])
+}
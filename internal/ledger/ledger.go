@@ -0,0 +1,138 @@
+// Package ledger writes found results in the formats shared by the CLI
+// --output file and the TUI save: plain text, JSON and keystore files. Text
+// ledgers can be appended to without breaking their #N numbering.
+package ledger
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"vanity-eth/internal/generator"
+)
+
+// WriteText writes results as numbered blocks starting at #first.
+func WriteText(w io.Writer, results []generator.Result, first int) error {
+	for i, r := range results {
+		fmt.Fprintf(w, "#%d\n", first+i)
+		if r.Pattern != "" {
+			fmt.Fprintf(w, "Pattern:     %s\n", r.Pattern)
+		}
+		fmt.Fprintf(w, "Address:     %s\n", r.Address)
+		if r.Match != "" {
+			fmt.Fprintf(w, "Match:       %s\n", r.Match)
+		}
+		if r.Mnemonic != "" {
+			fmt.Fprintf(w, "Mnemonic (%d words): %s\n", len(strings.Fields(r.Mnemonic)), r.Mnemonic)
+			fmt.Fprintf(w, "Path:        %s\n", r.DerivationPath)
+		}
+		if r.Passphrase != "" {
+			fmt.Fprintf(w, "Salt:        %d\n", r.Salt)
+		}
+		if r.PublicKey != "" {
+			fmt.Fprintf(w, "Public Key:  0x%s\n", r.PublicKey)
+		}
+		if r.ReverseNode != "" {
+			fmt.Fprintf(w, "ENS Reverse: 0x%s\n", r.ReverseNode)
+		}
+		if r.Proof != "" {
+			fmt.Fprintf(w, "Proof:       0x%s\n", r.Proof)
+		}
+		if r.XPub != "" {
+			fmt.Fprintf(w, "XPub:        %s\n", r.XPub)
+			if _, err := fmt.Fprintf(w, "Child index: %d\n\n", r.ChildIndex); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "Private Key: 0x%s\n\n", r.PrivateKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LastIndex returns the highest #N header in the text ledger at path, or 0
+// if the file is missing or has none.
+func LastIndex(path string) (int, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	last := 0
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if !strings.HasPrefix(line, "#") {
+			continue
+		}
+		if n, err := strconv.Atoi(line[1:]); err == nil && n > last {
+			last = n
+		}
+	}
+	return last, sc.Err()
+}
+
+// AppendText adds results to the text ledger at path, creating it if needed
+// and continuing its numbering. If writing fails, the file is cut back to
+// where it ended, so a failed call leaves no partial entry behind and can
+// be retried.
+func AppendText(path string, results []generator.Result) error {
+	last, err := LastIndex(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	err = WriteText(f, results, last+1)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		if terr := os.Truncate(path, info.Size()); terr != nil {
+			return fmt.Errorf("%v; a partial entry may remain: %v", err, terr)
+		}
+		return err
+	}
+	return nil
+}
+
+// sleep waits between Retry attempts; tests replace it.
+var sleep = time.Sleep
+
+// Retry calls write up to attempts times until it succeeds, waiting
+// backoff after the first failure and twice as long after each later one,
+// and returns the last error. It is meant for whole-file writes to
+// filesystems that fail transiently, such as network mounts; write must be
+// safe to repeat.
+func Retry(attempts int, backoff time.Duration, write func() error) error {
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			sleep(backoff)
+			backoff *= 2
+		}
+		if err = write(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
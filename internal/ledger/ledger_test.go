@@ -0,0 +1,127 @@
+package ledger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"vanity-eth/internal/generator"
+)
+
+func TestAppendText_ContinuesNumbering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.txt")
+	rs := []generator.Result{
+		{Address: "0xaa", PrivateKey: generator.PrivateKey{1}},
+		{Address: "0xbb", PrivateKey: generator.PrivateKey{2}},
+	}
+
+	// A missing file starts at #1; an empty one too.
+	if err := AppendText(path, rs); err != nil {
+		t.Fatalf("AppendText: %v", err)
+	}
+	if err := AppendText(path, rs[:1]); err != nil {
+		t.Fatalf("AppendText: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"#1\nAddress:     0xaa", "#2\nAddress:     0xbb", "#3\nAddress:     0xaa"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("ledger missing %q:\n%s", want, data)
+		}
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.txt")
+	if err := os.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := LastIndex(empty); err != nil || n != 0 {
+		t.Fatalf("empty file: got %d, %v", n, err)
+	}
+}
+
+func TestWriteKeystore_Decrypts(t *testing.T) {
+	scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	defer func() { scryptN, scryptP = keystore.StandardScryptN, keystore.StandardScryptP }()
+
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := crypto.PubkeyToAddress(priv.PublicKey)
+	rs := []generator.Result{{Address: addr.Hex(), PrivateKey: crypto.FromECDSA(priv)}}
+
+	dir := filepath.Join(t.TempDir(), "keystore")
+	paths, err := WriteKeystore(dir, rs, "hunter2")
+	if err != nil {
+		t.Fatalf("WriteKeystore: %v", err)
+	}
+	if len(paths) != 1 || !strings.HasSuffix(paths[0], strings.ToLower(addr.Hex()[2:])) {
+		t.Fatalf("paths = %v", paths)
+	}
+	data, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := keystore.DecryptKey(data, "hunter2")
+	if err != nil {
+		t.Fatalf("DecryptKey: %v", err)
+	}
+	if key.Address != addr || key.PrivateKey.D.Cmp(priv.D) != 0 {
+		t.Fatalf("decrypted %s, want %s", key.Address.Hex(), addr.Hex())
+	}
+	if _, err := keystore.DecryptKey(data, "wrong"); err == nil {
+		t.Fatal("wrong passphrase decrypted")
+	}
+}
+
+func TestWriteJSON_OmitsMissingKey(t *testing.T) {
+	var b strings.Builder
+	rs := []generator.Result{{Address: "0xaa", Pattern: "prefix aa"}}
+	if err := WriteJSON(&b, rs); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "privateKey") || !strings.Contains(b.String(), `"pattern": "prefix aa"`) {
+		t.Fatalf("unexpected JSON:\n%s", b.String())
+	}
+}
+
+func TestRetry_RecoversFromFailingFirstWrite(t *testing.T) {
+	// The directory is missing on the first attempt, as when a network
+	// mount has not come back yet, and appears during the backoff.
+	dir := filepath.Join(t.TempDir(), "mount")
+	path := filepath.Join(dir, "keys.txt")
+	var waits []time.Duration
+	defer func(old func(time.Duration)) { sleep = old }(sleep)
+	sleep = func(d time.Duration) {
+		waits = append(waits, d)
+		if len(waits) == 2 {
+			os.Mkdir(dir, 0o700)
+		}
+	}
+	tries := 0
+	err := Retry(4, 10*time.Millisecond, func() error {
+		tries++
+		return os.WriteFile(path, []byte("ok\n"), 0o600)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tries != 3 || len(waits) != 2 || waits[0] != 10*time.Millisecond || waits[1] != 20*time.Millisecond {
+		t.Fatalf("tries = %d, waits = %v; want 3 tries after 10ms and 20ms", tries, waits)
+	}
+
+	tries = 0
+	err = Retry(2, time.Millisecond, func() error {
+		tries++
+		return os.WriteFile(filepath.Join(dir, "missing", "keys.txt"), nil, 0o600)
+	})
+	if err == nil || tries != 2 {
+		t.Fatalf("err = %v after %d tries; want the last error after 2", err, tries)
+	}
+}
@@ -0,0 +1,79 @@
+// Package proof signs a found address with its own key, so that anyone can
+// check that whoever reported the address held the key, without seeing it.
+// The signature is an EIP-191 personal_sign over Message(address), the form
+// wallets and tools such as cast wallet verify already check.
+package proof
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Message returns the text signed for addr: "vanity-eth:" followed by the
+// EIP-55 checksum address, whatever the case or 0x of addr.
+func Message(addr string) string {
+	return "vanity-eth:" + common.HexToAddress(addr).Hex()
+}
+
+// hash is the EIP-191 hash of msg: keccak256 of "\x19Ethereum Signed
+// Message:\n", the decimal length of msg, and msg.
+func hash(msg string) []byte {
+	return crypto.Keccak256([]byte(fmt.Sprintf("\x19Ethereum Signed Message:\n%d%s", len(msg), msg)))
+}
+
+// Sign signs Message(addr) with the 32-byte private key and returns the
+// 65-byte signature r || s || v in hex without 0x, v being 27 or 28. The
+// signature is deterministic (RFC 6979), so one key signs an address the
+// same way every time.
+func Sign(key []byte, addr string) (string, error) {
+	priv, err := crypto.ToECDSA(key)
+	if err != nil {
+		return "", err
+	}
+	defer priv.D.SetInt64(0)
+	if crypto.PubkeyToAddress(priv.PublicKey) != common.HexToAddress(addr) {
+		return "", fmt.Errorf("the key does not produce %s", addr)
+	}
+	sig, err := crypto.Sign(hash(Message(addr)), priv)
+	if err != nil {
+		return "", err
+	}
+	sig[64] += 27
+	return hex.EncodeToString(sig), nil
+}
+
+// Recover returns the address that signed Message(addr) into sig, in hex
+// with or without 0x. v may be 0/1 or 27/28.
+func Recover(addr, sig string) (common.Address, error) {
+	b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(sig), "0x"), "0X"))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("not hex: %v", err)
+	}
+	if len(b) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("%d bytes, want %d", len(b), crypto.SignatureLength)
+	}
+	if b[64] >= 27 {
+		b[64] -= 27
+	}
+	pub, err := crypto.SigToPub(hash(Message(addr)), b)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Verify reports whether sig is addr's own signature of Message(addr).
+func Verify(addr, sig string) error {
+	signer, err := Recover(addr, sig)
+	if err != nil {
+		return err
+	}
+	if signer != common.HexToAddress(addr) {
+		return fmt.Errorf("signed by %s, not %s", signer.Hex(), common.HexToAddress(addr).Hex())
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package proof
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// keyOne is the private key 1, whose address is well known.
+const (
+	keyOne  = "0000000000000000000000000000000000000000000000000000000000000001"
+	addrOne = "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"
+	// sigOne is keyOne's signature of Message(addrOne); RFC 6979 makes it
+	// fixed, so any change to the message or hashing shows here.
+	sigOne = "88ed8bfc9f1060a299e396cacf3cad84859cf25993b7674729396529581916695dcb0d9b2ff7f5746804a24d9b1598518b38ed9beb5f6c4bd5d4005271c189c01b"
+)
+
+func TestMessage_IsChecksummed(t *testing.T) {
+	want := "vanity-eth:" + addrOne
+	for _, addr := range []string{addrOne, strings.ToLower(addrOne), strings.TrimPrefix(strings.ToLower(addrOne), "0x")} {
+		if got := Message(addr); got != want {
+			t.Errorf("Message(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestHash_IsPersonalSign(t *testing.T) {
+	msg := Message(addrOne)
+	if got, want := hash(msg), accounts.TextHash([]byte(msg)); hex.EncodeToString(got) != hex.EncodeToString(want) {
+		t.Fatalf("hash = %x, want the EIP-191 hash %x", got, want)
+	}
+}
+
+func TestSign_Vector(t *testing.T) {
+	key, _ := hex.DecodeString(keyOne)
+	sig, err := Sign(key, strings.ToLower(addrOne))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig != sigOne {
+		t.Fatalf("Sign = %s, want %s", sig, sigOne)
+	}
+	if err := Verify(addrOne, "0x"+sig); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+}
+
+func TestSign_RejectsOtherAddress(t *testing.T) {
+	key, _ := hex.DecodeString(keyOne)
+	if _, err := Sign(key, "0x0000000000000000000000000000000000000001"); err == nil {
+		t.Fatal("Sign accepted an address the key does not produce")
+	}
+}
+
+func TestRecover_AcceptsBothRecoveryIDs(t *testing.T) {
+	b, _ := hex.DecodeString(sigOne)
+	b[64] -= 27
+	signer, err := Recover(addrOne, hex.EncodeToString(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer != common.HexToAddress(addrOne) {
+		t.Fatalf("Recover = %s, want %s", signer.Hex(), addrOne)
+	}
+}
+
+func TestVerify_RejectsTampering(t *testing.T) {
+	b, _ := hex.DecodeString(sigOne)
+	b[10] ^= 1
+	cases := map[string][2]string{
+		"other address": {"0x2c7536E3605D9C16a7a3D7b1898e529396a65c23", sigOne},
+		"flipped bit":   {addrOne, hex.EncodeToString(b)},
+		"short":         {addrOne, sigOne[:128]},
+		"not hex":       {addrOne, "zz" + sigOne[2:]},
+	}
+	for name, c := range cases {
+		if err := Verify(c[0], c[1]); err == nil {
+			t.Errorf("%s: Verify accepted it", name)
+		}
+	}
+}
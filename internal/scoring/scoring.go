@@ -0,0 +1,97 @@
+// Package scoring rates how striking an address looks, as a weighted blend
+// of aesthetic metrics, for searches that want "anything good enough" rather
+// than a fixed pattern.
+package scoring
+
+import "strings"
+
+// Metric is one aesthetic property of an address. Score gets the 40 hex
+// characters of the body in lowercase and returns 0 for a typical random
+// address, more the rarer the property.
+type Metric struct {
+	Name   string
+	Weight int
+	Score  func(body string) int
+}
+
+// Metrics are the metrics Score blends. Leading zeros weigh double: each one
+// is as rare as a repeated nibble but also saves gas in calldata.
+var Metrics = []Metric{
+	{Name: "leading-zeros", Weight: 2, Score: LeadingZeros},
+	{Name: "runs", Weight: 1, Score: Runs},
+	{Name: "palindrome", Weight: 1, Score: Palindrome},
+}
+
+// Score returns the weighted sum of Metrics for addr, with or without 0x and
+// in any case.
+func Score(addr string) int {
+	body := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(addr, "0x"), "0X"))
+	total := 0
+	for _, m := range Metrics {
+		total += m.Weight * m.Score(body)
+	}
+	return total
+}
+
+// LeadingZeros counts the zero nibbles body starts with.
+func LeadingZeros(body string) int {
+	n := 0
+	for n < len(body) && body[n] == '0' {
+		n++
+	}
+	return n
+}
+
+// ZeroBytes counts the zero bytes in body: the aligned "00" nibble pairs,
+// which cost less gas than other bytes when the address is in calldata.
+func ZeroBytes(body string) int {
+	n := 0
+	for i := 0; i+1 < len(body); i += 2 {
+		if body[i] == '0' && body[i+1] == '0' {
+			n++
+		}
+	}
+	return n
+}
+
+// minRun is the shortest run of one repeated nibble Runs counts; shorter
+// ones turn up in most random addresses.
+const minRun = 3
+
+// Runs scores every run of at least minRun identical nibbles by how far it
+// exceeds a pair, so "aaa" counts 1 and "aaaaa" counts 3.
+func Runs(body string) int {
+	score := 0
+	for i := 0; i < len(body); {
+		j := i + 1
+		for j < len(body) && body[j] == body[i] {
+			j++
+		}
+		if j-i >= minRun {
+			score += j - i - 2
+		}
+		i = j
+	}
+	return score
+}
+
+// minPalindrome is the length a palindrome must exceed to score; ones of up
+// to this length are common in random addresses.
+const minPalindrome = 4
+
+// Palindrome scores the longest palindromic stretch of body by how far it
+// exceeds minPalindrome nibbles.
+func Palindrome(body string) int {
+	longest := 0
+	for center := 0; center < 2*len(body)-1; center++ {
+		lo, hi := center/2, center/2+center%2
+		for lo >= 0 && hi < len(body) && body[lo] == body[hi] {
+			lo--
+			hi++
+		}
+		if n := hi - lo - 1; n > longest {
+			longest = n
+		}
+	}
+	return max(0, longest-minPalindrome)
+}
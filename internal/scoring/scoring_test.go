@@ -0,0 +1,48 @@
+package scoring
+
+import "testing"
+
+func TestMetrics(t *testing.T) {
+	cases := []struct {
+		body                    string
+		zeros, runs, palindrome int
+	}{
+		{"7e5f4552091a69125d5dfcb7b8c2659029395bdf", 0, 0, 0},
+		{"000000c0ffee0000000000000000000000000000", 6, 4 + 26, 28 - 4},
+		{"abcdeffedcba0123456789012345678901234567", 0, 0, 12 - 4},
+		{"1aaaab5555512345678901234567890123456789", 0, 2 + 3, 5 - 4},
+	}
+	for _, tc := range cases {
+		if got := LeadingZeros(tc.body); got != tc.zeros {
+			t.Errorf("LeadingZeros(%s) = %d, want %d", tc.body, got, tc.zeros)
+		}
+		if got := Runs(tc.body); got != tc.runs {
+			t.Errorf("Runs(%s) = %d, want %d", tc.body, got, tc.runs)
+		}
+		if got := Palindrome(tc.body); got != tc.palindrome {
+			t.Errorf("Palindrome(%s) = %d, want %d", tc.body, got, tc.palindrome)
+		}
+	}
+}
+
+func TestZeroBytes_CountsAlignedPairs(t *testing.T) {
+	for body, want := range map[string]int{
+		"7e5f4552091a69125d5dfcb7b8c2659029395bdf": 0,
+		"000000c0ffee0000000000000000000000000000": 17,
+		"0100100000000000000000000000000000000000": 18,
+	} {
+		if got := ZeroBytes(body); got != want {
+			t.Errorf("ZeroBytes(%s) = %d, want %d", body, got, want)
+		}
+	}
+}
+
+func TestScore_IgnoresPrefixAndCase(t *testing.T) {
+	want := Score("000000c0ffee0000000000000000000000000000")
+	if want != 2*6+30+24 {
+		t.Fatalf("Score = %d, want %d", want, 2*6+30+24)
+	}
+	if got := Score("0x000000C0FFEE0000000000000000000000000000"); got != want {
+		t.Fatalf("Score with 0x and upper case = %d, want %d", got, want)
+	}
+}
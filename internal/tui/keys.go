@@ -11,8 +11,16 @@ type keyMap struct {
 	Right    key.Binding
 	Enter    key.Binding
 	Stop     key.Binding
+	More     key.Binding
+	Fewer    key.Binding
 	Save     key.Binding
+	Format   key.Binding
+	Fallback key.Binding
+	Cancel   key.Binding
 	New      key.Binding
+	History  key.Binding
+	Back     key.Binding
+	Reveal   key.Binding
 	Quit     key.Binding
 }
 
@@ -49,14 +57,46 @@ var keys = keyMap{
 		key.WithKeys("ctrl+c", "q", "esc"),
 		key.WithHelp("ctrl+c/esc", "stop"),
 	),
+	More: key.NewBinding(
+		key.WithKeys("+", "="),
+		key.WithHelp("+", "more workers"),
+	),
+	Fewer: key.NewBinding(
+		key.WithKeys("-", "_"),
+		key.WithHelp("-", "fewer workers"),
+	),
 	Save: key.NewBinding(
 		key.WithKeys("s"),
 		key.WithHelp("s", "save"),
 	),
+	Format: key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "format"),
+	),
+	Fallback: key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "save to temp dir"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("ctrl+c", "esc"),
+		key.WithHelp("esc", "cancel"),
+	),
 	New: key.NewBinding(
 		key.WithKeys("n"),
 		key.WithHelp("n", "new search"),
 	),
+	History: key.NewBinding(
+		key.WithKeys("h"),
+		key.WithHelp("h", "history"),
+	),
+	Back: key.NewBinding(
+		key.WithKeys("esc", "h", "q"),
+		key.WithHelp("esc/h", "back"),
+	),
+	Reveal: key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "reveal keys"),
+	),
 	Quit: key.NewBinding(
 		key.WithKeys("ctrl+c", "q", "esc"),
 		key.WithHelp("ctrl+c/q/esc", "quit"),
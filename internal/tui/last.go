@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// lastSearch is what the TUI remembers of the last submitted search: the
+// form fields and nothing else, so the file never holds results or keys
+// and does not change shape with generator.Config. The JSON names match
+// those of generator.Config, which older versions wrote whole.
+type lastSearch struct {
+	Prefix        string `json:"prefix,omitempty"`
+	Suffix        string `json:"suffix,omitempty"`
+	Contains      string `json:"contains,omitempty"`
+	Count         int    `json:"count,omitempty"`
+	Workers       int    `json:"workers,omitempty"`
+	CaseSensitive bool   `json:"caseSensitive,omitempty"`
+}
+
+// lastConfigPath is where the TUI remembers the last submitted search,
+// normally ~/.config/vanity-eth/last.json.
+func lastConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vanity-eth", "last.json"), nil
+}
+
+// loadLastSearch returns the last submitted search, if any. A missing or
+// unreadable file is not an error; ok is simply false.
+func loadLastSearch() (last lastSearch, ok bool) {
+	path, err := lastConfigPath()
+	if err != nil {
+		return last, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return last, false
+	}
+	if err := json.Unmarshal(data, &last); err != nil {
+		return lastSearch{}, false
+	}
+	return last, true
+}
+
+// saveLastSearch remembers last for the next launch. Remembering the form
+// is a convenience, so the caller reports an error rather than stopping
+// the search.
+func saveLastSearch(last lastSearch) error {
+	path, err := lastConfigPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(last, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
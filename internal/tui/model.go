@@ -2,10 +2,14 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
 	"os"
+	"path/filepath"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +20,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"vanity-eth/internal/generator"
+	"vanity-eth/internal/humanize"
+	"vanity-eth/internal/ledger"
 )
 
 // uiState is the current screen of the TUI.
@@ -25,14 +31,27 @@ const (
 	stateForm    uiState = iota // pattern entry form
 	stateRunning                // search in progress
 	stateResults                // search complete
+	stateSave                   // keystore passphrase prompt
+	stateHistory                // searches made earlier in the session
 )
 
+// saveFormats are the formats the results screen cycles through with the
+// format key, as with the CLI's --output-format.
+var saveFormats = []string{"text", "json", "keystore"}
+
 // Internal messages.
 type tickMsg time.Time
 type resultMsg struct{ r generator.Result }
 type doneMsg struct{}
 type savedMsg struct{ path string }
-type saveErrMsg struct{ err error }
+
+// saveErrMsg reports a failed save; fallback is set if the same save could
+// go to the temp directory instead.
+type saveErrMsg struct {
+	err      error
+	fallback bool
+}
+type benchMsg struct{ perWorker float64 }
 
 // Form focus indices.
 const (
@@ -42,7 +61,9 @@ const (
 	fieldCount    = 3
 	fieldWorkers  = 4
 	fieldCase     = 5
-	numFields     = 6
+	fieldSaveTo   = 6
+	fieldFormat   = 7
+	numFields     = 8
 )
 
 // inputIndex maps a focusIdx to m.inputs slice index (-1 if not a text input).
@@ -58,22 +79,37 @@ func inputIndex(fi int) int {
 		return 3
 	case fieldWorkers:
 		return 4
+	case fieldSaveTo:
+		return 5
 	default:
 		return -1
 	}
 }
 
+// sessionRecord is a finished search kept in the session history. It holds
+// addresses only: private keys are zeroed when the next search starts.
+type sessionRecord struct {
+	cfg       generator.Config
+	addresses []string
+	total     int64
+	elapsed   time.Duration
+}
+
 // Model is the bubbletea application model.
 type Model struct {
 	state  uiState
 	width  int
 	height int
 
-	// Form: prefix(0) suffix(1) contains(2) count(3) workers(4).
+	// Form: prefix(0) suffix(1) contains(2) count(3) workers(4) save to(5).
 	inputs        []textinput.Model
 	focusIdx      int
 	caseSensitive bool
 
+	// benchRate is the addr/s one worker managed in the start-up benchmark;
+	// 0 until it has finished.
+	benchRate float64
+
 	// Running state.
 	ctx       context.Context
 	cancel    context.CancelFunc
@@ -81,10 +117,29 @@ type Model struct {
 	resultCh  chan generator.Result
 	startTime time.Time
 	spinner   spinner.Model
+	// scaler sets the search's worker count while it runs.
+	scaler *generator.Scaler
+	// kept holds the results found so far; results takes them over when
+	// the search ends.
+	kept generator.Recent
+	// seen holds every address found so far.
+	seen generator.Seen
+	// rate is the smoothed rate the ETA is based on, updated every tick.
+	rate generator.RateSmoother
 
 	// Shared.
-	results []generator.Result
-	cfg     generator.Config
+	results    []generator.Result
+	ledgerPath string // if set, text saves append here instead of a new file
+	savePath   string // set in the form: save here automatically when done
+	saveTarget string // where the keystore being asked a passphrase for goes
+	saveInTemp bool   // the keystore goes to the temp directory instead
+	fallback   bool   // after a failed save, t saves to the temp directory
+	saveFormat int    // index into saveFormats
+	passInput  textinput.Model
+	passFirst  string // the passphrase typed once, waiting to be confirmed
+	maskKeys   bool   // show keys masked until revealKeys is toggled on
+	revealKeys bool
+	cfg        generator.Config
 
 	// Status messages.
 	errMsg  string
@@ -93,11 +148,15 @@ type Model struct {
 	// Final stats (captured when done).
 	finalTotal   int64
 	finalElapsed time.Duration
+
+	// history holds the searches finished before the current one, oldest
+	// first; it survives the new-search reset.
+	history []sessionRecord
 }
 
 // New creates a fresh Model ready for the form state.
 func New() Model {
-	inputs := make([]textinput.Model, 5)
+	inputs := make([]textinput.Model, 6)
 
 	newInput := func(placeholder string, width int) textinput.Model {
 		t := textinput.New()
@@ -112,8 +171,24 @@ func New() Model {
 	inputs[2] = newInput("e.g. e|f|ff", 28) // contains
 	inputs[3] = newInput("1", 6)            // count
 	inputs[3].SetValue("1")
-	inputs[4] = newInput(fmt.Sprintf("%d", runtime.NumCPU()), 6) // workers
-	inputs[4].SetValue(fmt.Sprintf("%d", runtime.NumCPU()))
+	inputs[4] = newInput(fmt.Sprintf("%d", generator.DefaultWorkers()), 6) // workers
+	inputs[4].SetValue(fmt.Sprintf("%d", generator.DefaultWorkers()))
+	inputs[5] = newInput("optional: save here when done", 28) // save to
+	inputs[5].CharLimit = 256
+
+	// Pre-fill the form from the last search, if one was remembered.
+	last, ok := loadLastSearch()
+	if ok {
+		inputs[0].SetValue(last.Prefix)
+		inputs[1].SetValue(last.Suffix)
+		inputs[2].SetValue(last.Contains)
+		if last.Count > 0 {
+			inputs[3].SetValue(strconv.Itoa(last.Count))
+		}
+		if last.Workers > 0 {
+			inputs[4].SetValue(strconv.Itoa(last.Workers))
+		}
+	}
 
 	inputs[0].Focus()
 
@@ -121,14 +196,46 @@ func New() Model {
 	sp.Spinner = spinner.Dot
 	sp.Style = lipgloss.NewStyle().Foreground(colorPrimary)
 
+	pass := textinput.New()
+	pass.Placeholder = "passphrase"
+	pass.EchoMode = textinput.EchoPassword
+	pass.EchoCharacter = '•'
+	pass.Width = 28
+
 	return Model{
-		inputs:  inputs,
-		spinner: sp,
+		inputs:        inputs,
+		caseSensitive: ok && last.CaseSensitive,
+		spinner:       sp,
+		passInput:     pass,
 	}
 }
 
+// WithLedger makes text saves append results to path, continuing its #N
+// numbering, instead of writing a new timestamped file each time.
+func (m Model) WithLedger(path string) Model {
+	m.ledgerPath = path
+	return m
+}
+
+// WithMaskedKeys shows private keys masked in the results view until the
+// user reveals them. Saved files still get them in full.
+func (m Model) WithMaskedKeys() Model {
+	m.maskKeys = true
+	return m
+}
+
 func (m Model) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, benchmark())
+}
+
+// benchmark briefly measures this machine's key rate so the form can show
+// an estimated search time before one starts.
+func benchmark() tea.Cmd {
+	return func() tea.Msg {
+		workers := generator.DefaultWorkers()
+		rate := generator.MeasureRate(generator.Config{Workers: workers}, 300*time.Millisecond)
+		return benchMsg{perWorker: rate / float64(workers)}
+	}
 }
 
 // ---- Update ----------------------------------------------------------------
@@ -141,8 +248,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		return m, nil
 
+	case benchMsg:
+		m.benchRate = msg.perWorker
+		return m, nil
+
 	case tickMsg:
 		if m.state == stateRunning {
+			m.rate.Update(m.stats.Total.Load(), time.Since(m.startTime))
 			return m, tick()
 		}
 		return m, nil
@@ -157,26 +269,45 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case resultMsg:
 		if m.state == stateRunning {
-			m.results = append(m.results, msg.r)
+			// Run already sends each address once; this guards the
+			// results list should that ever change.
+			if !m.seen.Add(msg.r.Checksum) {
+				msg.r.PrivateKey.Zero()
+			} else {
+				m.kept.Add(msg.r)
+			}
 			return m, waitForResult(m.resultCh)
 		}
 		return m, nil
 
 	case doneMsg:
+		m.results = m.kept.Results()
 		m.finalTotal = m.stats.Total.Load()
 		m.finalElapsed = time.Since(m.startTime)
+		if err := m.stats.Err(); err != nil {
+			m.errMsg = "Search aborted: " + err.Error()
+		}
 		if m.cancel != nil {
 			m.cancel()
 		}
 		m.state = stateResults
-		return m, nil
+		if m.savePath == "" || len(m.results) == 0 {
+			return m, nil
+		}
+		return m.save(m.savePath, false)
 
 	case savedMsg:
 		m.infoMsg = "Saved to " + msg.path
+		m.fallback = false
 		return m, nil
 
 	case saveErrMsg:
+		m.infoMsg = ""
 		m.errMsg = "Save error: " + msg.err.Error()
+		m.fallback = msg.fallback
+		if m.fallback {
+			m.errMsg += "; press t to save in " + os.TempDir() + " instead"
+		}
 		return m, nil
 
 	case tea.KeyMsg:
@@ -184,8 +315,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 
 	// Delegate unhandled msgs to focused text input when on form.
-	if m.state == stateForm {
+	switch m.state {
+	case stateForm:
 		return m.updateActiveInput(msg)
+	case stateSave:
+		var cmd tea.Cmd
+		m.passInput, cmd = m.passInput.Update(msg)
+		return m, cmd
 	}
 	return m, nil
 }
@@ -195,7 +331,9 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case stateForm:
 		switch {
-		case key.Matches(msg, keys.Quit):
+		// q is an ordinary character in a file path.
+		case key.Matches(msg, keys.Cancel),
+			key.Matches(msg, keys.Quit) && m.focusIdx != fieldSaveTo:
 			return m, tea.Quit
 
 		case key.Matches(msg, keys.Tab):
@@ -222,6 +360,10 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.caseSensitive = !m.caseSensitive
 			return m, nil
 
+		case msg.String() == " " && m.focusIdx == fieldFormat:
+			m.saveFormat = (m.saveFormat + 1) % len(saveFormats)
+			return m, nil
+
 		case key.Matches(msg, keys.Enter):
 			if err := m.prepareSearch(); err != nil {
 				m.errMsg = err.Error()
@@ -239,31 +381,106 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case stateRunning:
-		if key.Matches(msg, keys.Stop) {
+		switch {
+		case key.Matches(msg, keys.Stop):
 			if m.cancel != nil {
 				m.cancel()
 			}
+		case key.Matches(msg, keys.More):
+			m.scaler.Set(min(m.scaler.Workers()+1, maxLiveWorkers()))
+		case key.Matches(msg, keys.Fewer):
+			m.scaler.Set(m.scaler.Workers() - 1)
 		}
 
 	case stateResults:
 		switch {
 		case key.Matches(msg, keys.Quit):
+			generator.ZeroKeys(m.results)
 			return m, tea.Quit
+		case key.Matches(msg, keys.Format):
+			m.saveFormat = (m.saveFormat + 1) % len(saveFormats)
+			return m, nil
+		case key.Matches(msg, keys.Reveal) && m.maskKeys:
+			m.revealKeys = !m.revealKeys
+			return m, nil
 		case key.Matches(msg, keys.Save):
 			m.infoMsg = ""
 			m.errMsg = ""
-			return m, saveResults(m.results)
+			return m.save(m.savePath, false)
+		case key.Matches(msg, keys.Fallback) && m.fallback:
+			m.infoMsg = ""
+			m.errMsg = ""
+			return m.save("", true)
+		case key.Matches(msg, keys.History):
+			m.state = stateHistory
+			return m, nil
 		case key.Matches(msg, keys.New):
-			next := New()
+			next := New().WithLedger(m.ledgerPath)
+			next.maskKeys = m.maskKeys
+			next.benchRate = m.benchRate
 			next.width = m.width
 			next.height = m.height
+			next.history = append(m.history, m.record())
+			generator.ZeroKeys(m.results)
 			return next, nil
 		}
+
+	case stateHistory:
+		if key.Matches(msg, keys.Back) {
+			m.state = stateResults
+		}
+
+	case stateSave:
+		switch {
+		case key.Matches(msg, keys.Cancel):
+			m.passInput.Reset()
+			m.passInput.Blur()
+			m.passFirst = ""
+			m.errMsg = ""
+			m.state = stateResults
+		case key.Matches(msg, keys.Enter):
+			// The passphrase is typed twice: a typo in a hidden field
+			// would otherwise lock the keys away for good.
+			pass := m.passInput.Value()
+			m.passInput.Reset()
+			switch {
+			case m.passFirst == "" && pass == "":
+				m.errMsg = "passphrase must not be empty"
+				return m, nil
+			case m.passFirst == "":
+				m.passFirst = pass
+				m.errMsg = ""
+				return m, nil
+			case pass != m.passFirst:
+				m.passFirst = ""
+				m.errMsg = "passphrases do not match, enter it again"
+				return m, nil
+			}
+			m.passFirst = ""
+			m.passInput.Blur()
+			m.errMsg = ""
+			m.infoMsg = fmt.Sprintf("Encrypting %d key(s)...", len(m.results))
+			m.state = stateResults
+			return m, saveResults(m.results, "keystore", m.saveTarget, m.ledgerPath, pass, m.saveInTemp)
+		default:
+			var cmd tea.Cmd
+			m.passInput, cmd = m.passInput.Update(msg)
+			return m, cmd
+		}
 	}
 
 	return m, nil
 }
 
+// record summarizes the finished search for the session history.
+func (m Model) record() sessionRecord {
+	rec := sessionRecord{cfg: m.cfg, total: m.finalTotal, elapsed: m.finalElapsed}
+	for _, r := range m.results {
+		rec.addresses = append(rec.addresses, r.Address)
+	}
+	return rec
+}
+
 // updateActiveInput forwards the message to the focused text input and
 // validates hex fields in real time.
 func (m Model) updateActiveInput(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -300,10 +517,17 @@ func hexValidationError(val, label string) string {
 	if strings.TrimSpace(val) == "" {
 		return ""
 	}
-	if err := generator.ValidateHexPattern(val); err != nil {
-		return fmt.Sprintf("%s: %v", label, err)
+	err := generator.ValidateHexPattern(val)
+	var bad *generator.InvalidCharError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &bad):
+		return fmt.Sprintf("%s: %q (character %d) is not a hex digit", label, bad.Char, bad.Offset+1)
+	case errors.Is(err, generator.ErrUnclosedGroup):
+		return fmt.Sprintf("%s: close the '(' group with ')'", label)
 	}
-	return ""
+	return fmt.Sprintf("%s: %v", label, err)
 }
 
 // syncFocus blurs all inputs and focuses the active one (if applicable).
@@ -332,6 +556,9 @@ func (m *Model) prepareSearch() error {
 			}
 		}
 	}
+	if err := generator.ValidatePatternFit(prefix, suffix, contains, m.caseSensitive); err != nil {
+		return err
+	}
 
 	count, err := strconv.Atoi(strings.TrimSpace(m.inputs[3].Value()))
 	if err != nil || count < 1 {
@@ -343,6 +570,13 @@ func (m *Model) prepareSearch() error {
 		return fmt.Errorf("workers must be a positive integer")
 	}
 
+	savePath := strings.TrimSpace(m.inputs[5].Value())
+	if savePath != "" {
+		if _, err := os.Stat(filepath.Dir(savePath)); err != nil {
+			return fmt.Errorf("save to: %v", err)
+		}
+	}
+
 	m.cfg = generator.Config{
 		Prefix:        prefix,
 		Suffix:        suffix,
@@ -351,23 +585,44 @@ func (m *Model) prepareSearch() error {
 		Count:         count,
 		CaseSensitive: m.caseSensitive,
 	}
+	saveErr := saveLastSearch(lastSearch{
+		Prefix:        prefix,
+		Suffix:        suffix,
+		Contains:      contains,
+		Count:         count,
+		Workers:       workers,
+		CaseSensitive: m.caseSensitive,
+	})
 
 	ctx, cancel := context.WithCancel(context.Background())
 	m.ctx = ctx
 	m.cancel = cancel
 	m.stats = &generator.Stats{}
+	m.scaler = generator.NewScaler(workers)
 	m.resultCh = make(chan generator.Result, count)
 	m.results = nil
+	m.kept = generator.Recent{}
+	m.seen = generator.Seen{}
+	m.savePath = savePath
 	m.startTime = time.Now()
+	m.rate = generator.RateSmoother{}
 	m.errMsg = ""
+	if saveErr != nil {
+		m.errMsg = "Could not remember this search: " + saveErr.Error()
+	}
 	m.infoMsg = ""
 	m.state = stateRunning
 	return nil
 }
 
-// runGenerator fires the generator as a background tea.Cmd.
+// runGenerator fires the generator as a background tea.Cmd. Stopping
+// cancels m.ctx; resultCh holds cfg.Count results, so no worker is left
+// blocked on a send, and Run closes it once the workers exit. waitForResult
+// keeps draining it until then, so results found as the search stops are
+// still shown and the close always brings doneMsg.
 func (m Model) runGenerator() tea.Cmd {
 	cfg := m.cfg
+	cfg.Scaler = m.scaler
 	ch := m.resultCh
 	stats := m.stats
 	ctx := m.ctx
@@ -377,6 +632,10 @@ func (m Model) runGenerator() tea.Cmd {
 	}
 }
 
+// maxLiveWorkers caps the worker count the + key reaches: past a few
+// workers per CPU they only contend for it.
+func maxLiveWorkers() int { return 4 * runtime.NumCPU() }
+
 func waitForResult(ch <-chan generator.Result) tea.Cmd {
 	return func() tea.Msg {
 		r, ok := <-ch
@@ -393,18 +652,109 @@ func tick() tea.Cmd {
 	})
 }
 
-func saveResults(results []generator.Result) tea.Cmd {
+// save saves the results in the chosen format to path, or where
+// saveResults puts them by default if path is empty, or under a new name in
+// the temp directory if inTemp is set. Keystores ask for a passphrase
+// first.
+func (m Model) save(path string, inTemp bool) (Model, tea.Cmd) {
+	if saveFormats[m.saveFormat] == "keystore" {
+		m.state = stateSave
+		m.saveTarget = path
+		m.saveInTemp = inTemp
+		m.passInput.Reset()
+		m.passFirst = ""
+		return m, m.passInput.Focus()
+	}
+	return m, saveResults(m.results, saveFormats[m.saveFormat], path, m.ledgerPath, "", inTemp)
+}
+
+// Text and JSON saves are tried saveAttempts times, saveBackoff apart and
+// doubling, before the TUI gives up and offers the temp directory.
+// Keystores are not retried: encrypting is slow, and a partial write would
+// leave duplicates.
+const (
+	saveAttempts = 3
+	saveBackoff  = 250 * time.Millisecond
+)
+
+// saveResults writes results in format to path, the destination chosen in
+// the form, if set. Otherwise text appends to ledgerPath if set or goes to a
+// new timestamped file, json to a new timestamped file, and keystore to one
+// encrypted file per key in a new timestamped directory. For keystore, path
+// names the directory. With inTemp, the file or directory gets a new,
+// unique name in the temp directory instead, which a failed save offers as
+// the fallback; being shared, the temp directory is never written through
+// an existing name.
+func saveResults(results []generator.Result, format, path, ledgerPath, passphrase string, inTemp bool) tea.Cmd {
 	return func() tea.Msg {
-		path := fmt.Sprintf("vanity-eth-%s.txt", time.Now().Format("20060102-150405"))
-		f, err := os.Create(path)
-		if err != nil {
-			return saveErrMsg{err}
+		base := "vanity-eth-" + time.Now().Format("20060102-150405")
+		failed := func(err error) tea.Msg {
+			return saveErrMsg{err: err, fallback: !inTemp}
+		}
+		switch format {
+		case "keystore":
+			dir := base + "-keystore"
+			switch {
+			case inTemp:
+				var err error
+				if dir, err = os.MkdirTemp("", base+"-*-keystore"); err != nil {
+					return failed(err)
+				}
+			case path != "":
+				dir = path
+			}
+			if _, err := ledger.WriteKeystore(dir, results, passphrase); err != nil {
+				return failed(err)
+			}
+			return savedMsg{path: dir}
+		case "text":
+			if ledgerPath != "" && path == "" && !inTemp {
+				// AppendText cuts the ledger back to where it ended when
+				// it fails, and rereads the last index, so a retry neither
+				// repeats entries nor breaks the numbering.
+				err := ledger.Retry(saveAttempts, saveBackoff, func() error {
+					return ledger.AppendText(ledgerPath, results)
+				})
+				if err != nil {
+					return failed(err)
+				}
+				return savedMsg{path: ledgerPath}
+			}
+		}
+
+		ext := ".txt"
+		write := func(w io.Writer) error { return ledger.WriteText(w, results, 1) }
+		if format == "json" {
+			ext = ".json"
+			write = func(w io.Writer) error { return ledger.WriteJSON(w, results) }
+		}
+		if path == "" {
+			path = base + ext
 		}
-		defer f.Close()
-		for i, r := range results {
-			fmt.Fprintf(f, "#%d\n", i+1)
-			fmt.Fprintf(f, "Address:     %s\n", r.Address)
-			fmt.Fprintf(f, "Private Key: 0x%s\n\n", r.PrivateKey)
+		open := func() (*os.File, error) {
+			return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		}
+		if inTemp {
+			// CreateTemp picks a name no one else has, and makes it 0600.
+			open = func() (*os.File, error) { return os.CreateTemp("", base+"-*"+ext) }
+		}
+		err := ledger.Retry(saveAttempts, saveBackoff, func() error {
+			f, err := open()
+			if err != nil {
+				return err
+			}
+			path = f.Name()
+			err = write(f)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil && inTemp {
+				os.Remove(path)
+			}
+			return err
+		})
+		if err != nil {
+			return failed(err)
 		}
 		return savedMsg{path: path}
 	}
@@ -421,9 +771,13 @@ func (m Model) View() string {
 		body = m.viewRunning()
 	case stateResults:
 		body = m.viewResults()
+	case stateSave:
+		body = m.viewSave()
+	case stateHistory:
+		body = m.viewHistory()
 	}
 
-	box := styleBox.Render(body)
+	box := styleBox.Width(m.boxWidth()).Render(body)
 	if m.width > 0 && m.height > 0 {
 		return lipgloss.Place(m.width, m.height, lipgloss.Left, lipgloss.Center, box)
 	}
@@ -464,6 +818,10 @@ func (m Model) viewForm() string {
 	}
 	b.WriteString(caseLbl.Width(11).Render("Case") + "  " + box + " sensitive\n")
 
+	b.WriteString("\n")
+	b.WriteString(row("Save to", fieldSaveTo, m.inputs[5].View()))
+	b.WriteString(row("Format", fieldFormat, "‹ "+saveFormats[m.saveFormat]+" ›"))
+
 	b.WriteString("\n")
 
 	// Live preview
@@ -480,7 +838,15 @@ func (m Model) viewForm() string {
 		m.inputs[2].Value(),
 		m.caseSensitive,
 	); d != nil {
-		b.WriteString(styleMuted.Render("  ~1 in " + formatBigInt(d) + "\n"))
+		b.WriteString(difficultyStyle(d).Render("  ~1 in "+humanize.BigInt(d)) +
+			styleMuted.Render(" ("+percentPerAttempt(d)+" per attempt)"))
+		if est := m.formEstimate(); est != "" {
+			b.WriteString(styleMuted.Render("  •  " + est))
+		}
+		b.WriteString("\n")
+		if warning := generator.DifficultyWarning(d); warning != "" {
+			b.WriteString(styleDanger.Width(m.contentWidth()).Render("  ⚠ "+warning) + "\n")
+		}
 	}
 
 	b.WriteString("\n")
@@ -491,7 +857,7 @@ func (m Model) viewForm() string {
 
 	help := styleHelp.PaddingLeft(12)
 	b.WriteString(help.Render("up/down/tab move between fields") + "\n")
-	b.WriteString(help.Render("space toggles case sensitive") + "\n")
+	b.WriteString(help.Render("space toggles case sensitive, cycles format") + "\n")
 	b.WriteString(help.Render("enter starts search") + "\n")
 	b.WriteString(help.Render("esc/ctrl+c/q quits"))
 	return b.String()
@@ -500,53 +866,36 @@ func (m Model) viewForm() string {
 // renderPreview builds a colour-coded address skeleton.
 // Patterns with | alternation (e.g. "e|f|ff") are shown as "(e|f|ff)".
 func renderPreview(prefix, suffix, contains string) string {
-	const addrLen = 40
 	prefix = strings.ToLower(prefix)
 	suffix = strings.ToLower(suffix)
 	contains = strings.ToLower(contains)
 
-	// patToken returns the display text and hex positions consumed (min alt length).
-	patToken := func(pat string) (string, int) {
-		if pat == "" {
-			return "", 0
-		}
-		minLen := generator.MinHexPatternLen(pat)
+	// patToken returns the display text for a pattern.
+	patToken := func(pat string) string {
 		if strings.Contains(pat, "|") && !strings.HasPrefix(pat, "(") {
-			return "(" + pat + ")", minLen
+			return "(" + pat + ")"
 		}
-		return pat, minLen
+		return pat
 	}
+	unknown := func(n int) string { return styleMuted.Render(strings.Repeat("?", n)) }
 
-	prefixTok, prefixLen := patToken(prefix)
-	suffixTok, suffixLen := patToken(suffix)
-	containsTok, containsLen := patToken(contains)
+	prefixLen, before, containsLen, after, suffixLen := generator.PreviewLayout(prefix, suffix, contains)
 
 	var b strings.Builder
 	b.WriteString(styleMuted.Render("  Preview") + "  0x")
-
-	if prefixTok != "" {
-		b.WriteString(styleSuccess.Render(prefixTok))
+	if prefixLen > 0 {
+		b.WriteString(styleSuccess.Render(patToken(prefix)))
 	}
-
-	middle := addrLen - prefixLen - suffixLen
-	if containsTok != "" && containsLen <= middle {
-		before := (middle - containsLen) / 2
-		after := middle - before - containsLen
-		for i := 0; i < before; i++ {
-			b.WriteString(styleMuted.Render("?"))
-		}
-		b.WriteString(styleAccent.Render(containsTok))
-		for i := 0; i < after; i++ {
-			b.WriteString(styleMuted.Render("?"))
-		}
-	} else {
-		for i := 0; i < middle; i++ {
-			b.WriteString(styleMuted.Render("?"))
-		}
+	b.WriteString(unknown(before))
+	if containsLen > 0 {
+		b.WriteString(styleAccent.Render(patToken(contains)))
 	}
-
-	if suffixTok != "" {
-		b.WriteString(styleSuccess.Render(suffixTok))
+	b.WriteString(unknown(after))
+	if suffixLen > 0 {
+		b.WriteString(styleSuccess.Render(patToken(suffix)))
+	}
+	if containsLen == 0 && generator.MinHexPatternLen(contains) > 0 {
+		b.WriteString(styleMuted.Render("  (contains does not fit)"))
 	}
 
 	b.WriteString("\n")
@@ -567,27 +916,49 @@ func (m Model) viewRunning() string {
 	}
 
 	b.WriteString(styleTitle.Render("vanity-eth") + "  " + m.spinner.View() + "\n")
-	b.WriteString(styleMuted.Render("Searching for "+patternDesc(m.cfg)) + "\n\n")
+	b.WriteString(styleMuted.Render("Searching for "+patternDesc(m.cfg)) + "\n")
+	if warning := generator.DifficultyWarning(generator.Difficulty(m.cfg)); warning != "" {
+		b.WriteString(styleDanger.Width(m.contentWidth()).Render("⚠ "+warning) + "\n")
+	}
+	if m.errMsg != "" {
+		b.WriteString(styleDanger.Width(m.contentWidth()).Render("✗ "+m.errMsg) + "\n")
+	}
+	b.WriteString("\n")
 
-	eta := computeETA(m.cfg, int(found), rate)
+	// Until the first tick, fall back on the raw rate.
+	etaRate := m.rate.Rate()
+	if etaRate == 0 {
+		etaRate = rate
+	}
+	eta := computeETA(m.cfg, int(found), etaRate)
 	etaStr := "—"
 	if eta > 0 {
 		etaStr = fmtDuration(eta)
 	}
 
-	b.WriteString(statRow("Tried", formatBig(total)) + "  " + statRow("Rate", fmt.Sprintf("%.0f/s", rate)) + "\n")
-	b.WriteString(statRow("Found", fmt.Sprintf("%d/%d", found, m.cfg.Count)) + "  " + statRow("Time", fmtDuration(elapsed)) + "\n")
-	b.WriteString(statRow("ETA", etaStr) + "\n\n")
+	// The pool takes a moment to follow the + and - keys; show where it is
+	// headed until it gets there.
+	workers := fmt.Sprint(m.stats.Workers.Load())
+	if target := m.scaler.Workers(); int64(target) != m.stats.Workers.Load() {
+		workers += fmt.Sprintf(" → %d", target)
+	}
 
-	if len(m.results) > 0 {
+	width := m.contentWidth()
+	b.WriteString(statGrid([][2]string{
+		{statRow("Tried", formatBig(total)), statRow("Rate", fmt.Sprintf("%.0f/s", rate))},
+		{statRow("Found", fmt.Sprintf("%d/%d", found, m.cfg.Count)), statRow("Time", fmtDuration(elapsed))},
+		{statRow("Workers", workers), statRow("ETA", etaStr)},
+	}, width) + "\n")
+
+	if m.kept.Len() > 0 {
 		b.WriteString(styleSuccess.Render("Results so far:") + "\n")
-		for _, r := range m.results {
-			b.WriteString("  " + styleSuccess.Render("✓") + " " + styleStat.Render(truncate(r.Address, 32)) + "\n")
+		for _, r := range m.kept.Results() {
+			b.WriteString("  " + styleSuccess.Render("✓") + " " + styleStat.Render(truncate(r.Address, width-4)) + "\n")
 		}
 		b.WriteString("\n")
 	}
 
-	b.WriteString(styleHelp.Render("ctrl+c · q  stop search"))
+	b.WriteString(styleHelp.Render("+/-  workers  •  ctrl+c · q  stop search"))
 	return b.String()
 }
 
@@ -603,13 +974,27 @@ func (m Model) viewResults() string {
 	b.WriteString(styleMuted.Render(fmt.Sprintf("%s tried  •  %s  •  %.0f addr/s",
 		formatBig(m.finalTotal), fmtDuration(m.finalElapsed), rate)) + "\n\n")
 
+	// Addresses are shown whole, wrapped if need be; the key is only a
+	// reminder of which one it is, so it is cut to fit.
+	width := m.contentWidth()
+	keyLen := max(4, min(20, width-len("    key:  0x...")))
 	for i, r := range m.results {
-		b.WriteString(fmt.Sprintf("%s  %s\n",
-			styleMuted.Render(fmt.Sprintf("#%d", i+1)),
-			styleStat.Render(r.Address)))
-		b.WriteString(fmt.Sprintf("    %s  %s\n",
-			styleMuted.Render("key:"),
-			styleKey.Render("0x"+truncate(r.PrivateKey, 20)+"...")))
+		b.WriteString(wrapValue("", styleMuted.Render(fmt.Sprintf("#%d", i+1)), styleStat, r.Address, width))
+		if r.Checksum != "" && r.Checksum != r.Address {
+			b.WriteString(wrapValue("    ", styleMuted.Render("eip55:"), lipgloss.NewStyle(), r.Checksum, width))
+		}
+		switch {
+		case m.maskKeys && m.revealKeys:
+			b.WriteString(wrapValue("    ", styleMuted.Render("key:"), styleKey, "0x"+r.PrivateKey.String(), width))
+		case m.maskKeys:
+			b.WriteString(fmt.Sprintf("    %s  %s\n",
+				styleMuted.Render("key:"),
+				styleKey.Render("0x"+r.PrivateKey.Masked())))
+		default:
+			b.WriteString(fmt.Sprintf("    %s  %s\n",
+				styleMuted.Render("key:"),
+				styleKey.Render("0x"+truncate(r.PrivateKey.String(), keyLen)+"...")))
+		}
 		b.WriteString("\n")
 	}
 
@@ -620,17 +1005,113 @@ func (m Model) viewResults() string {
 		b.WriteString(styleDanger.Render("✗ "+m.errMsg) + "\n\n")
 	}
 
-	b.WriteString(styleHelp.Render("s save  n new search  q quit"))
+	save := "s save as " + saveFormats[m.saveFormat]
+	if m.savePath != "" {
+		save += " to " + m.savePath
+	}
+	help := save + "  f format  n new search  h history  q quit"
+	if m.fallback {
+		help += "  t save to temp dir"
+	}
+	if m.maskKeys {
+		reveal := "  r reveal keys"
+		if m.revealKeys {
+			reveal = "  r mask keys"
+		}
+		help += reveal
+	}
+	b.WriteString(styleHelp.Render(help))
+	return b.String()
+}
+
+// ---- Save view -------------------------------------------------------------
+
+func (m Model) viewSave() string {
+	var b strings.Builder
+
+	b.WriteString(styleTitle.Render("vanity-eth") + "\n")
+	b.WriteString(styleMuted.Render(fmt.Sprintf("Encrypt %d key(s) into keystore files", len(m.results))) + "\n\n")
+	label := "Passphrase"
+	if m.passFirst != "" {
+		label = "Confirm"
+	}
+	b.WriteString(styleLabel.Width(11).Render(label) + "  " + m.passInput.View() + "\n\n")
+
+	if m.errMsg != "" {
+		b.WriteString(styleDanger.Render("✗ "+m.errMsg) + "\n\n")
+	}
+
+	b.WriteString(styleHelp.Render("enter save  esc cancel"))
+	return b.String()
+}
+
+// ---- History view ----------------------------------------------------------
+
+func (m Model) viewHistory() string {
+	var b strings.Builder
+
+	b.WriteString(styleTitle.Render("vanity-eth") + "\n")
+	b.WriteString(styleMuted.Render("Searches in this session") + "\n\n")
+
+	width := m.contentWidth()
+	records := append(slices.Clip(m.history), m.record())
+	for i, rec := range records {
+		label := fmt.Sprintf("#%d", i+1)
+		if i == len(records)-1 {
+			label += " (current)"
+		}
+		b.WriteString(styleStat.Render(label) + "  " + patternDesc(rec.cfg) + "\n")
+		b.WriteString(styleMuted.Render(fmt.Sprintf("    found %d of %d  •  %s tried in %s",
+			len(rec.addresses), rec.cfg.Count, formatBig(rec.total), fmtDuration(rec.elapsed))) + "\n")
+		for _, addr := range rec.addresses {
+			b.WriteString(wrapValue("    ", "", lipgloss.NewStyle(), addr, width))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(styleHelp.Render("esc/h back"))
 	return b.String()
 }
 
 // ---- Helpers ---------------------------------------------------------------
 
+// formEstimate returns "est. ~X for N" for the pattern, count and workers
+// currently in the form, based on the start-up benchmark. Empty until the
+// benchmark is done or while a field is invalid.
+func (m Model) formEstimate() string {
+	if m.benchRate <= 0 {
+		return ""
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(m.inputs[3].Value()))
+	if err != nil || count < 1 {
+		return ""
+	}
+	workers, err := strconv.Atoi(strings.TrimSpace(m.inputs[4].Value()))
+	if err != nil || workers < 1 {
+		return ""
+	}
+	cfg := generator.Config{
+		Prefix:        m.inputs[0].Value(),
+		Suffix:        m.inputs[1].Value(),
+		Contains:      m.inputs[2].Value(),
+		Count:         count,
+		CaseSensitive: m.caseSensitive,
+	}
+	eta := computeETA(cfg, 0, m.benchRate*float64(workers))
+	if eta <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("est. ~%s for %d", fmtDuration(eta), count)
+}
+
 func computeETA(cfg generator.Config, found int, ratePerSec float64) time.Duration {
 	if ratePerSec <= 0 {
 		return 0
 	}
-	d := generator.HexDifficulty(cfg.Prefix, cfg.Suffix, cfg.Contains, cfg.CaseSensitive)
+	if cfg.Invert {
+		return 0 // almost every address matches; an ETA is meaningless
+	}
+	d := generator.Difficulty(cfg)
 	if d == nil {
 		return 0
 	}
@@ -644,6 +1125,57 @@ func computeETA(cfg generator.Config, found int, ratePerSec float64) time.Durati
 	return time.Duration(secs * float64(time.Second))
 }
 
+// boxWidth returns the width to render the box at: defaultBoxWidth, or what
+// the terminal leaves room for down to minBoxWidth. The terminal width is
+// unknown until the first tea.WindowSizeMsg.
+func (m Model) boxWidth() int {
+	if m.width <= 0 {
+		return defaultBoxWidth
+	}
+	return max(minBoxWidth, min(defaultBoxWidth, m.width-styleBox.GetHorizontalBorderSize()))
+}
+
+// contentWidth returns the width a view's lines have inside the box.
+func (m Model) contentWidth() int {
+	return m.boxWidth() - styleBox.GetHorizontalPadding()
+}
+
+// statGrid lays out pairs of statRows side by side, or all in one column
+// when any pair does not fit in width.
+func statGrid(pairs [][2]string, width int) string {
+	sep := "  "
+	for _, p := range pairs {
+		if lipgloss.Width(p[0])+2+lipgloss.Width(p[1]) > width {
+			sep = "\n"
+		}
+	}
+	var b strings.Builder
+	for _, p := range pairs {
+		b.WriteString(p[0] + sep + p[1] + "\n")
+	}
+	return b.String()
+}
+
+// wrapValue renders indent, label and value on one line if they fit in
+// width. Otherwise label gets a line of its own and value, which has no
+// spaces to break at, is split into indented lines of what fits.
+func wrapValue(indent, label string, style lipgloss.Style, value string, width int) string {
+	line := indent + label + "  "
+	if lipgloss.Width(line)+len(value) <= width {
+		return line + style.Render(value) + "\n"
+	}
+	var b strings.Builder
+	b.WriteString(indent + label + "\n")
+	pad := indent + "  "
+	step := max(1, width-len(pad))
+	for len(value) > 0 {
+		n := min(step, len(value))
+		b.WriteString(pad + style.Render(value[:n]) + "\n")
+		value = value[n:]
+	}
+	return b.String()
+}
+
 func statRow(label, value string) string {
 	return styleLabel.Width(7).Render(label) + "  " + styleAccent.Render(value)
 }
@@ -662,6 +1194,9 @@ func patternDesc(cfg generator.Config) string {
 	if cfg.Regex != "" {
 		parts = append(parts, fmt.Sprintf("regex %q", cfg.Regex))
 	}
+	if cfg.RegexBody != "" {
+		parts = append(parts, fmt.Sprintf("regex-body %q", cfg.RegexBody))
+	}
 	return strings.Join(parts, " + ")
 }
 
@@ -695,26 +1230,43 @@ func formatBig(n int64) string {
 	}
 }
 
-// formatBigInt formats a large difficulty number (e.g. 16^8) compactly.
-func formatBigInt(n *big.Int) string {
-	f, _ := new(big.Float).SetInt(n).Float64()
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
+
+// Patterns needing fewer than easyDifficulty attempts on average are shown
+// in green in the form, and those needing more than hardDifficulty in red;
+// those in between in amber.
+var (
+	easyDifficulty = big.NewInt(1_000_000)
+	hardDifficulty = big.NewInt(1_000_000_000)
+)
+
+// difficultyStyle colors a difficulty by how hard it is to find.
+func difficultyStyle(d *big.Int) lipgloss.Style {
 	switch {
-	case f < 1_000:
-		return fmt.Sprintf("%.0f", f)
-	case f < 1_000_000:
-		return fmt.Sprintf("%.1fK", f/1e3)
-	case f < 1_000_000_000:
-		return fmt.Sprintf("%.2fM", f/1e6)
-	case f < 1_000_000_000_000:
-		return fmt.Sprintf("%.2fB", f/1e9)
+	case d.Cmp(easyDifficulty) < 0:
+		return styleSuccess
+	case d.Cmp(hardDifficulty) > 0:
+		return styleDanger
 	default:
-		return fmt.Sprintf("%.2fT", f/1e12)
+		return styleWarning
 	}
 }
 
-func truncate(s string, max int) string {
-	if len(s) <= max {
-		return s
+// percentPerAttempt formats the chance 1/d that one attempt matches as a
+// percentage, such as "6.25%" or "0.000153%", falling back to scientific
+// notation for very small chances.
+func percentPerAttempt(d *big.Int) string {
+	f, _ := new(big.Float).SetInt(d).Float64()
+	p := 100 / f
+	if p >= 1e-6 {
+		// Round to three significant digits, then print without an exponent.
+		p, _ = strconv.ParseFloat(fmt.Sprintf("%.3g", p), 64)
+		return strconv.FormatFloat(p, 'f', -1, 64) + "%"
 	}
-	return s[:max]
+	return fmt.Sprintf("%.2e%%", p)
 }
@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"vanity-eth/internal/generator"
+)
+
+// newTestModel returns a Model sized as a width x 40 terminal, with no last
+// search to pre-fill the form from.
+func newTestModel(t *testing.T, width int) Model {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	m, _ := New().Update(tea.WindowSizeMsg{Width: width, Height: 40})
+	return m.(Model)
+}
+
+func testResults() []generator.Result {
+	key := make(generator.PrivateKey, 32)
+	for i := range key {
+		key[i] = byte(i + 1)
+	}
+	return []generator.Result{
+		{
+			Address:    "0xdead00000000000000000000000000000000beef",
+			Checksum:   "0xDEAD00000000000000000000000000000000BEEF",
+			PrivateKey: key,
+		},
+		{Address: "0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef", PrivateKey: key},
+	}
+}
+
+// Every view fits the terminal, from the narrowest box to a wide window
+// where the box stops growing.
+func TestView_FitsWidth(t *testing.T) {
+	cfg := generator.Config{Prefix: "dead", Suffix: "beef", Contains: "c0ffee", Count: 3, Workers: 2}
+	views := map[string]func(m Model) Model{
+		"form": func(m Model) Model { return m },
+		"running": func(m Model) Model {
+			m.state = stateRunning
+			m.cfg = cfg
+			m.stats = &generator.Stats{}
+			m.stats.Total.Store(123456789)
+			m.stats.Workers.Store(2)
+			m.scaler = generator.NewScaler(4)
+			m.startTime = time.Now().Add(-time.Minute)
+			m.kept = generator.Recent{Max: 10}
+			for _, r := range testResults() {
+				m.kept.Add(r)
+			}
+			return m
+		},
+		"results": func(m Model) Model {
+			m.state = stateResults
+			m.cfg = cfg
+			m.results = testResults()
+			m.finalTotal, m.finalElapsed = 123456789, time.Minute
+			return m
+		},
+		"results revealed": func(m Model) Model {
+			m.state = stateResults
+			m.cfg = cfg
+			m.results = testResults()
+			m.finalTotal, m.finalElapsed = 123456789, time.Minute
+			m.maskKeys, m.revealKeys = true, true
+			return m
+		},
+		"save": func(m Model) Model {
+			m.state = stateSave
+			m.results = testResults()
+			m.errMsg = "passphrases do not match, enter it again"
+			return m
+		},
+		"history": func(m Model) Model {
+			m.state = stateHistory
+			m.history = []sessionRecord{{
+				cfg:       cfg,
+				addresses: []string{testResults()[0].Address, testResults()[1].Address},
+				total:     123456789,
+				elapsed:   time.Minute,
+			}}
+			return m
+		},
+	}
+	for _, width := range []int{minBoxWidth + 2, 40, 60, 120} {
+		for name, setup := range views {
+			m := setup(newTestModel(t, width))
+			for i, line := range strings.Split(m.View(), "\n") {
+				if w := lipgloss.Width(line); w > width {
+					t.Errorf("%s at width %d: line %d is %d wide: %q", name, width, i+1, w, line)
+				}
+			}
+		}
+	}
+}
+
+// The box grows with the terminal up to defaultBoxWidth and shrinks down
+// to minBoxWidth, keeping a narrower terminal's lines from wrapping.
+func TestBoxWidth(t *testing.T) {
+	border := styleBox.GetHorizontalBorderSize()
+	for _, tc := range []struct{ width, want int }{
+		{0, defaultBoxWidth},
+		{10, minBoxWidth},
+		{minBoxWidth + border + 5, minBoxWidth + 5},
+		{200, defaultBoxWidth},
+	} {
+		m := Model{width: tc.width}
+		if got := m.boxWidth(); got != tc.want {
+			t.Errorf("boxWidth() at width %d = %d, want %d", tc.width, got, tc.want)
+		}
+	}
+}
+
+func TestWrapValue_SplitsToWidth(t *testing.T) {
+	value := strings.Repeat("ab", 21)
+	got := wrapValue("    ", "key:", lipgloss.NewStyle(), value, 20)
+	var joined string
+	for i, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+		if w := lipgloss.Width(line); w > 20 {
+			t.Errorf("line %d is %d wide: %q", i+1, w, line)
+		}
+		if i > 0 {
+			joined += strings.TrimSpace(line)
+		}
+	}
+	if joined != value {
+		t.Errorf("wrapped value = %q, want %q", joined, value)
+	}
+
+	if got := wrapValue("", "#1", lipgloss.NewStyle(), "0xab", 20); got != "#1  0xab\n" {
+		t.Errorf("short value = %q, want it on one line", got)
+	}
+}
+
+func TestStatGrid_OneColumnWhenNarrow(t *testing.T) {
+	pairs := [][2]string{{"Tried  12345", "Rate  99/s"}}
+	if got := statGrid(pairs, 40); got != "Tried  12345  Rate  99/s\n" {
+		t.Errorf("wide grid = %q", got)
+	}
+	if got := statGrid(pairs, 20); got != "Tried  12345\nRate  99/s\n" {
+		t.Errorf("narrow grid = %q", got)
+	}
+}
@@ -1,19 +1,38 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// The primary and accent colors can be overridden with any lipgloss color
+// (e.g. "#FF8800" or an ANSI number like "208") through these variables.
+const (
+	envPrimaryColor = "VANITY_ETH_PRIMARY_COLOR"
+	envAccentColor  = "VANITY_ETH_ACCENT_COLOR"
+)
+
+// The box is defaultBoxWidth columns wide inside its border, or as narrow
+// as minBoxWidth in terminals that have less room; see Model.boxWidth.
+const (
+	defaultBoxWidth = 58
+	minBoxWidth     = 30
+)
 
 var (
-	colorPrimary = lipgloss.Color("#7C3AED")
-	colorAccent  = lipgloss.Color("#06B6D4")
+	colorPrimary = envColor(envPrimaryColor, "#7C3AED")
+	colorAccent  = envColor(envAccentColor, "#06B6D4")
 	colorSuccess = lipgloss.Color("#10B981")
 	colorDanger  = lipgloss.Color("#EF4444")
+	colorWarning = lipgloss.Color("#F59E0B")
 	colorMuted   = lipgloss.Color("#6B7280")
 
 	styleBox = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
 			BorderForeground(colorPrimary).
-			Padding(1, 3).
-			Width(58)
+			Padding(1, 3)
 
 	styleTitle = lipgloss.NewStyle().
 			Foreground(colorPrimary).
@@ -31,6 +50,10 @@ var (
 			Foreground(colorDanger).
 			Bold(true)
 
+	styleWarning = lipgloss.NewStyle().
+			Foreground(colorWarning).
+			Bold(true)
+
 	styleAccent = lipgloss.NewStyle().
 			Foreground(colorAccent).
 			Bold(true)
@@ -51,3 +74,15 @@ var (
 	styleKey = lipgloss.NewStyle().
 			Foreground(colorDanger)
 )
+
+func envColor(name, fallback string) lipgloss.Color {
+	if v := os.Getenv(name); v != "" {
+		return lipgloss.Color(v)
+	}
+	return lipgloss.Color(fallback)
+}
+
+// DisableColor makes every style render without ANSI color codes.
+func DisableColor() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+}